@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthorHandler struct {
+	services.AuthorServices
+}
+
+// retrieve the authors of a post
+//
+// @Summary 	Get post authors
+// @Description Get the authors of a post, its authors also see the pending invitations
+// @ID 			get-post-authors
+// @Tags 		Authors
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/{postID}/authors [get]
+func (handler *AuthorHandler) GetAuthors(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve authors service
+	authors, errorResponse := handler.AuthorServices.GetAuthors(postID, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Authors retrieved successfully",
+		Data:    authors,
+	})
+}
+
+// invite a user to write a post
+//
+// @Summary 	Invite author
+// @Description Invite a user to write the post as a co-author or contributor, only the owner can invite
+// @ID 			invite-author
+// @Tags 		Authors
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @param 		Invite  body dto.AuthorInvite true "Enter the username and the role"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		409 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/{postID}/authors [post]
+func (handler *AuthorHandler) InviteAuthor(ctx echo.Context) error {
+	var request dto.AuthorInvite
+
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	username := strings.TrimSpace(request.Username)
+	if username == "" {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "username is required",
+		})
+	}
+
+	if err := validation.ValidateAuthorRole(request.Role); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	author := models.PostAuthor{PostID: postID, Role: request.Role, InvitedBy: &viewer.UserID}
+
+	//call the invite author service
+	if errorResponse := handler.AuthorServices.InviteAuthor(&author, username, viewer); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "author invited successfully",
+		Data:    author,
+	})
+}
+
+// remove an author of a post
+//
+// @Summary 	Remove author
+// @Description Remove an author or cancel an invitation, authors can also remove themselves
+// @ID 			remove-author
+// @Tags 		Authors
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @param 		username  path string true "Enter the username of the author"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/{postID}/authors/{username} [delete]
+func (handler *AuthorHandler) RemoveAuthor(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	username := ctx.Param("username")
+	//call the remove author service
+	if errorResponse := handler.AuthorServices.RemoveAuthor(postID, username, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Author removed successfully",
+		Data:    username,
+	})
+}
+
+// retrieve the pending invitations of the user
+//
+// @Summary 	Get author invitations
+// @Description Get the posts the user was invited to write
+// @ID 			get-author-invitations
+// @Tags 		Authors
+// @Security 	JWT
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/invitations [get]
+func (handler *AuthorHandler) GetInvitations(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve invitations service
+	invitations, count, errorResponse := handler.AuthorServices.GetInvitations(getViewer(ctx).UserID, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Invitations retrieved successfully",
+		Data:         invitations,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// accept the invitation to write a post
+//
+// @Summary 	Accept author invitation
+// @Description Accept the invitation to write a post
+// @ID 			accept-author-invitation
+// @Tags 		Authors
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/invitations/{postID}/accept [post]
+func (handler *AuthorHandler) AcceptInvitation(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the accept invitation service
+	if errorResponse := handler.AuthorServices.AcceptInvitation(getViewer(ctx).UserID, postID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Invitation accepted successfully",
+		Data:    postID,
+	})
+}
+
+// decline the invitation to write a post
+//
+// @Summary 	Decline author invitation
+// @Description Decline the invitation to write a post
+// @ID 			decline-author-invitation
+// @Tags 		Authors
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/invitations/{postID} [delete]
+func (handler *AuthorHandler) DeclineInvitation(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the decline invitation service
+	if errorResponse := handler.AuthorServices.DeclineInvitation(getViewer(ctx).UserID, postID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Invitation declined successfully",
+		Data:    postID,
+	})
+}
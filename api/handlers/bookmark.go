@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type BookmarkHandler struct {
+	services.BookmarkServices
+}
+
+// bookmark a post
+//
+// @Summary 	Bookmark post
+// @Description Save a post to read later, optionally into a named collection
+// @ID 			create-bookmark
+// @Tags 		Bookmarks
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @param 		Bookmark  body dto.BookmarkRequest false "Enter the collection name"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/bookmarks/{postID} [post]
+func (handler *BookmarkHandler) CreateBookmark(ctx echo.Context) error {
+	var request dto.BookmarkRequest
+
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateCollectionName(request.Collection); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	bookmark := models.Bookmark{UserID: viewer.UserID, PostID: postID}
+
+	//call the create bookmark service
+	if errorResponse := handler.BookmarkServices.CreateBookmark(&bookmark, request.Collection, viewer); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "post bookmarked successfully",
+		Data:    bookmark,
+	})
+}
+
+// retrieve the bookmarks of the user
+//
+// @Summary 	Get bookmarks
+// @Description Get the bookmarked posts of the user sorted by the date they were saved
+// @ID 			get-bookmarks
+// @Tags 		Bookmarks
+// @Security 	JWT
+// @Produce 	json
+// @Param       collection_id query string false "Enter the collection id"
+// @Param       sort query string false "Sort by newest or oldest"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/bookmarks [get]
+func (handler *BookmarkHandler) GetBookmarks(ctx echo.Context) error {
+	var collectionID uuid.UUID
+	sort := ctx.QueryParam("sort")
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	if sort != "" && sort != "newest" && sort != "oldest" {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "sort must be either newest or oldest",
+		})
+	}
+
+	if id := ctx.QueryParam("collection_id"); id != "" {
+		convCollectionID, err := uuid.Parse(id)
+		if err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		}
+		collectionID = convCollectionID
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	//call the retrieve bookmarks service
+	bookmarks, count, errorResponse := handler.BookmarkServices.GetBookmarks(viewer.UserID, collectionID, sort, limit, offset, viewer)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Bookmarks retrieved successfully",
+		Data:         bookmarks,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// remove the bookmark of a post
+//
+// @Summary 	Delete bookmark
+// @Description Remove a post from the bookmarks of the user
+// @ID 			delete-bookmark
+// @Tags 		Bookmarks
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/bookmarks/{postID} [delete]
+func (handler *BookmarkHandler) DeleteBookmark(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the delete bookmark service
+	if errorResponse := handler.BookmarkServices.DeleteBookmark(getViewer(ctx).UserID, postID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Bookmark deleted successfully",
+		Data:    postID,
+	})
+}
+
+// retrieve the bookmark collections of the user
+//
+// @Summary 	Get bookmark collections
+// @Description Get the bookmark collections of the user along with the number of bookmarks in them
+// @ID 			get-bookmark-collections
+// @Tags 		Bookmarks
+// @Security 	JWT
+// @Produce 	json
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/bookmarks/collections [get]
+func (handler *BookmarkHandler) GetCollections(ctx echo.Context) error {
+	//call the retrieve collections service
+	collections, errorResponse := handler.BookmarkServices.GetCollections(getViewer(ctx).UserID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Collections retrieved successfully",
+		Data:    collections,
+	})
+}
+
+// delete a bookmark collection
+//
+// @Summary 	Delete bookmark collection
+// @Description Delete a bookmark collection, its bookmarks are kept without a collection
+// @ID 			delete-bookmark-collection
+// @Tags 		Bookmarks
+// @Security 	JWT
+// @Produce 	json
+// @param 		collectionID  path string true "Enter the collection id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/bookmarks/collections/{collectionID} [delete]
+func (handler *BookmarkHandler) DeleteCollection(ctx echo.Context) error {
+	collectionID, err := uuid.Parse(ctx.Param("collection_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the delete collection service
+	if errorResponse := handler.BookmarkServices.DeleteCollection(getViewer(ctx).UserID, collectionID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Collection deleted successfully",
+		Data:    collectionID,
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/marees7/rishi-aug-2024/api/services"
 	"github.com/marees7/rishi-aug-2024/api/validation"
@@ -117,6 +118,42 @@ func (handler *CategoryHandler) GetCategories(ctx echo.Context) error {
 	})
 }
 
+// retrieve a specific category using its slug
+//
+// @Summary 	Get category by slug
+// @Description Get a category using its slug, old slugs are redirected to the current one
+// @ID 			get-category-by-slug
+// @Tags 		Category
+// @Security 	JWT
+// @Produce 	json
+// @param 		slug  path string true "Enter the category slug"
+// @Success 	200 {object} dto.ResponseJson
+// @Success 	301 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/categories/by-slug/{slug} [get]
+func (handler *CategoryHandler) GetCategoryBySlug(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+
+	category, moved, errorResponse := handler.Category.GetCategoryBySlug(slug)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	//redirect the old slug to the current one
+	if moved {
+		return ctx.Redirect(http.StatusMovedPermanently, "/v1/categories/by-slug/"+url.PathEscape(category.Slug))
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "retrieved category successfully",
+		Data:    category,
+	})
+}
+
 // update an existing category
 //
 // @Summary 	Update categories
@@ -155,6 +192,13 @@ func (handler *CategoryHandler) UpdateCategory(ctx echo.Context) error {
 		})
 	}
 
+	if err := validation.ValidateCommentLockDays(category.CommentLockDays); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
 	roleCtx := ctx.Get("role").(string)
 	if validation.ValidateRole(roleCtx) {
 		//call the update category service
@@ -5,6 +5,7 @@ import (
 
 	"github.com/marees7/rishi-aug-2024/api/services"
 	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
 	"github.com/marees7/rishi-aug-2024/common/helpers"
 	"github.com/marees7/rishi-aug-2024/pkg/loggers"
@@ -21,7 +22,7 @@ type CommentHandler struct {
 // Create a new comment for a post
 //
 // @Summary 	Create comment
-// @Description Create a new comment
+// @Description Create a new comment, or an answer to another comment of the post when parent_id is given
 // @ID 			Create-comment
 // @Tags 		Comments
 // @Security 	JWT
@@ -32,6 +33,7 @@ type CommentHandler struct {
 // @Success 	201 {object} dto.ResponseJson
 // @Failure		400 {object} dto.ResponseJson
 // @Failure		404 {object} dto.ResponseJson
+// @Failure		423 {object} dto.ResponseJson
 // @Failure		500 {object} dto.ResponseJson
 // @Router 		/v1/users/comment/{postID} [post]
 func (handler *CommentHandler) CreateComment(ctx echo.Context) error {
@@ -72,23 +74,105 @@ func (handler *CommentHandler) CreateComment(ctx echo.Context) error {
 
 	comment.UserID = userID
 	//call the create comment service
-	if err := handler.CommentServices.CreateComment(&comment); err != nil {
+	if err := handler.CommentServices.CreateComment(ctx.Request().Context(), &comment, getViewer(ctx)); err != nil {
 		loggers.Warn.Println(err.Error)
 		return ctx.JSON(err.Status, dto.ResponseJson{
 			Error: err.Error,
 		})
 	}
 
+	if comment.Status != constants.CommentStatusApproved {
+		return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+			Message: "comment is waiting for moderation",
+			Data:    comment,
+		})
+	}
+
 	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
 		Message: "comment added successfully",
 		Data:    comment,
 	})
 }
 
+// answer an existing comment
+//
+// @Summary 	Create reply
+// @Description Answer a comment, answers to comments nested as deep as allowed answer their parent instead
+// @ID 			Create-reply
+// @Tags 		Comments
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		commentID  path string true "Enter the comment id"
+// @param 		Create_reply  body models.Comment true "Enter the reply you want add in the comment"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		423 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/reply/{commentID} [post]
+func (handler *CommentHandler) CreateReply(ctx echo.Context) error {
+	var comment models.Comment
+
+	if err := ctx.Bind(&comment); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateComment(&comment); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	userID, err := uuid.Parse(ctx.Get("user_id").(string))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	parentID, err := uuid.Parse(ctx.Param("comment_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//the post is the one of the comment answered
+	comment.PostID = uuid.Nil
+	comment.ParentID = &parentID
+	comment.UserID = userID
+	//call the create comment service
+	if err := handler.CommentServices.CreateComment(ctx.Request().Context(), &comment, getViewer(ctx)); err != nil {
+		loggers.Warn.Println(err.Error)
+		return ctx.JSON(err.Status, dto.ResponseJson{
+			Error: err.Error,
+		})
+	}
+
+	if comment.Status != constants.CommentStatusApproved {
+		return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+			Message: "comment is waiting for moderation",
+			Data:    comment,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "reply added to the comment successfully",
+		Data:    comment,
+	})
+}
+
 // retrieve every comments of the post
 //
 // @Summary 	Get comment
-// @Description Get comments in a post
+// @Description Get the threads of a post with their answers nested under them, or the comments found by the search
 // @ID 			get-comment
 // @Tags 		Comments
 // @Security 	JWT
@@ -98,6 +182,7 @@ func (handler *CommentHandler) CreateComment(ctx echo.Context) error {
 // @param 		limit  query string false "Enter the limit"
 // @param 		offset  query string false "Enter the offset"
 // @param 		search  query string false "Enter a comment phrase you want to search"
+// @param 		sort  query string false "Enter the order of the threads, newest, oldest or top"
 // @Success 	200 {object} dto.ResponseJson
 // @Failure		400 {object} dto.ResponseJson
 // @Failure		404 {object} dto.ResponseJson
@@ -108,6 +193,7 @@ func (handler *CommentHandler) GetComments(ctx echo.Context) error {
 	offsetStr := ctx.QueryParam("offset")
 	limitStr := ctx.QueryParam("limit")
 	search := ctx.QueryParam("search")
+	sort := ctx.QueryParam("sort")
 
 	id := ctx.Param("post_id")
 	if id == "" {
@@ -132,14 +218,22 @@ func (handler *CommentHandler) GetComments(ctx echo.Context) error {
 		})
 	}
 
+	if err := validation.ValidateCommentSort(sort); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
 	keywords := map[string]interface{}{
 		"search": search,
+		"sort":   sort,
 		"limit":  limit,
 		"offset": offset,
 	}
 
 	//call the retrieve comment service
-	comment, errorResponse, count := handler.CommentServices.GetComments(postID, keywords)
+	comment, errorResponse, count := handler.CommentServices.GetComments(postID, keywords, getViewer(ctx))
 	if errorResponse != nil {
 		loggers.Warn.Println(errorResponse.Error)
 		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
@@ -155,6 +249,55 @@ func (handler *CommentHandler) GetComments(ctx echo.Context) error {
 		TotalRecords: count})
 }
 
+// retrieve a comment along with every answer nested under it
+//
+// @Summary 	Get comment thread
+// @Description Get a comment along with its answers, such as the answers nested deeper than shown in the threads
+// @ID 			get-comment-thread
+// @Tags 		Comments
+// @Security 	JWT
+// @Produce 	json
+// @param 		commentID  path string true "Enter the comment id"
+// @param 		sort  query string false "Enter the order of the answers, newest, oldest or top"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/comment/{commentID}/replies [get]
+func (handler *CommentHandler) GetThread(ctx echo.Context) error {
+	sort := ctx.QueryParam("sort")
+
+	commentID, err := uuid.Parse(ctx.Param("comment_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateCommentSort(sort); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve thread service
+	comment, errorResponse := handler.CommentServices.GetThread(commentID, sort, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Comment thread retrieved successfully",
+		Data:    comment,
+	})
+}
+
 // update an existing comment
 //
 // @Summary 	Update comment
@@ -171,6 +314,7 @@ func (handler *CommentHandler) GetComments(ctx echo.Context) error {
 // @Failure		403 {object} dto.ResponseJson
 // @Failure		304 {object} dto.ResponseJson
 // @Failure		404 {object} dto.ResponseJson
+// @Failure		423 {object} dto.ResponseJson
 // @Failure		500 {object} dto.ResponseJson
 // @Router 		/v1/users/comment/{commentID} [put]
 func (handler *CommentHandler) UpdateComment(ctx echo.Context) error {
@@ -203,17 +347,25 @@ func (handler *CommentHandler) UpdateComment(ctx echo.Context) error {
 
 	comment.UserID = userID
 	//call the update comment service
-	if err := handler.CommentServices.UpdateComment(&comment, commentID); err != nil {
+	if err := handler.CommentServices.UpdateComment(ctx.Request().Context(), &comment, commentID, getViewer(ctx)); err != nil {
 		loggers.Warn.Println(err.Error)
 		return ctx.JSON(err.Status, dto.ResponseJson{
 			Error: err.Error,
 		})
 	}
 
+	message := "comment edited successfully"
+	if comment.Status != constants.CommentStatusApproved {
+		message = "comment is waiting for moderation"
+	}
+
 	return ctx.JSON(http.StatusOK, dto.ResponseJson{
-		Message: "comment edited successfully",
+		Message: message,
 		Data: map[string]interface{}{
 			"comment_id": commentID,
+			"status":     comment.Status,
+			"edited_at":  comment.EditedAt,
+			"edit_count": comment.EditCount,
 		},
 	})
 }
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/feeds"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FeedHandler struct {
+	services.FeedServices
+}
+
+// retrieve the latest posts as an RSS feed
+//
+// @Summary 	RSS feed
+// @Description Get the latest published posts as an RSS 2.0 feed, optionally limited to a category, tag or author
+// @ID 			get-rss-feed
+// @Tags 		Feeds
+// @Produce 	xml
+// @Success 	200 {string} string
+// @Success 	304 {string} string
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/feeds/posts.rss [get]
+func (handler *FeedHandler) GetRSS(ctx echo.Context) error {
+	return handler.serveFeed(ctx, feeds.FormatRSS)
+}
+
+// retrieve the latest posts as an Atom feed
+//
+// @Summary 	Atom feed
+// @Description Get the latest published posts as an Atom feed, optionally limited to a category, tag or author
+// @ID 			get-atom-feed
+// @Tags 		Feeds
+// @Produce 	xml
+// @Success 	200 {string} string
+// @Success 	304 {string} string
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/feeds/posts.atom [get]
+func (handler *FeedHandler) GetAtom(ctx echo.Context) error {
+	return handler.serveFeed(ctx, feeds.FormatAtom)
+}
+
+// retrieve the latest posts as a JSON feed
+//
+// @Summary 	JSON feed
+// @Description Get the latest published posts as a JSON Feed 1.1, optionally limited to a category, tag or author
+// @ID 			get-json-feed
+// @Tags 		Feeds
+// @Produce 	json
+// @Success 	200 {string} string
+// @Success 	304 {string} string
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/feeds/posts.json [get]
+func (handler *FeedHandler) GetJSON(ctx echo.Context) error {
+	return handler.serveFeed(ctx, feeds.FormatJSON)
+}
+
+// render the feed and answer with 304 when the reader already has the latest version
+func (handler *FeedHandler) serveFeed(ctx echo.Context, format string) error {
+	filter := dto.PublicPostFilter{
+		Category: ctx.Param("category"),
+		Tag:      ctx.Param("tag"),
+		Author:   ctx.Param("username"),
+	}
+
+	//call the feed service
+	feed, errorResponse := handler.FeedServices.GetFeed(filter, helpers.SiteURL()+ctx.Request().URL.Path)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	body, err := feeds.Render(feed, format)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusInternalServerError, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	lastModified := feed.Updated.UTC().Truncate(time.Second)
+
+	//an empty feed was never modified, it is only compared by its etag
+	if len(feed.Items) > 0 {
+		ctx.Response().Header().Set(echo.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+	ctx.Response().Header().Set("ETag", etag)
+
+	//the etag takes precedence over the modification date
+	if match := ctx.Request().Header.Get("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			return ctx.NoContent(http.StatusNotModified)
+		}
+	} else if since, err := http.ParseTime(ctx.Request().Header.Get(echo.HeaderIfModifiedSince)); err == nil && len(feed.Items) > 0 && !lastModified.After(since) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.Blob(http.StatusOK, feeds.ContentTypes[format], body)
+}
+
+// check if the If-None-Match header contains the etag, weak etags are compared by their value
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/importer"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ImportHandler struct {
+	services.ImportServices
+}
+
+// import a wordpress export or markdown files
+//
+// @Summary 	Import posts
+// @Description Import a wordpress export or markdown files with front matter in the background, importing the same file again resumes it
+// @ID 			create-import
+// @Tags 		Imports
+// @Security 	JWT
+// @Accept		multipart/form-data
+// @Produce 	json
+// @param 		file formData file true "Select the wordpress export, a markdown file or a zip archive of markdown files"
+// @param 		source formData string false "Enter the source, wxr or markdown"
+// @param 		dry_run formData bool false "Report what would be imported without importing it"
+// @Success 	200 {object} dto.ResponseJson
+// @Success 	202 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		409 {object} dto.ResponseJson
+// @Failure		413 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/imports [post]
+func (handler *ImportHandler) CreateImport(ctx echo.Context) error {
+	viewer := getViewer(ctx)
+	if !validation.ValidateRole(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//wordpress exports are xml files, anything else is markdown
+	source := ctx.FormValue("source")
+	if source == "" {
+		source = constants.ImportSourceMarkdown
+		if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".xml") {
+			source = constants.ImportSourceWXR
+		}
+	}
+	if err := validation.ValidateImportSource(source); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	dryRun := false
+	if dryRunStr := ctx.FormValue("dry_run"); dryRunStr != "" {
+		if dryRun, err = strconv.ParseBool(dryRunStr); err != nil {
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: "dry_run must be either true or false",
+			})
+		}
+	}
+
+	maxSize := helpers.EnvInt64("IMPORT_MAX_SIZE", constants.DefaultImportMaxSize)
+	if fileHeader.Size > maxSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.ResponseJson{
+			Error: fmt.Sprintf("file cannot be larger than %d bytes", maxSize),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+	defer file.Close()
+
+	//read one byte more than allowed to catch files larger than the reported size
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	} else if int64(len(content)) > maxSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.ResponseJson{
+			Error: fmt.Sprintf("file cannot be larger than %d bytes", maxSize),
+		})
+	}
+
+	document, err := importer.Parse(source, fileHeader.Filename, content)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//the checksum finds the earlier job of the same file
+	sum := sha256.Sum256(content)
+	job := models.ImportJob{
+		Source:   source,
+		Site:     document.Site,
+		Name:     fileHeader.Filename,
+		Checksum: hex.EncodeToString(sum[:]),
+		DryRun:   dryRun,
+		UserID:   viewer.UserID,
+	}
+
+	//call the start import service
+	imported, errorResponse := handler.ImportServices.StartImport(&job, document)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	if imported {
+		return ctx.JSON(http.StatusOK, dto.ResponseJson{
+			Message: "File was already imported",
+			Data:    job,
+		})
+	}
+
+	return ctx.JSON(http.StatusAccepted, dto.ResponseJson{
+		Message: "Import started successfully",
+		Data:    job,
+	})
+}
+
+// retrieve the import jobs
+//
+// @Summary 	Get imports
+// @Description Get the import jobs along with their counts, the latest first
+// @ID 			get-imports
+// @Tags 		Imports
+// @Security 	JWT
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/imports [get]
+func (handler *ImportHandler) GetImports(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	if !validation.ValidateRole(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve imports service
+	jobs, count, errorResponse := handler.ImportServices.GetImports(limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Imports retrieved successfully",
+		Data:         jobs,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve an import job
+//
+// @Summary 	Get import
+// @Description Get the status and counts of an import job
+// @ID 			get-import
+// @Tags 		Imports
+// @Security 	JWT
+// @Produce 	json
+// @param 		jobID  path string true "Enter the import job id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/imports/{jobID} [get]
+func (handler *ImportHandler) GetImport(ctx echo.Context) error {
+	jobID, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateRole(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	//call the retrieve import service
+	job, errorResponse := handler.ImportServices.GetImport(jobID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Import retrieved successfully",
+		Data:    job,
+	})
+}
+
+// retrieve the outcome of the items of an import job
+//
+// @Summary 	Get import items
+// @Description Get the items of an import job with what happened to them, such as the reason they failed
+// @ID 			get-import-items
+// @Tags 		Imports
+// @Security 	JWT
+// @Produce 	json
+// @param 		jobID  path string true "Enter the import job id"
+// @Param       action query string false "Enter the action, created, skipped or failed"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/imports/{jobID}/items [get]
+func (handler *ImportHandler) GetImportItems(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	action := ctx.QueryParam("action")
+
+	jobID, err := uuid.Parse(ctx.Param("job_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateRole(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	if err := validation.ValidateImportAction(action); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve import items service
+	items, count, errorResponse := handler.ImportServices.GetImportItems(jobID, action, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Import items retrieved successfully",
+		Data:         items,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
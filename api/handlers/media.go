@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type MediaHandler struct {
+	services.MediaServices
+}
+
+// upload a new file
+//
+// @Summary 	Upload media
+// @Description Upload an image or attachment which can be referenced by posts
+// @ID 			upload-media
+// @Tags 		Media
+// @Security 	JWT
+// @Accept		multipart/form-data
+// @Produce 	json
+// @param 		file formData file true "Select the file to upload"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		413 {object} dto.ResponseJson
+// @Failure		415 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/media [post]
+func (handler *MediaHandler) UploadMedia(ctx echo.Context) error {
+	userIDCtx := ctx.Get("user_id").(string)
+	userID, err := uuid.Parse(userIDCtx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	maxSize := helpers.EnvInt64("MEDIA_MAX_SIZE", constants.DefaultMediaMaxSize)
+	if fileHeader.Size > maxSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.ResponseJson{
+			Error: fmt.Sprintf("file cannot be larger than %d bytes", maxSize),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+	defer file.Close()
+
+	//read one byte more than allowed to catch files larger than the reported size
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	} else if int64(len(content)) > maxSize {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.ResponseJson{
+			Error: fmt.Sprintf("file cannot be larger than %d bytes", maxSize),
+		})
+	} else if len(content) == 0 {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "file cannot be empty",
+		})
+	}
+
+	//detect the type from the content instead of trusting the client
+	contentType := http.DetectContentType(content)
+	extension, err := validation.ValidateMediaType(contentType)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusUnsupportedMediaType, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the upload media service
+	media, errorResponse := handler.MediaServices.UploadMedia(userID, fileHeader.Filename, contentType, extension, content)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "media uploaded successfully",
+		Data:    media,
+	})
+}
+
+// retrieve the files uploaded by the user
+//
+// @Summary 	Get media
+// @Description Get all the files uploaded by the user
+// @ID 			get-media
+// @Tags 		Media
+// @Security 	JWT
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/media [get]
+func (handler *MediaHandler) GetMedia(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	userIDCtx := ctx.Get("user_id").(string)
+	userID, err := uuid.Parse(userIDCtx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve media service
+	media, count, errorResponse := handler.MediaServices.GetMedia(userID, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "retrieved media successfully",
+		Data:         media,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// delete an uploaded file
+//
+// @Summary 	Delete media
+// @Description Delete an uploaded file and detach it from every post
+// @ID 			delete-media
+// @Tags 		Media
+// @Security 	JWT
+// @Produce 	json
+// @param 		mediaID  path string true "Enter the media id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/media/{mediaID} [delete]
+func (handler *MediaHandler) DeleteMedia(ctx echo.Context) error {
+	id := ctx.Param("media_id")
+	mediaID, err := uuid.Parse(id)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	userIDCtx := ctx.Get("user_id").(string)
+	userID, err := uuid.Parse(userIDCtx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	roleCtx := ctx.Get("role").(string)
+	//call the delete media service
+	errorResponse := handler.MediaServices.DeleteMedia(mediaID, userID, roleCtx)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Media deleted successfully",
+		Data:    mediaID,
+	})
+}
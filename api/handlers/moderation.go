@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ModerationHandler struct {
+	services.ModerationServices
+}
+
+// retrieve the comments waiting for moderation
+//
+// @Summary 	Get moderation queue
+// @Description Get the comments waiting for moderation the oldest first, or the comments with another status
+// @ID 			get-moderation-queue
+// @Tags 		Moderation
+// @Security 	JWT
+// @Produce 	json
+// @Param       status query string false "Enter the status, pending, approved, rejected or spam"
+// @Param       category query string false "Enter the category slug"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/comments [get]
+func (handler *ModerationHandler) GetQueue(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	category := ctx.QueryParam("category")
+	status := ctx.QueryParam("status")
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateCommentStatus(status); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+	if status == "" {
+		status = constants.CommentStatusPending
+	}
+
+	//call the retrieve moderation queue service
+	comments, count, errorResponse := handler.ModerationServices.GetQueue(status, category, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Comments retrieved successfully",
+		Data:         comments,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// approve or reject comments in bulk
+//
+// @Summary 	Moderate comments
+// @Description Approve the pending or spam comments, or reject them as rejected or spam, up to 100 at once
+// @ID 			moderate-comments
+// @Tags 		Moderation
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		Moderation  body dto.CommentModeration true "Enter the comment ids and their new status"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/comments [put]
+func (handler *ModerationHandler) ModerateComments(ctx echo.Context) error {
+	var moderation dto.CommentModeration
+
+	if err := ctx.Bind(&moderation); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	if !validation.ValidateModerator(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	if err := validation.ValidateCommentModeration(&moderation); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the moderate comments service
+	moderated, errorResponse := handler.ModerationServices.ModerateComments(&moderation, viewer.UserID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Comments moderated successfully",
+		Data:         moderation,
+		TotalRecords: moderated,
+	})
+}
+
+// retrieve the moderation rules
+//
+// @Summary 	Get moderation rules
+// @Description Get the site-wide moderation rules followed by the rules of the categories
+// @ID 			get-moderation-rules
+// @Tags 		Moderation
+// @Security 	JWT
+// @Produce 	json
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/rules [get]
+func (handler *ModerationHandler) GetRules(ctx echo.Context) error {
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//call the retrieve moderation rules service
+	rules, errorResponse := handler.ModerationServices.GetRules()
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Rules retrieved successfully",
+		Data:    rules,
+	})
+}
+
+// create or replace the moderation rules
+//
+// @Summary 	Save moderation rules
+// @Description Create or replace the moderation rules of a category, or the site-wide rules without a category
+// @ID 			save-moderation-rules
+// @Tags 		Moderation
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		Rule  body models.ModerationRule true "Enter the category id and the rules"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/rules [put]
+func (handler *ModerationHandler) SaveRule(ctx echo.Context) error {
+	var rule models.ModerationRule
+
+	if err := ctx.Bind(&rule); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	if !validation.ValidateModerator(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+	rule.UpdatedBy = viewer.UserID
+
+	//call the save moderation rules service
+	if errorResponse := handler.ModerationServices.SaveRule(&rule); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Rules saved successfully",
+		Data:    rule,
+	})
+}
+
+// delete the moderation rules
+//
+// @Summary 	Delete moderation rules
+// @Description Delete moderation rules, the categories without rules follow the site-wide rules
+// @ID 			delete-moderation-rules
+// @Tags 		Moderation
+// @Security 	JWT
+// @Produce 	json
+// @param 		ruleID  path string true "Enter the rule id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/rules/{ruleID} [delete]
+func (handler *ModerationHandler) DeleteRule(ctx echo.Context) error {
+	ruleID, err := uuid.Parse(ctx.Param("rule_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//call the delete moderation rules service
+	if errorResponse := handler.ModerationServices.DeleteRule(ruleID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Rules deleted successfully",
+		Data:    ruleID,
+	})
+}
+
+// retrieve the previous versions of a comment
+//
+// @Summary 	Get comment revisions
+// @Description Get the previous versions of an edited comment the oldest first, the first version is the comment as it was written
+// @ID 			get-comment-revisions
+// @Tags 		Moderation
+// @Security 	JWT
+// @Produce 	json
+// @param 		commentID  path string true "Enter the comment id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/comments/{commentID}/revisions [get]
+func (handler *ModerationHandler) GetRevisions(ctx echo.Context) error {
+	commentID, err := uuid.Parse(ctx.Param("comment_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//call the retrieve comment revisions service
+	revisions, errorResponse := handler.ModerationServices.GetRevisions(commentID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Revisions retrieved successfully",
+		Data:    revisions,
+	})
+}
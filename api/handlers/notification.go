@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	services.NotificationServices
+}
+
+// retrieve the notifications of the user
+//
+// @Summary 	Get notifications
+// @Description Get the notifications of the user the latest first, such as the comments on their posts waiting for moderation
+// @ID 			get-notifications
+// @Tags 		Notifications
+// @Security 	JWT
+// @Produce 	json
+// @Param       unread query bool false "Only get the notifications which were not read"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/notifications [get]
+func (handler *NotificationHandler) GetNotifications(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	unread := false
+	if unreadStr := ctx.QueryParam("unread"); unreadStr != "" {
+		if unread, err = strconv.ParseBool(unreadStr); err != nil {
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: "unread must be either true or false",
+			})
+		}
+	}
+
+	//call the retrieve notifications service
+	notifications, count, errorResponse := handler.NotificationServices.GetNotifications(getViewer(ctx).UserID, unread, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Notifications retrieved successfully",
+		Data:         notifications,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// mark a notification as read
+//
+// @Summary 	Read notification
+// @Description Mark a notification of the user as read
+// @ID 			read-notification
+// @Tags 		Notifications
+// @Security 	JWT
+// @Produce 	json
+// @param 		notificationID  path string true "Enter the notification id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/notifications/{notificationID}/read [put]
+func (handler *NotificationHandler) ReadNotification(ctx echo.Context) error {
+	notificationID, err := uuid.Parse(ctx.Param("notification_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the read notifications service
+	if _, errorResponse := handler.NotificationServices.ReadNotifications(getViewer(ctx).UserID, notificationID); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Notification marked as read",
+		Data:    notificationID,
+	})
+}
+
+// mark every notification as read
+//
+// @Summary 	Read notifications
+// @Description Mark every notification of the user as read
+// @ID 			read-notifications
+// @Tags 		Notifications
+// @Security 	JWT
+// @Produce 	json
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/notifications/read [put]
+func (handler *NotificationHandler) ReadNotifications(ctx echo.Context) error {
+	//call the read notifications service
+	count, errorResponse := handler.NotificationServices.ReadNotifications(getViewer(ctx).UserID, uuid.Nil)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Notifications marked as read",
+		TotalRecords: count,
+	})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PinHandler struct {
+	services.PinServices
+}
+
+// pin a post on top of the listing
+//
+// @Summary 	Pin post
+// @Description Pin a published post site-wide or within its category, pinning it again changes its position and expiry
+// @ID 			pin-post
+// @Tags 		Pins
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @param 		Pin  body dto.PinRequest true "Enter the scope, position and expiry"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/pins/{postID} [put]
+func (handler *PinHandler) PinPost(ctx echo.Context) error {
+	var request dto.PinRequest
+
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	if !validation.ValidateModerator(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	if err := validation.ValidatePin(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	pin := models.PostPin{PostID: postID, Scope: request.Scope, ExpiresAt: request.ExpiresAt, PinnedBy: viewer.UserID}
+
+	//call the pin post service
+	if errorResponse := handler.PinServices.PinPost(&pin, request.Position); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post pinned successfully",
+		Data:    pin,
+	})
+}
+
+// remove the pin of a post
+//
+// @Summary 	Unpin post
+// @Description Remove the site-wide or category pin of a post
+// @ID 			unpin-post
+// @Tags 		Pins
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Param       scope query string false "Enter the scope, site or category"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/pins/{postID} [delete]
+func (handler *PinHandler) UnpinPost(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	request := dto.PinRequest{Scope: ctx.QueryParam("scope")}
+	if err := validation.ValidatePin(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the unpin post service
+	if errorResponse := handler.PinServices.UnpinPost(postID, request.Scope); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post unpinned successfully",
+		Data:    postID,
+	})
+}
+
+// retrieve the pinned posts
+//
+// @Summary 	Get pinned posts
+// @Description Get the pins which did not expire in their order, the category pins can be limited to a category
+// @ID 			get-pins
+// @Tags 		Pins
+// @Security 	JWT
+// @Produce 	json
+// @Param       scope query string false "Enter the scope, site or category"
+// @Param       category query string false "Enter the category slug"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/pins [get]
+func (handler *PinHandler) GetPins(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	category := ctx.QueryParam("category")
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//filtering by category only makes sense for the category pins
+	request := dto.PinRequest{Scope: ctx.QueryParam("scope")}
+	if request.Scope == "" && category != "" {
+		request.Scope = constants.PinScopeCategory
+	}
+	if err := validation.ValidatePin(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve pins service
+	pins, count, errorResponse := handler.PinServices.GetPins(request.Scope, category, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Pins retrieved successfully",
+		Data:         pins,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/marees7/rishi-aug-2024/api/services"
 	"github.com/marees7/rishi-aug-2024/api/validation"
@@ -61,13 +62,20 @@ func (handler *PostHandler) CreatePost(ctx echo.Context) error {
 
 	post.UserID = userID
 	//call the create post service
-	if err := handler.PostServices.CreatePost(&post); err != nil {
+	if err := handler.PostServices.CreatePost(ctx.Request().Context(), &post, getViewer(ctx)); err != nil {
 		loggers.Warn.Println(err.Error)
 		return ctx.JSON(err.Status, dto.ResponseJson{
 			Error: err.Error,
 		})
 	}
 
+	if post.HiddenAt != nil {
+		return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+			Message: "post is waiting for moderation",
+			Data:    post,
+		})
+	}
+
 	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
 		Message: "post created successfully",
 		Data:    post,
@@ -88,8 +96,11 @@ func (handler *PostHandler) CreatePost(ctx echo.Context) error {
 // @param 		startDate  query string false "Enter the start date"
 // @param 		endDate  query string false "Enter the end date"
 // @param 		title  query string false "Enter the title to search"
+// @param 		author  query string false "Enter the username of any of the authors"
+// @param 		all  query bool false "List every post including the drafts and hidden posts, admins only"
 // @Success 	200 {object} dto.ResponseJson
 // @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
 // @Failure		404 {object} dto.ResponseJson
 // @Failure		500 {object} dto.ResponseJson
 // @Router 		/v1/users/post [get]
@@ -100,6 +111,7 @@ func (handler *PostHandler) GetPosts(ctx echo.Context) error {
 	offsetStr := ctx.QueryParam("offset")
 	limitStr := ctx.QueryParam("limit")
 	title := ctx.QueryParam("title")
+	author := ctx.QueryParam("author")
 
 	id := ctx.QueryParam("post_id")
 	if id == "" {
@@ -124,16 +136,27 @@ func (handler *PostHandler) GetPosts(ctx echo.Context) error {
 		})
 	}
 
+	//only the admin can list the drafts and hidden posts of other users
+	all := ctx.QueryParam("all") == "true"
+	if all && !validation.ValidateRole(ctx.Get("role").(string)) {
+		loggers.Warn.Println("only admins can list every post")
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed to list every post",
+		})
+	}
+
 	keywords := map[string]interface{}{
 		"fromDate": fromDate,
 		"toDate":   toDate,
 		"title":    title,
+		"author":   author,
+		"all":      all,
 		"limit":    limit,
 		"offset":   offset,
 	}
 
 	//call the retrieve post service
-	posts, count, err := handler.PostServices.GetPosts(postID, keywords)
+	posts, count, err := handler.PostServices.GetPosts(postID, keywords, getViewer(ctx))
 	if err != nil {
 		loggers.Warn.Println(err)
 		return ctx.JSON(http.StatusInternalServerError, dto.ResponseJson{
@@ -174,7 +197,8 @@ func (handler *PostHandler) GetPost(ctx echo.Context) error {
 		})
 	}
 
-	post, errorResponse := handler.PostServices.GetPost(postID)
+	viewer := getViewer(ctx)
+	post, errorResponse := handler.PostServices.GetPost(postID, viewer, getVisit(ctx, viewer))
 	if errorResponse != nil {
 		loggers.Warn.Println(errorResponse.Error)
 		return ctx.JSON(errorResponse.Status, dto.ErrorResponse{
@@ -188,6 +212,44 @@ func (handler *PostHandler) GetPost(ctx echo.Context) error {
 	})
 }
 
+// retrieve a specific post using its slug
+//
+// @Summary 	get post by slug
+// @Description get single post using its slug, old slugs are redirected to the current one
+// @ID 			get-post-by-slug
+// @Tags 		Posts
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		slug  path string true "Enter the post slug"
+// @Success 	200 {object} dto.ResponseJson
+// @Success 	301 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/posts/by-slug/{slug} [get]
+func (handler *PostHandler) GetPostBySlug(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+
+	viewer := getViewer(ctx)
+	post, moved, errorResponse := handler.PostServices.GetPostBySlug(slug, viewer, getVisit(ctx, viewer))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	//redirect the old slug to the current one
+	if moved {
+		return ctx.Redirect(http.StatusMovedPermanently, "/v1/posts/by-slug/"+url.PathEscape(post.Slug))
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post retrieved successfully",
+		Data:    post,
+	})
+}
+
 // update a existing post
 //
 // @Summary 	Update post
@@ -204,6 +266,7 @@ func (handler *PostHandler) GetPost(ctx echo.Context) error {
 // @Failure		403 {object} dto.ResponseJson
 // @Failure		304 {object} dto.ResponseJson
 // @Failure		404 {object} dto.ResponseJson
+// @Failure		422 {object} dto.ResponseJson
 // @Failure		500 {object} dto.ResponseJson
 // @Router 		/v1/users/post/{postID} [put]
 func (handler *PostHandler) UpdatePost(ctx echo.Context) error {
@@ -225,6 +288,41 @@ func (handler *PostHandler) UpdatePost(ctx echo.Context) error {
 		})
 	}
 
+	if err := validation.ValidateContentFormat(post.ContentFormat); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidatePostStatus(post.Status); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidatePostVisibility(&post); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidatePostSEO(&post); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidatePostTags(post.TagNames); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
 	userIDCtx := ctx.Get("user_id").(string)
 	userID, err := uuid.Parse(userIDCtx)
 	if err != nil {
@@ -236,13 +334,20 @@ func (handler *PostHandler) UpdatePost(ctx echo.Context) error {
 
 	post.UserID = userID
 	//call the update post service
-	if err := handler.PostServices.UpdatePost(&post, postID); err != nil {
+	if err := handler.PostServices.UpdatePost(ctx.Request().Context(), &post, postID, getViewer(ctx)); err != nil {
 		loggers.Warn.Println(err.Error)
 		return ctx.JSON(err.Status, dto.ResponseJson{
 			Error: err.Error,
 		})
 	}
 
+	if post.HiddenAt != nil {
+		return ctx.JSON(http.StatusOK, dto.ResponseJson{
+			Message: "post is waiting for moderation",
+			Data:    map[string]interface{}{"post_id": postID},
+		})
+	}
+
 	return ctx.JSON(http.StatusOK, dto.ResponseJson{
 		Message: "Post updated successfully",
 		Data:    map[string]interface{}{"post_id": postID},
@@ -300,3 +405,58 @@ func (handler *PostHandler) DeletePost(ctx echo.Context) error {
 		Data:    postID,
 	})
 }
+
+// Lock or unlock the comments on a post
+//
+// @Summary 	lock comments
+// @Description lock or unlock the comments on a post, reading them stays possible
+// @ID 			lock-comments
+// @Tags 		Posts
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @param 		Lock_comments  body dto.CommentLock true "Lock or unlock the comments"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/{postID}/comments/lock [put]
+func (handler *PostHandler) LockComments(ctx echo.Context) error {
+	var lock dto.CommentLock
+
+	id := ctx.Param("post_id")
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&lock); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the lock comments service
+	if errorResponse := handler.PostServices.LockComments(postID, lock.Locked, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	message := "Comments unlocked successfully"
+	if lock.Locked {
+		message = "Comments locked successfully"
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: message,
+		Data:    map[string]interface{}{"post_id": postID, "comments_locked": lock.Locked},
+	})
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PublicHandler struct {
+	services.PublicServices
+}
+
+// retrieve the published posts
+//
+// @Summary 	Get published posts
+// @Description Get the published posts without logging in
+// @ID 			get-public-posts
+// @Tags 		Public
+// @Produce 	json
+// @Param       category query string false "Enter the category slug"
+// @Param       tag query string false "Enter the tag slug"
+// @Param       author query string false "Enter the author username"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/posts [get]
+func (handler *PublicHandler) GetPosts(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	filter := dto.PublicPostFilter{
+		Category: ctx.QueryParam("category"),
+		Tag:      ctx.QueryParam("tag"),
+		Author:   ctx.QueryParam("author"),
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	//call the retrieve published posts service
+	posts, count, errorResponse := handler.PublicServices.GetPosts(filter, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Posts retrieved successfully",
+		Data:         posts,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve a published post using its slug
+//
+// @Summary 	Get published post
+// @Description Get a published post using its slug without logging in, old slugs are redirected to the current one
+// @ID 			get-public-post
+// @Tags 		Public
+// @Produce 	json
+// @param 		slug  path string true "Enter the post slug"
+// @Success 	200 {object} dto.ResponseJson
+// @Success 	301 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/posts/{slug} [get]
+func (handler *PublicHandler) GetPost(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+
+	viewer := getViewer(ctx)
+	//call the retrieve published post service
+	post, moved, errorResponse := handler.PublicServices.GetPost(slug, viewer, getVisit(ctx, viewer))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	//redirect the old slug to the current one
+	if moved {
+		return ctx.Redirect(http.StatusMovedPermanently, "/v1/public/posts/"+url.PathEscape(post.Slug))
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post retrieved successfully",
+		Data:    post,
+	})
+}
+
+// unlock a password protected post
+//
+// @Summary 	Unlock post
+// @Description Check the password of a protected post and get a grant to send in the X-Post-Grant header
+// @ID 			unlock-public-post
+// @Tags 		Public
+// @Accept		json
+// @Produce 	json
+// @param 		slug  path string true "Enter the post slug"
+// @param 		Unlock_post  body dto.UnlockRequest true "Enter the post password"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		401 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/posts/{slug}/unlock [post]
+func (handler *PublicHandler) UnlockPost(ctx echo.Context) error {
+	var request dto.UnlockRequest
+	slug := ctx.Param("slug")
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if request.Password == "" {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "password cannot be empty",
+		})
+	}
+
+	//call the unlock post service
+	grant, errorResponse := handler.PublicServices.UnlockPost(slug, request.Password)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post unlocked successfully",
+		Data:    grant,
+	})
+}
+
+// retrieve the comments of a published post
+//
+// @Summary 	Get published comments
+// @Description Get the threads of a published post with their answers nested under them without logging in
+// @ID 			get-public-comments
+// @Tags 		Public
+// @Produce 	json
+// @param 		slug  path string true "Enter the post slug"
+// @param 		sort  query string false "Enter the order of the threads, newest, oldest or top"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/posts/{slug}/comments [get]
+func (handler *PublicHandler) GetComments(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+	sort := ctx.QueryParam("sort")
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateCommentSort(sort); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve published comments service
+	comments, count, errorResponse := handler.PublicServices.GetComments(slug, sort, limit, offset, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Comments retrieved successfully",
+		Data:         comments,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve a comment of a published post along with its answers
+//
+// @Summary 	Get published comment thread
+// @Description Get a comment of a published post along with its answers without logging in
+// @ID 			get-public-comment-thread
+// @Tags 		Public
+// @Produce 	json
+// @param 		slug  path string true "Enter the post slug"
+// @param 		commentID  path string true "Enter the comment id"
+// @param 		sort  query string false "Enter the order of the answers, newest, oldest or top"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/posts/{slug}/comments/{commentID} [get]
+func (handler *PublicHandler) GetThread(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+	sort := ctx.QueryParam("sort")
+
+	commentID, err := uuid.Parse(ctx.Param("comment_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateCommentSort(sort); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve published comment thread service
+	comment, errorResponse := handler.PublicServices.GetThread(slug, commentID, sort, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Comment thread retrieved successfully",
+		Data:    comment,
+	})
+}
+
+// retrieve every category
+//
+// @Summary 	Get public categories
+// @Description Get all the categories without logging in
+// @ID 			get-public-categories
+// @Tags 		Public
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/categories [get]
+func (handler *PublicHandler) GetCategories(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve categories service
+	categories, count, errorResponse := handler.PublicServices.GetCategories(limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Categories retrieved successfully",
+		Data:         categories,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve the public profile of an author
+//
+// @Summary 	Get author profile
+// @Description Get the public profile of an author without logging in
+// @ID 			get-public-author
+// @Tags 		Public
+// @Produce 	json
+// @param 		username  path string true "Enter the username"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		429 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/public/authors/{username} [get]
+func (handler *PublicHandler) GetAuthor(ctx echo.Context) error {
+	username := ctx.Param("username")
+
+	//call the retrieve author service
+	author, errorResponse := handler.PublicServices.GetAuthor(username)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Author retrieved successfully",
+		Data:    author,
+	})
+}
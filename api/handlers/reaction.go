@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ReactionHandler struct {
+	services.ReactionServices
+}
+
+// add a reaction, or remove it when the user already reacted with the same type
+//
+// @Summary 	Toggle reaction
+// @Description Add or remove a reaction on a post or comment
+// @ID 			toggle-reaction
+// @Tags 		Reactions
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		targetType  path string true "post or comment, reply is another name of comment"
+// @param 		targetID  path string true "Enter the post or comment id"
+// @param 		Reaction  body dto.ReactionRequest true "Enter the reaction type"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/reactions/{targetType}/{targetID} [post]
+func (handler *ReactionHandler) ToggleReaction(ctx echo.Context) error {
+	var request dto.ReactionRequest
+
+	targetType := ctx.Param("target_type")
+	if err := validation.ValidateReactionTarget(targetType); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//replies became comments keeping their ids, so reply is another name of comment
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("target_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateReactionType(request.Type); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	reaction := models.Reaction{
+		TargetType: targetType,
+		TargetID:   targetID,
+		UserID:     viewer.UserID,
+		Type:       request.Type,
+	}
+
+	//call the toggle reaction service
+	result, errorResponse := handler.ReactionServices.ToggleReaction(&reaction, viewer)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	message := "reaction removed successfully"
+	if result.Reacted {
+		message = "reaction added successfully"
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: message,
+		Data:    result,
+	})
+}
+
+// retrieve the users who reacted to a post or comment
+//
+// @Summary 	Get reactions
+// @Description Get the users who reacted to a post or comment
+// @ID 			get-reactions
+// @Tags 		Reactions
+// @Security 	JWT
+// @Produce 	json
+// @param 		targetType  path string true "post or comment, reply is another name of comment"
+// @param 		targetID  path string true "Enter the post or comment id"
+// @Param       type query string false "Enter the reaction type"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/reactions/{targetType}/{targetID} [get]
+func (handler *ReactionHandler) GetReactors(ctx echo.Context) error {
+	reactionType := ctx.QueryParam("type")
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	targetType := ctx.Param("target_type")
+	if err := validation.ValidateReactionTarget(targetType); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//replies became comments keeping their ids, so reply is another name of comment
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("target_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if reactionType != "" {
+		if err := validation.ValidateReactionType(reactionType); err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		}
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve reactions service
+	reactors, count, errorResponse := handler.ReactionServices.GetReactors(targetType, targetID, reactionType, limit, offset, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Reactions retrieved successfully",
+		Data:         reactors,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
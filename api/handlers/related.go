@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type RelatedHandler struct {
+	services.RelatedServices
+}
+
+// retrieve the posts similar to a post
+//
+// @Summary 	Get related posts
+// @Description Get the published posts sharing the most tags, the category and words with a post
+// @ID 			get-related-posts
+// @Tags 		Posts
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Param       limit query string false "Enter the limit"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/posts/{postID}/related [get]
+func (handler *RelatedHandler) GetRelatedPosts(ctx echo.Context) error {
+	limit := constants.DefaultRelatedLimit
+
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > constants.MaxRelatedPosts {
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: fmt.Sprintf("limit must be between 1 and %d", constants.MaxRelatedPosts),
+			})
+		}
+	}
+
+	//call the retrieve related posts service
+	related, errorResponse := handler.RelatedServices.GetRelatedPosts(postID, limit, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Related posts retrieved successfully",
+		Data:    related,
+		Limit:   limit,
+	})
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	services.ReportServices
+}
+
+// report a post or comment
+//
+// @Summary 	Report content
+// @Description Report a post, comment or reply for spam, harassment, hate, violence, sexual content, misinformation or another reason, the content is hidden once enough users report it
+// @ID 			create-report
+// @Tags 		Reports
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		Report  body models.Report true "Enter the target type, target id, reason and details"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		409 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/reports [post]
+func (handler *ReportHandler) CreateReport(ctx echo.Context) error {
+	var report models.Report
+
+	if err := ctx.Bind(&report); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//replies are comments on comments
+	if report.TargetType == constants.ReactionTargetReply {
+		report.TargetType = constants.ReactionTargetComment
+	}
+
+	if err := validation.ValidateReport(&report); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	report.ReporterID = viewer.UserID
+
+	//call the create report service
+	if errorResponse := handler.ReportServices.CreateReport(&report, viewer); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "Report submitted successfully",
+		Data:    report,
+	})
+}
+
+// retrieve the open reports grouped by the reported content
+//
+// @Summary 	Get reports
+// @Description Get the open reports grouped by the reported content, the most reported first
+// @ID 			get-reports
+// @Tags 		Reports
+// @Security 	JWT
+// @Produce 	json
+// @Param       target_type query string false "Enter the target type, post or comment"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/reports [get]
+func (handler *ReportHandler) GetReportGroups(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	targetType := ctx.QueryParam("target_type")
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+	if err := validation.ValidateReportTarget(targetType); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve report groups service
+	groups, count, errorResponse := handler.ReportServices.GetReportGroups(targetType, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Reports retrieved successfully",
+		Data:         groups,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve the reports of a post or comment
+//
+// @Summary 	Get content reports
+// @Description Get every report of a post or comment along with the reporters, the latest first
+// @ID 			get-content-reports
+// @Tags 		Reports
+// @Security 	JWT
+// @Produce 	json
+// @param 		targetType  path string true "Enter the target type, post, comment or reply"
+// @param 		targetID  path string true "Enter the target id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/reports/{targetType}/{targetID} [get]
+func (handler *ReportHandler) GetReports(ctx echo.Context) error {
+	targetType, targetID, err := reportTarget(ctx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//call the retrieve reports service
+	reports, errorResponse := handler.ReportServices.GetReports(targetType, targetID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Reports retrieved successfully",
+		Data:    reports,
+	})
+}
+
+// resolve the open reports of a post or comment
+//
+// @Summary 	Resolve reports
+// @Description Dismiss the reports and show the content again, or remove the content and optionally warn or suspend its author
+// @ID 			resolve-reports
+// @Tags 		Reports
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		targetType  path string true "Enter the target type, post, comment or reply"
+// @param 		targetID  path string true "Enter the target id"
+// @param 		Resolution  body dto.ReportResolution true "Enter the action, a note and the days of the suspension"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/reports/{targetType}/{targetID}/resolve [post]
+func (handler *ReportHandler) ResolveReports(ctx echo.Context) error {
+	var resolution dto.ReportResolution
+
+	targetType, targetID, err := reportTarget(ctx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&resolution); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	if !validation.ValidateModerator(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	if err := validation.ValidateReportResolution(&resolution); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the resolve reports service
+	audit, errorResponse := handler.ReportServices.ResolveReports(targetType, targetID, &resolution, viewer.UserID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Reports resolved successfully",
+		Data:    audit,
+	})
+}
+
+// retrieve the audit log
+//
+// @Summary 	Get audit log
+// @Description Get the actions taken on reported content and its authors the latest first, optionally of a single post or comment
+// @ID 			get-audit-log
+// @Tags 		Reports
+// @Security 	JWT
+// @Produce 	json
+// @Param       target_type query string false "Enter the target type, post or comment"
+// @Param       target_id query string false "Enter the target id"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/audit [get]
+func (handler *ReportHandler) GetAuditLogs(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	targetType := ctx.QueryParam("target_type")
+	targetIDStr := ctx.QueryParam("target_id")
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+	if err := validation.ValidateReportTarget(targetType); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	targetID := uuid.Nil
+	if targetIDStr != "" {
+		if targetID, err = uuid.Parse(targetIDStr); err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		}
+	}
+
+	//call the retrieve audit log service
+	logs, count, errorResponse := handler.ReportServices.GetAuditLogs(targetType, targetID, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Audit log retrieved successfully",
+		Data:         logs,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// read the reported content from the path, replies are comments on comments
+func reportTarget(ctx echo.Context) (string, uuid.UUID, error) {
+	targetType := ctx.Param("target_type")
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+
+	if err := validation.ValidateReportTarget(targetType); err != nil {
+		return "", uuid.Nil, err
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("target_id"))
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+
+	return targetType, targetID, nil
+}
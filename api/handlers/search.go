@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SearchHandler struct {
+	services.SearchServices
+}
+
+// search the posts and comments
+//
+// @Summary 	Search
+// @Description Full text search over the posts and optionally the comments, ranked by relevance.
+// @Description Quote words to search for a phrase, end a word with * to match it as a prefix and use - to exclude a word
+// @ID 			search
+// @Tags 		Search
+// @Security 	JWT
+// @Produce 	json
+// @param 		q  query string true "Enter the search text"
+// @param 		type  query string false "Search posts, comments or all"
+// @param 		category_id  query string false "Enter the category id"
+// @param 		user_id  query string false "Enter the author id"
+// @param 		limit  query string false "Enter the limit"
+// @param 		offset  query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/search [get]
+func (handler *SearchHandler) Search(ctx echo.Context) error {
+	query := dto.SearchQuery{
+		Query:  ctx.QueryParam("q"),
+		Type:   ctx.QueryParam("type"),
+		Viewer: getViewer(ctx),
+	}
+
+	if query.Query == "" {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "search query cannot be empty",
+		})
+	}
+
+	if query.Type == "" {
+		query.Type = constants.SearchTypePosts
+	} else if query.Type != constants.SearchTypePosts && query.Type != constants.SearchTypeComments && query.Type != constants.SearchTypeAll {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "type must be either posts, comments or all",
+		})
+	}
+
+	if id := ctx.QueryParam("category_id"); id != "" {
+		categoryID, err := uuid.Parse(id)
+		if err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		}
+		query.CategoryID = categoryID
+	}
+
+	if id := ctx.QueryParam("user_id"); id != "" {
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		}
+		query.UserID = userID
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(ctx.QueryParam("limit"), ctx.QueryParam("offset"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+	query.Limit = limit
+	query.Offset = offset
+
+	//call the search service
+	results, count, errorResponse := handler.SearchServices.Search(query)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Search results retrieved successfully",
+		Data:         results,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SeriesHandler struct {
+	services.SeriesServices
+}
+
+// create a new series
+//
+// @Summary 	Create series
+// @Description Create a series to group posts such as the parts of a tutorial
+// @ID 			create-series
+// @Tags 		Series
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		Series  body models.Series true "Enter the title and description"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series [post]
+func (handler *SeriesHandler) CreateSeries(ctx echo.Context) error {
+	var series models.Series
+
+	if err := ctx.Bind(&series); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateSeries(&series); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	series.UserID = getViewer(ctx).UserID
+	series.Parts = nil
+
+	//call the create series service
+	if errorResponse := handler.SeriesServices.CreateSeries(&series); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "series created successfully",
+		Data:    series,
+	})
+}
+
+// retrieve the series of the user
+//
+// @Summary 	Get own series
+// @Description Get the series of the user along with the number of posts in them
+// @ID 			get-own-series
+// @Tags 		Series
+// @Security 	JWT
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series [get]
+func (handler *SeriesHandler) GetSeriesList(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve series service
+	series, count, errorResponse := handler.SeriesServices.GetSeriesList(getViewer(ctx).UserID, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Series retrieved successfully",
+		Data:         series,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// retrieve a series along with its posts in order
+//
+// @Summary 	Get series
+// @Description Get a series along with the posts the user can see in order
+// @ID 			get-series
+// @Tags 		Series
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/series/{seriesID} [get]
+func (handler *SeriesHandler) GetSeries(ctx echo.Context) error {
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve series service
+	series, errorResponse := handler.SeriesServices.GetSeries(seriesID, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Series retrieved successfully",
+		Data:    series,
+	})
+}
+
+// update a existing series
+//
+// @Summary 	Update series
+// @Description Update the title and description of a series
+// @ID 			update-series
+// @Tags 		Series
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @param 		Series  body models.Series true "Enter the title and description"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series/{seriesID} [put]
+func (handler *SeriesHandler) UpdateSeries(ctx echo.Context) error {
+	var series models.Series
+
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&series); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateSeries(&series); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the update series service
+	if errorResponse := handler.SeriesServices.UpdateSeries(&series, seriesID, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Series updated successfully",
+		Data:    seriesID,
+	})
+}
+
+// delete a existing series
+//
+// @Summary 	Delete series
+// @Description Delete a series, its posts are kept
+// @ID 			delete-series
+// @Tags 		Series
+// @Security 	JWT
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series/{seriesID} [delete]
+func (handler *SeriesHandler) DeleteSeries(ctx echo.Context) error {
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the delete series service
+	if errorResponse := handler.SeriesServices.DeleteSeries(seriesID, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Series deleted successfully",
+		Data:    seriesID,
+	})
+}
+
+// add a post to a series
+//
+// @Summary 	Add series part
+// @Description Add a post at the end of the series, a post belongs to one series at most
+// @ID 			add-series-part
+// @Tags 		Series
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @param 		Part  body dto.SeriesPartRequest true "Enter the post id"
+// @Success 	201 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		409 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series/{seriesID}/posts [post]
+func (handler *SeriesHandler) AddPart(ctx echo.Context) error {
+	var request dto.SeriesPartRequest
+
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if request.PostID == uuid.Nil {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "post id is required",
+		})
+	}
+
+	//call the add part service
+	part, errorResponse := handler.SeriesServices.AddPart(seriesID, request.PostID, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.ResponseJson{
+		Message: "post added to the series successfully",
+		Data:    part,
+	})
+}
+
+// change the order of the posts in a series
+//
+// @Summary 	Reorder series parts
+// @Description Change the order of the posts in a series, every post of the series has to be given
+// @ID 			reorder-series-parts
+// @Tags 		Series
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @param 		Order  body dto.SeriesOrderRequest true "Enter the post ids in order"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series/{seriesID}/posts [put]
+func (handler *SeriesHandler) ReorderParts(ctx echo.Context) error {
+	var request dto.SeriesOrderRequest
+
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the reorder parts service
+	if errorResponse := handler.SeriesServices.ReorderParts(seriesID, request.PostIDs, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Series reordered successfully",
+		Data:    request.PostIDs,
+	})
+}
+
+// remove a post from a series
+//
+// @Summary 	Remove series part
+// @Description Remove a post from a series, the posts after it move up
+// @ID 			remove-series-part
+// @Tags 		Series
+// @Security 	JWT
+// @Produce 	json
+// @param 		seriesID  path string true "Enter the series id"
+// @param 		postID  path string true "Enter the post id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/series/{seriesID}/posts/{postID} [delete]
+func (handler *SeriesHandler) RemovePart(ctx echo.Context) error {
+	seriesID, err := uuid.Parse(ctx.Param("series_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the remove part service
+	if errorResponse := handler.SeriesServices.RemovePart(seriesID, postID, getViewer(ctx)); errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post removed from the series successfully",
+		Data:    postID,
+	})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SitemapHandler struct {
+	services.SitemapServices
+}
+
+// retrieve the sitemap of the site
+//
+// @Summary 	Sitemap
+// @Description Get the sitemap of the published posts and categories, or the sitemap index once there are more than 50000 urls
+// @ID 			get-sitemap
+// @Tags 		Sitemap
+// @Produce 	xml
+// @Success 	200 {string} string
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/sitemap.xml [get]
+func (handler *SitemapHandler) GetSitemap(ctx echo.Context) error {
+	//call the sitemap service
+	body, errorResponse := handler.SitemapServices.GetSitemap()
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, body)
+}
+
+// retrieve the sitemap of the categories
+//
+// @Summary 	Category sitemap
+// @Description Get the sitemap of the home page and the categories listed in the sitemap index
+// @ID 			get-category-sitemap
+// @Tags 		Sitemap
+// @Produce 	xml
+// @Success 	200 {string} string
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/sitemaps/categories.xml [get]
+func (handler *SitemapHandler) GetCategorySitemap(ctx echo.Context) error {
+	//call the category sitemap service
+	body, errorResponse := handler.SitemapServices.GetCategorySitemap()
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, body)
+}
+
+// retrieve a page of the post sitemaps
+//
+// @Summary 	Post sitemap
+// @Description Get a page of the post sitemaps listed in the sitemap index
+// @ID 			get-post-sitemap
+// @Tags 		Sitemap
+// @Produce 	xml
+// @param 		page  path string true "Enter the page followed by .xml"
+// @Success 	200 {string} string
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/sitemaps/posts-{page} [get]
+func (handler *SitemapHandler) GetPostSitemap(ctx echo.Context) error {
+	pageStr, found := strings.CutSuffix(ctx.Param("page"), ".xml")
+	page, err := strconv.Atoi(pageStr)
+	if !found || err != nil {
+		return ctx.JSON(http.StatusNotFound, dto.ResponseJson{
+			Error: "sitemap not found",
+		})
+	}
+
+	//call the post sitemap service
+	body, errorResponse := handler.SitemapServices.GetPostSitemap(page)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, body)
+}
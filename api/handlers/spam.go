@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type SpamHandler struct {
+	services.SpamServices
+}
+
+// retrieve the spam checks
+//
+// @Summary 	Get spam checks
+// @Description Get the spam checks of the posts and comments the latest first along with the score of every checker, used to tune the checkers
+// @ID 			get-spam-checks
+// @Tags 		Moderation
+// @Security 	JWT
+// @Produce 	json
+// @Param       decision query string false "Enter the decision, allow, hold or reject"
+// @Param       target_type query string false "Enter the target type, post or comment"
+// @Param       review query string false "Enter the review, ham, spam or none"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/spam [get]
+func (handler *SpamHandler) GetSpamChecks(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	decision := ctx.QueryParam("decision")
+	targetType := ctx.QueryParam("target_type")
+	review := ctx.QueryParam("review")
+
+	if !validation.ValidateModerator(getViewer(ctx).Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if targetType == constants.ReactionTargetReply {
+		targetType = constants.ReactionTargetComment
+	}
+	if err := validation.ValidateReportTarget(targetType); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+	if err := validation.ValidateSpamFilters(decision, review); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve spam checks service
+	checks, count, errorResponse := handler.SpamServices.GetSpamChecks(decision, targetType, review, limit, offset)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Spam checks retrieved successfully",
+		Data:         checks,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// review a spam check
+//
+// @Summary 	Review spam check
+// @Description Tell whether the checked content was spam or ham, held or rejected content marked as ham is shown and content marked as spam stays hidden
+// @ID 			review-spam-check
+// @Tags 		Moderation
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		checkID  path string true "Enter the spam check id"
+// @param 		Review  body dto.SpamReview true "Enter ham or spam"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/moderation/spam/{checkID} [put]
+func (handler *SpamHandler) ReviewSpamCheck(ctx echo.Context) error {
+	var review dto.SpamReview
+
+	checkID, err := uuid.Parse(ctx.Param("check_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&review); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	viewer := getViewer(ctx)
+	if !validation.ValidateModerator(viewer.Role) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins and moderators are allowed",
+		})
+	}
+
+	if err := validation.ValidateSpamReview(review.Review); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the review spam check service
+	check, errorResponse := handler.SpamServices.ReviewSpamCheck(checkID, review.Review, viewer.UserID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Spam check reviewed successfully",
+		Data:    check,
+	})
+}
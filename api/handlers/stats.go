@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type StatsHandler struct {
+	services.StatsServices
+}
+
+// retrieve the views of a post
+//
+// @Summary 	Get post stats
+// @Description Get the daily views, unique visitors and top referrers of a post, only the author and the admin can view them
+// @ID 			get-post-stats
+// @Tags 		Posts
+// @Security 	JWT
+// @Produce 	json
+// @param 		postID  path string true "Enter the post id"
+// @Param       start_date query string false "Enter the start date (YYYY-MM-DD)"
+// @Param       end_date query string false "Enter the end date (YYYY-MM-DD)"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/users/post/{postID}/stats [get]
+func (handler *StatsHandler) GetPostStats(ctx echo.Context) error {
+	postID, err := uuid.Parse(ctx.Param("post_id"))
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//default to the last days up to today
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -(constants.DefaultStatsDays - 1))
+
+	if date := ctx.QueryParam("start_date"); date != "" {
+		if from, err = time.Parse(time.DateOnly, date); err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: "start_date must be in the YYYY-MM-DD format",
+			})
+		}
+	}
+
+	if date := ctx.QueryParam("end_date"); date != "" {
+		if to, err = time.Parse(time.DateOnly, date); err != nil {
+			loggers.Warn.Println(err)
+			return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+				Error: "end_date must be in the YYYY-MM-DD format",
+			})
+		}
+	}
+
+	if from.After(to) {
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: "start_date cannot be after end_date",
+		})
+	}
+
+	userIDCtx := ctx.Get("user_id").(string)
+	userID, err := uuid.Parse(userIDCtx)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	roleCtx := ctx.Get("role").(string)
+	//call the retrieve post stats service
+	stats, errorResponse := handler.StatsServices.GetPostStats(postID, userID, roleCtx, from, to)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Post stats retrieved successfully",
+		Data:    stats,
+	})
+}
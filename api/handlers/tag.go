@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TagHandler struct {
+	services.TagServices
+}
+
+// Retrieve every tag along with its usage count
+//
+// @Summary 	Get tags
+// @Description Get all the tags along with the number of posts using them
+// @ID 			get-tags
+// @Tags 		Tags
+// @Security 	JWT
+// @Produce 	json
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Param       sort query string false "Sort by popular or name"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/tags [get]
+func (handler *TagHandler) GetTags(ctx echo.Context) error {
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+	sort := ctx.QueryParam("sort")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve tags service
+	tags, count, errorResponse := handler.TagServices.GetTags(limit, offset, sort)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "retrieved tags successfully",
+		Data:         tags,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// Retrieve the posts labelled with a tag
+//
+// @Summary 	Get posts by tag
+// @Description Get all the posts labelled with the tag
+// @ID 			get-posts-by-tag
+// @Tags 		Tags
+// @Security 	JWT
+// @Produce 	json
+// @param 		slug  path string true "Enter the tag slug"
+// @Param       limit query string false "Enter the limit"
+// @Param       offset query string false "Enter the offset"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/tags/{slug}/posts [get]
+func (handler *TagHandler) GetPostsByTag(ctx echo.Context) error {
+	slug := ctx.Param("slug")
+	offsetStr := ctx.QueryParam("offset")
+	limitStr := ctx.QueryParam("limit")
+
+	//pagination
+	limit, offset, err := helpers.Pagination(limitStr, offsetStr)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	//call the retrieve posts by tag service
+	posts, count, errorResponse := handler.TagServices.GetPostsByTag(slug, limit, offset, getViewer(ctx))
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message:      "Posts retrieved successfully",
+		Data:         posts,
+		Limit:        limit,
+		Offset:       offset,
+		TotalRecords: count,
+	})
+}
+
+// rename an existing tag
+//
+// @Summary 	Rename tag
+// @Description Rename an existing tag
+// @ID 			rename-tag
+// @Tags 		Tags
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		tagID  path string true "Enter the tag id"
+// @param 		RenameDetails  body models.Tag true "Enter the new tag name"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		409 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/tags/{tagID} [put]
+func (handler *TagHandler) RenameTag(ctx echo.Context) error {
+	var tag models.Tag
+
+	id := ctx.Param("tag_id")
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&tag); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := validation.ValidateTagName(tag.Name); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	roleCtx := ctx.Get("role").(string)
+	if !validation.ValidateRole(roleCtx) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	//call the rename tag service
+	renamed, errorResponse := handler.TagServices.RenameTag(tagID, tag.Name)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Tag renamed successfully",
+		Data:    renamed,
+	})
+}
+
+// merge a tag into another tag
+//
+// @Summary 	Merge tag
+// @Description Move every post of the tag to the target tag and delete the tag
+// @ID 			merge-tag
+// @Tags 		Tags
+// @Security 	JWT
+// @Accept		json
+// @Produce 	json
+// @param 		tagID  path string true "Enter the id of the tag to merge"
+// @param 		MergeDetails  body dto.MergeTagRequest true "Enter the target tag id"
+// @Success 	200 {object} dto.ResponseJson
+// @Failure		400 {object} dto.ResponseJson
+// @Failure		403 {object} dto.ResponseJson
+// @Failure		404 {object} dto.ResponseJson
+// @Failure		500 {object} dto.ResponseJson
+// @Router 		/v1/admin/tags/{tagID}/merge [post]
+func (handler *TagHandler) MergeTag(ctx echo.Context) error {
+	var request dto.MergeTagRequest
+
+	id := ctx.Param("tag_id")
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	if err := ctx.Bind(&request); err != nil {
+		loggers.Warn.Println(err)
+		return ctx.JSON(http.StatusBadRequest, dto.ResponseJson{
+			Error: err.Error(),
+		})
+	}
+
+	roleCtx := ctx.Get("role").(string)
+	if !validation.ValidateRole(roleCtx) {
+		return ctx.JSON(http.StatusForbidden, dto.ResponseJson{
+			Message: "Only admins are allowed",
+		})
+	}
+
+	//call the merge tag service
+	errorResponse := handler.TagServices.MergeTag(tagID, request.TargetTagID)
+	if errorResponse != nil {
+		loggers.Warn.Println(errorResponse.Error)
+		return ctx.JSON(errorResponse.Status, dto.ResponseJson{
+			Error: errorResponse.Error,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.ResponseJson{
+		Message: "Tags merged successfully",
+		Data: map[string]interface{}{
+			"tag_id": request.TargetTagID,
+		},
+	})
+}
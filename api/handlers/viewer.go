@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// retrieve the logged in user, if any, along with the posts unlocked by the grants sent in the request
+func getViewer(ctx echo.Context) dto.Viewer {
+	var viewer dto.Viewer
+
+	if userID, ok := ctx.Get("user_id").(string); ok {
+		viewer.UserID, _ = uuid.Parse(userID)
+	}
+	viewer.Role, _ = ctx.Get("role").(string)
+
+	//invalid or expired grants are ignored
+	for _, token := range strings.Split(ctx.Request().Header.Get(constants.PostGrantHeader), ",") {
+		if postID, err := validation.ParsePostGrant(strings.TrimSpace(token)); err == nil {
+			viewer.Grants = append(viewer.Grants, postID)
+		}
+	}
+
+	return viewer
+}
+
+// identify the visitor reading a post and the site they came from
+func getVisit(ctx echo.Context, viewer dto.Viewer) dto.Visit {
+	visit := dto.Visit{Referrer: constants.ReferrerDirect}
+
+	//anonymous visitors are told apart by a hash of their ip and browser
+	if viewer.UserID != uuid.Nil {
+		visit.Visitor = "user:" + viewer.UserID.String()
+	} else {
+		sum := sha256.Sum256([]byte(ctx.RealIP() + "|" + ctx.Request().UserAgent()))
+		visit.Visitor = "anonymous:" + hex.EncodeToString(sum[:16])
+	}
+
+	if referrer, err := url.Parse(ctx.Request().Referer()); err == nil && referrer.Hostname() != "" {
+		visit.Referrer = strings.ToLower(referrer.Hostname())
+	}
+
+	return visit
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+
+	"github.com/labstack/echo/v4"
+)
+
+// lets browsers and proxies cache the successful responses for the given seconds
+func CacheControl(maxAge int) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			response := c.Response()
+
+			//the status is only known once the handler writes the response
+			response.Before(func() {
+				if response.Header().Get(echo.HeaderCacheControl) != "" {
+					return
+				}
+
+				//responses for logged in visitors or unlocked posts must not be shared
+				personal := c.Request().Header.Get(constants.PostGrantHeader) != ""
+				if _, err := c.Cookie("Authorization"); err == nil {
+					personal = true
+				}
+
+				if c.Request().Method != http.MethodGet || response.Status >= http.StatusBadRequest {
+					response.Header().Set(echo.HeaderCacheControl, "no-store")
+				} else if personal {
+					response.Header().Set(echo.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", maxAge))
+				} else {
+					response.Header().Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", maxAge))
+				}
+				response.Header().Add(echo.HeaderVary, "Cookie, "+constants.PostGrantHeader)
+			})
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sets the user details when the visitor is logged in, anonymous visitors are let through
+func OptionalToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		tokenString, err := c.Cookie("Authorization")
+		if err != nil {
+			return next(c)
+		}
+
+		//an invalid or expired token is treated as an anonymous visitor
+		claims, err := validation.ParseToken(tokenString.Value)
+		if err != nil {
+			loggers.Warn.Println(err)
+			return next(c)
+		}
+
+		c.Set("user_id", claims["user_id"])
+		c.Set("role", claims["role"])
+		c.Set("email", claims["email"])
+
+		return next(c)
+	}
+}
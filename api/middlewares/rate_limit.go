@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/ratelimit"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// limits every client ip to the given number of requests per minute
+func RateLimitByIP(requestsPerMinute int) echo.MiddlewareFunc {
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(requestsPerMinute) / 60),
+		Burst:     requestsPerMinute,
+		ExpiresIn: 3 * time.Minute,
+	})
+
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: store,
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.RealIP(), nil
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			return c.JSON(http.StatusForbidden, dto.ResponseJson{
+				Error: err.Error(),
+			})
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			c.Response().Header().Set("Retry-After", "60")
+			return c.JSON(http.StatusTooManyRequests, dto.ResponseJson{
+				Message: "Too many requests, please try again later",
+			})
+		},
+	})
+}
+
+// limits the action of the logged in user to its token bucket, the users with an exempt role are not limited
+func RateLimitByUser(limiter *ratelimit.Limiter, action string, exemptRoles []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get("role").(string)
+			for _, exempt := range exemptRoles {
+				if role == exempt {
+					return next(c)
+				}
+			}
+
+			key, _ := c.Get("user_id").(string)
+			if key == "" {
+				key = c.RealIP()
+			}
+
+			return limitRequest(c, next, limiter, action, key)
+		}
+	}
+}
+
+// limits the login attempts of every client ip, of every account from that ip and of every account from all the ips,
+// the limit of an account from all the ips is higher so others can hardly lock the account out
+//
+// the account is read from the email in the body, which is limited in size
+func RateLimitLogin(limiter *ratelimit.Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var login dto.LoginRequest
+
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, constants.MaxLoginBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return c.JSON(http.StatusRequestEntityTooLarge, dto.ResponseJson{
+						Error: err.Error(),
+					})
+				}
+				return c.JSON(http.StatusBadRequest, dto.ResponseJson{
+					Error: err.Error(),
+				})
+			}
+			//the handler reads the body again
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			//every attempt of the ip counts, whichever account it tries
+			ip := c.RealIP()
+			buckets := [][2]string{{constants.RateLimitActionLoginIP, ip}}
+			if json.Unmarshal(body, &login) == nil && strings.TrimSpace(login.Email) != "" {
+				account := strings.ToLower(strings.TrimSpace(login.Email))
+				buckets = append(buckets, [2]string{constants.RateLimitActionLogin, account + "|" + ip},
+					[2]string{constants.RateLimitActionLoginAccount, account})
+			}
+
+			//the request has to get a token from every bucket, the first one is checked first
+			handler := next
+			for i := len(buckets) - 1; i >= 0; i-- {
+				action, key, inner := buckets[i][0], buckets[i][1], handler
+				handler = func(c echo.Context) error {
+					return limitRequest(c, inner, limiter, action, key)
+				}
+			}
+			return handler(c)
+		}
+	}
+}
+
+// take a token for the request and tell the client how many are left, the request is allowed when the store fails
+func limitRequest(c echo.Context, next echo.HandlerFunc, limiter *ratelimit.Limiter, action string, key string) error {
+	result, err := limiter.Allow(c.Request().Context(), action, key)
+	if err != nil {
+		loggers.Warn.Println("Failed to check the rate limit", err)
+		return next(c)
+	} else if result.Limit == 0 {
+		return next(c)
+	}
+
+	header := c.Response().Header()
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit, int(limiter.Limits[action].Period.Seconds())))
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(int(result.Reset.Seconds())))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+		return c.JSON(http.StatusTooManyRequests, dto.ResponseJson{
+			Message: "Too many requests, please try again later",
+		})
+	}
+
+	return next(c)
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// rejects the requests of suspended users changing anything, they can still read during their suspension
+func RejectSuspended(check func(userID uuid.UUID) *dto.ErrorResponse) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			userIDCtx, _ := c.Get("user_id").(string)
+			userID, err := uuid.Parse(userIDCtx)
+			if err != nil {
+				loggers.Warn.Println(err)
+				return c.JSON(http.StatusBadRequest, dto.ResponseJson{
+					Error: err.Error(),
+				})
+			}
+
+			if errorResponse := check(userID); errorResponse != nil {
+				loggers.Warn.Println(errorResponse.Error)
+				return c.JSON(errorResponse.Status, dto.ResponseJson{
+					Error: errorResponse.Error,
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package repositories
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/pkg/archive"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// number of rows read and restored at once
+const archiveBatchSize = 500
+
+type ArchiveRepository interface {
+	Export(writer io.Writer, passwords bool) (map[string]int64, error)
+	Restore(reader io.Reader) (*archive.Header, map[string]int64, error)
+}
+
+type archiveRepository struct {
+	*gorm.DB
+}
+
+func InitArchiveRepository(db *gorm.DB) ArchiveRepository {
+	return &archiveRepository{db}
+}
+
+// write the users, categories, tags, posts and comments to the archive, including the deleted ones
+//
+// the password hashes of the users are only written when asked for
+func (db *archiveRepository) Export(writer io.Writer, passwords bool) (map[string]int64, error) {
+	var counts map[string]int64
+
+	//a single snapshot keeps the rows consistent with each other while the site is in use
+	err := db.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+
+		output, err := archive.NewWriter(writer, archive.Header{ExportedAt: time.Now().UTC(), Passwords: passwords})
+		if err != nil {
+			return err
+		}
+
+		var users []models.User
+		err = tx.FindInBatches(&users, archiveBatchSize, func(batch *gorm.DB, _ int) error {
+			for _, user := range users {
+				row := archive.User{UserID: user.UserID, Email: user.Email, Name: user.Name, Username: user.Username, Role: user.Role,
+					CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt, DeletedAt: deletedAt(user.DeletedAt)}
+				if passwords {
+					row.Password = user.Password
+				}
+
+				if err := output.Write(archive.KindUser, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var categories []models.Category
+		err = tx.FindInBatches(&categories, archiveBatchSize, func(batch *gorm.DB, _ int) error {
+			for _, category := range categories {
+				row := archive.Category{CategoryID: category.CategoryID, CategoryName: category.CategoryName, Slug: category.Slug, Description: category.Description,
+					CreatedAt: category.CreatedAt, UpdatedAt: category.UpdatedAt, DeletedAt: deletedAt(category.DeletedAt)}
+				if category.CommentLockDays != nil {
+					row.CommentLockDays = *category.CommentLockDays
+				}
+
+				if err := output.Write(archive.KindCategory, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var tags []models.Tag
+		err = tx.FindInBatches(&tags, archiveBatchSize, func(batch *gorm.DB, _ int) error {
+			for _, tag := range tags {
+				row := archive.Tag{TagID: tag.TagID, Name: tag.Name, Slug: tag.Slug, CreatedAt: tag.CreatedAt, UpdatedAt: tag.UpdatedAt}
+
+				if err := output.Write(archive.KindTag, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var posts []models.Post
+		err = tx.Preload("Authors").FindInBatches(&posts, archiveBatchSize, func(batch *gorm.DB, _ int) error {
+			postIDs := make([]uuid.UUID, 0, len(posts))
+			for _, post := range posts {
+				postIDs = append(postIDs, post.PostID)
+			}
+
+			var postTags []struct {
+				PostID uuid.UUID
+				TagID  uuid.UUID
+			}
+			if err := tx.Table("post_tags").Where("post_id IN ?", postIDs).Order("post_id, tag_id").Find(&postTags).Error; err != nil {
+				return err
+			}
+
+			tagIDs := make(map[uuid.UUID][]uuid.UUID, len(posts))
+			for _, postTag := range postTags {
+				tagIDs[postTag.PostID] = append(tagIDs[postTag.PostID], postTag.TagID)
+			}
+
+			for _, post := range posts {
+				row := archive.Post{PostID: post.PostID, Title: post.Title, Slug: post.Slug, Content: post.Content, ContentFormat: post.ContentFormat,
+					ContentHTML: post.ContentHTML, Description: post.Description, Status: post.Status, Visibility: post.Visibility,
+					PasswordHash: post.PasswordHash, MetaTitle: post.MetaTitle, MetaDescription: post.MetaDescription,
+					CanonicalURL: post.CanonicalURL, OGImage: post.OGImage, NoIndex: post.NoIndex != nil && *post.NoIndex, CommentsLocked: post.CommentsLocked,
+					UserID: archivedUserID(post.UserID), CategoryID: post.CategoryID, TagIDs: tagIDs[post.PostID],
+					CreatedAt: post.CreatedAt, UpdatedAt: post.UpdatedAt, DeletedAt: deletedAt(post.DeletedAt)}
+
+				for _, author := range post.Authors {
+					row.Authors = append(row.Authors, archive.PostAuthor{UserID: author.UserID, Role: author.Role, Status: author.Status,
+						InvitedBy: author.InvitedBy, CreatedAt: author.CreatedAt, AcceptedAt: author.AcceptedAt})
+				}
+
+				if err := output.Write(archive.KindPost, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		//the comments are written a level at a time so every answer comes after the comment it answers
+		for depth := 0; ; depth++ {
+			var comments []models.Comment
+
+			data := tx.Where("depth=?", depth).FindInBatches(&comments, archiveBatchSize, func(batch *gorm.DB, _ int) error {
+				for _, comment := range comments {
+					row := archive.Comment{CommentID: comment.CommentID, Content: comment.Content, ContentHTML: comment.ContentHTML, UserID: archivedUserID(comment.UserID),
+						PostID: comment.PostID, ParentID: comment.ParentID, Status: comment.Status, EditedAt: comment.EditedAt, EditCount: comment.EditCount, Deleted: comment.Deleted,
+						CreatedAt: comment.CreatedAt, UpdatedAt: comment.UpdatedAt, DeletedAt: deletedAt(comment.DeletedAt)}
+
+					if err := output.Write(archive.KindComment, row); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if data.Error != nil {
+				return data.Error
+			} else if data.RowsAffected == 0 {
+				break
+			}
+		}
+
+		if err := output.Close(); err != nil {
+			return err
+		}
+
+		counts = output.Counts()
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// rebuild the rows of the archive in an empty database keeping their ids and timestamps
+//
+// every row has to refer to rows which come before it in the archive, nothing is restored when a row is invalid
+func (db *archiveRepository) Restore(reader io.Reader) (*archive.Header, map[string]int64, error) {
+	input, err := archive.NewReader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := input.Header()
+
+	//the hooks would give the rows new ids
+	err = db.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		if err := checkEmptyDatabase(tx); err != nil {
+			return err
+		}
+
+		restore := archiveRestore{
+			tx:         tx,
+			users:      make(map[uuid.UUID]bool),
+			categories: make(map[uuid.UUID]bool),
+			tags:       make(map[uuid.UUID]bool),
+			posts:      make(map[uuid.UUID]bool),
+			comments:   make(map[uuid.UUID]archivedComment),
+		}
+
+		for {
+			kind, data, err := input.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			if err := restore.add(kind, data); err != nil {
+				return fmt.Errorf("line %d: %w", input.Line(), err)
+			}
+		}
+
+		return restore.flush()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &header, input.Counts(), nil
+}
+
+// refuse to restore over existing content, the ids of the archive would clash with it
+func checkEmptyDatabase(tx *gorm.DB) error {
+	tables := []struct {
+		name  string
+		model any
+	}{
+		{name: "users", model: &models.User{}},
+		{name: "categories", model: &models.Category{}},
+		{name: "tags", model: &models.Tag{}},
+		{name: "posts", model: &models.Post{}},
+		{name: "comments", model: &models.Comment{}},
+	}
+
+	for _, table := range tables {
+		var count int64
+
+		if err := tx.Unscoped().Model(table.model).Count(&count).Error; err != nil {
+			return err
+		} else if count > 0 {
+			return fmt.Errorf("database is not empty, it already has %d %s", count, table.name)
+		}
+	}
+
+	return nil
+}
+
+// rows of an archive being restored, the ids read so far are kept to check the rows referring to them
+type archiveRestore struct {
+	tx *gorm.DB
+
+	users      map[uuid.UUID]bool
+	categories map[uuid.UUID]bool
+	tags       map[uuid.UUID]bool
+	posts      map[uuid.UUID]bool
+	comments   map[uuid.UUID]archivedComment
+
+	//hash of a random password given to the users exported without their password
+	password string
+
+	pendingUsers      []models.User
+	pendingCategories []models.Category
+	pendingTags       []models.Tag
+	pendingPosts      []models.Post
+	pendingAuthors    []models.PostAuthor
+	pendingPostTags   []map[string]any
+	pendingComments   []models.Comment
+}
+
+// place of a restored comment in its thread
+type archivedComment struct {
+	postID uuid.UUID
+	path   string
+	depth  int
+}
+
+// check the row and queue it, the queued rows are saved once there are enough of them
+func (restore *archiveRestore) add(kind string, data json.RawMessage) error {
+	switch kind {
+	case archive.KindUser:
+		var row archive.User
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := addArchiveID(restore.users, row.UserID, kind); err != nil {
+			return err
+		}
+
+		if row.Password == "" {
+			password, err := restore.randomPassword()
+			if err != nil {
+				return err
+			}
+			row.Password = password
+		}
+
+		restore.pendingUsers = append(restore.pendingUsers, models.User{UserID: row.UserID, Email: row.Email, Name: row.Name, Username: row.Username,
+			Password: row.Password, Role: row.Role, CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt, DeletedAt: gormDeletedAt(row.DeletedAt)})
+	case archive.KindCategory:
+		var row archive.Category
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := addArchiveID(restore.categories, row.CategoryID, kind); err != nil {
+			return err
+		}
+
+		restore.pendingCategories = append(restore.pendingCategories, models.Category{CategoryID: row.CategoryID, CategoryName: row.CategoryName,
+			Slug: row.Slug, Description: row.Description, CommentLockDays: &row.CommentLockDays, CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt,
+			DeletedAt: gormDeletedAt(row.DeletedAt)})
+	case archive.KindTag:
+		var row archive.Tag
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := addArchiveID(restore.tags, row.TagID, kind); err != nil {
+			return err
+		}
+
+		restore.pendingTags = append(restore.pendingTags, models.Tag{TagID: row.TagID, Name: row.Name, Slug: row.Slug, CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt})
+	case archive.KindPost:
+		var row archive.Post
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := addArchiveID(restore.posts, row.PostID, kind); err != nil {
+			return err
+		}
+		userID := restoredUserID(row.UserID)
+		if err := checkArchiveReference(restore.users, userID, archive.KindUser); err != nil {
+			return err
+		}
+		if err := checkArchiveReference(restore.categories, row.CategoryID, archive.KindCategory); err != nil {
+			return err
+		}
+
+		//the rows queued earlier are saved before a post without a user, which is saved on its own
+		if userID == uuid.Nil {
+			if err := restore.flush(); err != nil {
+				return err
+			}
+		}
+
+		for _, author := range row.Authors {
+			if err := checkArchiveReference(restore.users, author.UserID, archive.KindUser); err != nil {
+				return err
+			}
+			if author.InvitedBy != nil {
+				if err := checkArchiveReference(restore.users, *author.InvitedBy, archive.KindUser); err != nil {
+					return err
+				}
+			}
+
+			restore.pendingAuthors = append(restore.pendingAuthors, models.PostAuthor{PostID: row.PostID, UserID: author.UserID, Role: author.Role,
+				Status: author.Status, InvitedBy: author.InvitedBy, CreatedAt: author.CreatedAt, AcceptedAt: author.AcceptedAt})
+		}
+
+		for _, tagID := range row.TagIDs {
+			if err := checkArchiveReference(restore.tags, tagID, archive.KindTag); err != nil {
+				return err
+			}
+
+			restore.pendingPostTags = append(restore.pendingPostTags, map[string]any{"post_id": row.PostID, "tag_id": tagID})
+		}
+
+		noIndex := row.NoIndex
+		post := models.Post{PostID: row.PostID, Title: row.Title, Slug: row.Slug, Content: row.Content,
+			ContentFormat: row.ContentFormat, ContentHTML: row.ContentHTML, Description: row.Description, Status: row.Status, Visibility: row.Visibility,
+			PasswordHash: row.PasswordHash, MetaTitle: row.MetaTitle, MetaDescription: row.MetaDescription, CanonicalURL: row.CanonicalURL,
+			OGImage: row.OGImage, NoIndex: &noIndex, CommentsLocked: row.CommentsLocked, UserID: userID, CategoryID: row.CategoryID,
+			CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt, DeletedAt: gormDeletedAt(row.DeletedAt)}
+		if userID == uuid.Nil {
+			return restore.createWithoutUser(&post)
+		}
+		restore.pendingPosts = append(restore.pendingPosts, post)
+	case archive.KindComment:
+		var row archive.Comment
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := checkArchiveReference(restore.posts, row.PostID, archive.KindPost); err != nil {
+			return err
+		}
+
+		return restore.addComment(row)
+	//the replies of version 1 archives answer the comments starting the threads
+	case archive.KindReply:
+		var row archive.Reply
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+
+		comment := archive.Comment{CommentID: row.ReplyID, Content: row.Content, ContentHTML: row.ContentHTML, UserID: row.UserID,
+			CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt, DeletedAt: row.DeletedAt}
+		if row.CommentID != uuid.Nil {
+			comment.ParentID = &row.CommentID
+		}
+
+		return restore.addComment(comment)
+	}
+
+	if restore.pendingRows() >= archiveBatchSize {
+		return restore.flush()
+	}
+
+	return nil
+}
+
+// check the comment and queue it, the answers are placed in the thread of the comment they answer
+func (restore *archiveRestore) addComment(row archive.Comment) error {
+	userID := restoredUserID(row.UserID)
+	if err := checkArchiveReference(restore.users, userID, archive.KindUser); err != nil {
+		return err
+	}
+	if row.CommentID == uuid.Nil {
+		return fmt.Errorf("%s has no id", archive.KindComment)
+	} else if _, ok := restore.comments[row.CommentID]; ok {
+		return fmt.Errorf("%s %s is duplicated", archive.KindComment, row.CommentID)
+	}
+
+	place := archivedComment{postID: row.PostID, path: row.CommentID.String()}
+	if row.ParentID != nil {
+		parent, ok := restore.comments[*row.ParentID]
+		if !ok {
+			return fmt.Errorf("%s %s does not exist", archive.KindComment, *row.ParentID)
+		} else if row.PostID != uuid.Nil && row.PostID != parent.postID {
+			return fmt.Errorf("%s %s answers a comment of another post", archive.KindComment, row.CommentID)
+		}
+
+		place = archivedComment{postID: parent.postID, path: parent.path + "/" + row.CommentID.String(), depth: parent.depth + 1}
+	}
+	restore.comments[row.CommentID] = place
+
+	//the archives written before moderation only have approved comments
+	if row.Status == "" {
+		row.Status = constants.CommentStatusApproved
+	}
+
+	comment := models.Comment{CommentID: row.CommentID, Content: row.Content, ContentHTML: row.ContentHTML,
+		UserID: userID, PostID: place.postID, ParentID: row.ParentID, Path: place.path, Depth: place.depth, Status: row.Status,
+		EditedAt: row.EditedAt, EditCount: row.EditCount, Deleted: row.Deleted, CreatedAt: row.CreatedAt, UpdatedAt: row.UpdatedAt, DeletedAt: gormDeletedAt(row.DeletedAt)}
+
+	//the comments without a user are saved on their own once the rows they refer to were saved
+	if userID == uuid.Nil {
+		if err := restore.flush(); err != nil {
+			return err
+		}
+		return restore.createWithoutUser(&comment)
+	}
+	restore.pendingComments = append(restore.pendingComments, comment)
+
+	if restore.pendingRows() >= archiveBatchSize {
+		return restore.flush()
+	}
+
+	return nil
+}
+
+// number of rows waiting to be saved
+func (restore *archiveRestore) pendingRows() int {
+	return len(restore.pendingUsers) + len(restore.pendingCategories) + len(restore.pendingTags) + len(restore.pendingPosts) +
+		len(restore.pendingAuthors) + len(restore.pendingPostTags) + len(restore.pendingComments)
+}
+
+// save the queued rows, the rows referred to are saved first
+func (restore *archiveRestore) flush() error {
+	batches := []struct {
+		rows  any
+		count int
+	}{
+		{rows: &restore.pendingUsers, count: len(restore.pendingUsers)},
+		{rows: &restore.pendingCategories, count: len(restore.pendingCategories)},
+		{rows: &restore.pendingTags, count: len(restore.pendingTags)},
+		{rows: &restore.pendingPosts, count: len(restore.pendingPosts)},
+		{rows: &restore.pendingAuthors, count: len(restore.pendingAuthors)},
+		{rows: &restore.pendingComments, count: len(restore.pendingComments)},
+	}
+
+	for _, batch := range batches {
+		if batch.count == 0 {
+			continue
+		}
+
+		if err := restore.tx.Omit(clause.Associations).CreateInBatches(batch.rows, archiveBatchSize).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(restore.pendingPostTags) > 0 {
+		if err := restore.tx.Table("post_tags").CreateInBatches(restore.pendingPostTags, archiveBatchSize).Error; err != nil {
+			return err
+		}
+	}
+
+	restore.pendingUsers = restore.pendingUsers[:0]
+	restore.pendingCategories = restore.pendingCategories[:0]
+	restore.pendingTags = restore.pendingTags[:0]
+	restore.pendingPosts = restore.pendingPosts[:0]
+	restore.pendingAuthors = restore.pendingAuthors[:0]
+	restore.pendingPostTags = restore.pendingPostTags[:0]
+	restore.pendingComments = restore.pendingComments[:0]
+
+	return nil
+}
+
+// save a post or comment whose user was deleted, leaving its user_id NULL instead of the zero uuid
+func (restore *archiveRestore) createWithoutUser(row any) error {
+	return restore.tx.Omit(clause.Associations, "user_id").Create(row).Error
+}
+
+// hash of a random password which nobody knows, computed once since hashing is slow on purpose
+func (restore *archiveRestore) randomPassword() (string, error) {
+	if restore.password != "" {
+		return restore.password, nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), 10)
+	if err != nil {
+		return "", err
+	}
+
+	restore.password = string(password)
+	return restore.password, nil
+}
+
+// remember the id of the row, the ids have to be unique
+func addArchiveID(ids map[uuid.UUID]bool, id uuid.UUID, kind string) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("%s has no id", kind)
+	} else if ids[id] {
+		return fmt.Errorf("%s %s is duplicated", kind, id)
+	}
+
+	ids[id] = true
+	return nil
+}
+
+// check the row referred to came earlier in the archive, rows whose parent was deleted refer to no row
+func checkArchiveReference(ids map[uuid.UUID]bool, id uuid.UUID, kind string) error {
+	if id != uuid.Nil && !ids[id] {
+		return fmt.Errorf("%s %s does not exist", kind, id)
+	}
+
+	return nil
+}
+
+// user of a post or comment for the archive, rows whose user was deleted have none
+func archivedUserID(userID uuid.UUID) *uuid.UUID {
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	return &userID
+}
+
+// user of an archived post or comment, the archives written before also stored the zero uuid for the deleted users
+func restoredUserID(userID *uuid.UUID) uuid.UUID {
+	if userID == nil {
+		return uuid.Nil
+	}
+
+	return *userID
+}
+
+// convert the deletion time of a row for the archive
+func deletedAt(deleted gorm.DeletedAt) *time.Time {
+	if !deleted.Valid {
+		return nil
+	}
+
+	return &deleted.Time
+}
+
+// convert the deletion time of an archived row back
+func gormDeletedAt(deleted *time.Time) gorm.DeletedAt {
+	if deleted == nil {
+		return gorm.DeletedAt{}
+	}
+
+	return gorm.DeletedAt{Time: *deleted, Valid: true}
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthorRepository interface {
+	GetAuthors(postID uuid.UUID, viewer dto.Viewer) (*[]models.PostAuthor, *dto.ErrorResponse)
+	InviteAuthor(author *models.PostAuthor, username string, viewer dto.Viewer) *dto.ErrorResponse
+	RemoveAuthor(postID uuid.UUID, username string, viewer dto.Viewer) *dto.ErrorResponse
+	GetInvitations(userID uuid.UUID, limit, offset int) (*[]models.PostAuthor, int64, *dto.ErrorResponse)
+	AcceptInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+	DeclineInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+}
+
+type authorRepository struct {
+	*gorm.DB
+}
+
+func InitAuthorRepository(db *gorm.DB) AuthorRepository {
+	return &authorRepository{db}
+}
+
+// retrieve the authors of a post, the authors and the admin also see the pending invitations
+func (db *authorRepository) GetAuthors(postID uuid.UUID, viewer dto.Viewer) (*[]models.PostAuthor, *dto.ErrorResponse) {
+	var authors []models.PostAuthor
+
+	if errorResponse := checkPostAccess(db.DB, postID, viewer); errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	role, err := authorRole(db.DB, postID, viewer.UserID)
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	query := db.Model(&models.PostAuthor{}).Select("post_authors.*, users.username, users.name").
+		Joins("JOIN users ON users.user_id = post_authors.user_id AND users.deleted_at IS NULL").
+		Where("post_authors.post_id=?", postID)
+	if role == "" && viewer.Role != constants.AdminRole {
+		query = query.Where("post_authors.status=?", constants.AuthorStatusAccepted)
+	}
+
+	data := query.Order("post_authors.role = 'owner' DESC, post_authors.created_at").Find(&authors)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &authors, nil
+}
+
+// invite a user to write the post, only the owner or the admin can invite
+func (db *authorRepository) InviteAuthor(author *models.PostAuthor, username string, viewer dto.Viewer) *dto.ErrorResponse {
+	var user models.User
+
+	if errorResponse := checkAuthorsManager(db.DB, author.PostID, viewer); errorResponse != nil {
+		return errorResponse
+	}
+
+	data := db.Where("username=?", username).First(&user)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "user not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	author.UserID = user.UserID
+	author.Username = user.Username
+	author.Name = user.Name
+	author.Status = constants.AuthorStatusPending
+
+	//inviting the same user twice is rejected instead of changing their role
+	data = db.Clauses(clause.OnConflict{DoNothing: true}).Create(author)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusConflict, Error: "user is already an author of the post or was invited"}
+	}
+
+	return nil
+}
+
+// remove an author or cancel an invitation, authors other than the owner can also remove themselves
+func (db *authorRepository) RemoveAuthor(postID uuid.UUID, username string, viewer dto.Viewer) *dto.ErrorResponse {
+	var author models.PostAuthor
+
+	data := db.Model(&models.PostAuthor{}).Select("post_authors.*").
+		Joins("JOIN users ON users.user_id = post_authors.user_id").
+		Where("post_authors.post_id=? AND users.username=?", postID, username).First(&author)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "author not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if author.Role == constants.AuthorRoleOwner {
+		return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "the owner of the post cannot be removed"}
+	}
+
+	if author.UserID != viewer.UserID {
+		if errorResponse := checkAuthorsManager(db.DB, postID, viewer); errorResponse != nil {
+			return errorResponse
+		}
+	}
+
+	data = db.Where("post_id=? AND user_id=?", postID, author.UserID).Delete(&models.PostAuthor{})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return nil
+}
+
+// retrieve the pending invitations of the user along with their posts
+func (db *authorRepository) GetInvitations(userID uuid.UUID, limit, offset int) (*[]models.PostAuthor, int64, *dto.ErrorResponse) {
+	var invitations []models.PostAuthor
+	var count int64
+
+	query := db.Model(&models.PostAuthor{}).
+		Joins("JOIN posts ON posts.post_id = post_authors.post_id AND posts.deleted_at IS NULL").
+		Where("post_authors.user_id=? AND post_authors.status=?", userID, constants.AuthorStatusPending)
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Select("post_authors.*").Preload("Post", func(db *gorm.DB) *gorm.DB {
+		return db.Select("post_id", "title", "slug", "status", "visibility", "user_id", "created_at")
+	}).Order("post_authors.created_at DESC").Limit(limit).Offset(offset).Find(&invitations)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &invitations, count, nil
+}
+
+// accept the invitation to write the post
+func (db *authorRepository) AcceptInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	data := db.Model(&models.PostAuthor{}).
+		Where("post_id=? AND user_id=? AND status=?", postID, userID, constants.AuthorStatusPending).
+		Updates(map[string]interface{}{"status": constants.AuthorStatusAccepted, "accepted_at": time.Now()})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "invitation not found"}
+	}
+
+	return nil
+}
+
+// decline the invitation to write the post
+func (db *authorRepository) DeclineInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	data := db.Where("post_id=? AND user_id=? AND status=?", postID, userID, constants.AuthorStatusPending).Delete(&models.PostAuthor{})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "invitation not found"}
+	}
+
+	return nil
+}
+
+// retrieve the role of the user on the post, empty when the user is not an accepted author
+func authorRole(db *gorm.DB, postID uuid.UUID, userID uuid.UUID) (string, error) {
+	var roles []string
+
+	if userID == uuid.Nil {
+		return "", nil
+	}
+
+	data := db.Model(&models.PostAuthor{}).
+		Where("post_id=? AND user_id=? AND status=?", postID, userID, constants.AuthorStatusAccepted).Limit(1).Pluck("role", &roles)
+	if data.Error != nil || len(roles) == 0 {
+		return "", data.Error
+	}
+
+	return roles[0], nil
+}
+
+// check if the post exists and the viewer can manage its authors
+func checkAuthorsManager(db *gorm.DB, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	data := db.Where("post_id=?", postID).First(&models.Post{})
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if viewer.Role == constants.AdminRole {
+		return nil
+	}
+
+	role, err := authorRole(db, postID, viewer.UserID)
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if role != constants.AuthorRoleOwner {
+		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "only the owner of the post can manage its authors"}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookmarkRepository interface {
+	CreateBookmark(bookmark *models.Bookmark, collection string, viewer dto.Viewer) *dto.ErrorResponse
+	GetBookmarks(userID uuid.UUID, collectionID uuid.UUID, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Bookmark, int64, *dto.ErrorResponse)
+	DeleteBookmark(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+	GetCollections(userID uuid.UUID) (*[]models.BookmarkCollection, *dto.ErrorResponse)
+	DeleteCollection(userID uuid.UUID, collectionID uuid.UUID) *dto.ErrorResponse
+}
+
+type bookmarkRepository struct {
+	*gorm.DB
+}
+
+func InitBookmarkRepository(db *gorm.DB) BookmarkRepository {
+	return &bookmarkRepository{db}
+}
+
+// bookmark a post, or move the existing bookmark into the given collection
+func (db *bookmarkRepository) CreateBookmark(bookmark *models.Bookmark, collection string, viewer dto.Viewer) *dto.ErrorResponse {
+	//check if the post exists and the user can see it
+	data := db.Scopes(visiblePosts(viewer)).Where("post_id=?", bookmark.PostID).First(&models.Post{})
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		//create the collection the first time it is used
+		if name := strings.TrimSpace(collection); name != "" {
+			bookmarkCollection := models.BookmarkCollection{UserID: bookmark.UserID, Name: name}
+
+			data := tx.Where("user_id=? AND name=?", bookmark.UserID, name).FirstOrCreate(&bookmarkCollection)
+			if data.Error != nil {
+				return data.Error
+			}
+			bookmark.CollectionID = &bookmarkCollection.CollectionID
+		}
+
+		//bookmarking the same post again only changes its collection
+		data := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"collection_id"}),
+		}).Create(bookmark)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		//read back the stored bookmark as it may have existed already
+		return tx.Where("user_id=? AND post_id=?", bookmark.UserID, bookmark.PostID).First(bookmark).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return nil
+}
+
+// retrieve the bookmarks of the user along with their posts, newest first unless sorted by oldest
+func (db *bookmarkRepository) GetBookmarks(userID uuid.UUID, collectionID uuid.UUID, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Bookmark, int64, *dto.ErrorResponse) {
+	var bookmarks []models.Bookmark
+	var count int64
+
+	//the bookmarks of posts the user can no longer see are kept but left out, they come back once the post is visible again
+	query := db.Model(&models.Bookmark{}).
+		Joins("JOIN posts ON posts.post_id = bookmarks.post_id AND posts.deleted_at IS NULL").
+		Scopes(visiblePosts(viewer)).Where("bookmarks.user_id=?", userID)
+	if collectionID != uuid.Nil {
+		query = query.Where("bookmarks.collection_id=?", collectionID)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	order := "bookmarks.created_at DESC"
+	if sort == "oldest" {
+		order = "bookmarks.created_at"
+	}
+
+	data = query.Preload("Post").Preload("Post.Tags").Preload("Collection").
+		Order(order).Limit(limit).Offset(offset).Find(&bookmarks)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	for _, bookmark := range bookmarks {
+		if bookmark.Post == nil {
+			continue
+		}
+
+		if err := fillPostHTML(bookmark.Post); err != nil {
+			return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		lockPost(bookmark.Post, viewer)
+		bookmark.Post.Bookmarked = true
+	}
+
+	return &bookmarks, count, nil
+}
+
+// remove the bookmark of the post
+func (db *bookmarkRepository) DeleteBookmark(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	data := db.Where("user_id=? AND post_id=?", userID, postID).Delete(&models.Bookmark{})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "bookmark not found"}
+	}
+
+	return nil
+}
+
+// retrieve the collections of the user along with the number of bookmarks in them
+func (db *bookmarkRepository) GetCollections(userID uuid.UUID) (*[]models.BookmarkCollection, *dto.ErrorResponse) {
+	var collections []models.BookmarkCollection
+
+	data := db.Model(&models.BookmarkCollection{}).
+		Select("bookmark_collections.*, COUNT(bookmarks.bookmark_id) AS bookmarks").
+		Joins("LEFT JOIN bookmarks ON bookmarks.collection_id = bookmark_collections.collection_id").
+		Where("bookmark_collections.user_id=?", userID).
+		Group("bookmark_collections.collection_id").Order("bookmark_collections.name").Find(&collections)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &collections, nil
+}
+
+// delete a collection, its bookmarks are kept without a collection
+func (db *bookmarkRepository) DeleteCollection(userID uuid.UUID, collectionID uuid.UUID) *dto.ErrorResponse {
+	var data *gorm.DB
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		data = tx.Where("user_id=? AND collection_id=?", userID, collectionID).Delete(&models.BookmarkCollection{})
+		if data.Error != nil || data.RowsAffected == 0 {
+			return data.Error
+		}
+
+		return tx.Model(&models.Bookmark{}).Where("collection_id=?", collectionID).Update("collection_id", nil).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "collection not found"}
+	}
+
+	return nil
+}
+
+// mark the posts the viewer bookmarked
+func fillPostsBookmarked(db *gorm.DB, posts []models.Post, viewer dto.Viewer) error {
+	var bookmarked []uuid.UUID
+
+	if viewer.UserID == uuid.Nil || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	data := db.Model(&models.Bookmark{}).Where("user_id=? AND post_id IN ?", viewer.UserID, postIDs).Pluck("post_id", &bookmarked)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	saved := make(map[uuid.UUID]bool, len(bookmarked))
+	for _, postID := range bookmarked {
+		saved[postID] = true
+	}
+
+	for i := range posts {
+		posts[i].Bookmarked = saved[posts[i].PostID]
+	}
+
+	return nil
+}
+
+// mark the post if the viewer bookmarked it
+func fillPostBookmarked(db *gorm.DB, post *models.Post, viewer dto.Viewer) error {
+	var count int64
+
+	if viewer.UserID == uuid.Nil {
+		return nil
+	}
+
+	data := db.Model(&models.Bookmark{}).Where("user_id=? AND post_id=?", viewer.UserID, post.PostID).Count(&count)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	post.Bookmarked = count > 0
+	return nil
+}
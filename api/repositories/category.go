@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
 	"errors"
@@ -13,6 +14,7 @@ import (
 type CategoryRepository interface {
 	CreateCategory(category *models.Category) *dto.ErrorResponse
 	GetCategories(limit, offset int) (*[]models.Category, *dto.ErrorResponse, int64)
+	GetCategoryBySlug(slug string) (*models.Category, bool, *dto.ErrorResponse)
 	UpdateCategory(category *models.Category, categoryID uuid.UUID) *dto.ErrorResponse
 	DeleteCategory(categoryID uuid.UUID) *dto.ErrorResponse
 }
@@ -33,10 +35,13 @@ func (db *categoryRepository) CreateCategory(category *models.Category) *dto.Err
 		return &dto.ErrorResponse{Status: http.StatusConflict, Error: "category already exists"}
 	}
 
-	//create the category
-	data = db.Create(&category)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//create the category with a unique slug generated from the category name
+	err := saveWithSlug(db.DB, constants.SlugEntityCategory, category.CategoryName, uuid.Nil, func(tx *gorm.DB, slug string) error {
+		category.Slug = slug
+		return tx.Create(&category).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	}
 
 	return nil
@@ -55,6 +60,35 @@ func (db *categoryRepository) GetCategories(limit, offset int) (*[]models.Catego
 	return &categories, nil, count
 }
 
+// retrieve a category using its current or old slug, moved is true when an old slug was used
+func (db *categoryRepository) GetCategoryBySlug(slug string) (*models.Category, bool, *dto.ErrorResponse) {
+	var category models.Category
+
+	data := db.Where("slug=?", slug).First(&category)
+	if data.Error == nil {
+		return &category, false, nil
+	} else if !errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//check if the slug belonged to a renamed category
+	history, err := findSlugHistory(db.DB, constants.SlugEntityCategory, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "category not found"}
+	} else if err != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	data = db.Where("category_id=?", history.EntityID).First(&category)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "category not found"}
+	} else if data.Error != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &category, true, nil
+}
+
 // update an existing category
 func (db *categoryRepository) UpdateCategory(category *models.Category, categoryID uuid.UUID) *dto.ErrorResponse {
 	var categoryData models.Category
@@ -65,10 +99,33 @@ func (db *categoryRepository) UpdateCategory(category *models.Category, category
 		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "category not found"}
 	}
 
-	//updates the category if it is the admin
-	data = db.Where("category_id=?", categoryID).Updates(&category)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//slugs are only generated from the category name
+	category.Slug = ""
+	err := db.Transaction(func(tx *gorm.DB) error {
+		//regenerate the slug when the name changes and keep the old one for redirects
+		if category.CategoryName != "" && category.CategoryName != categoryData.CategoryName {
+			err := saveWithSlug(tx, constants.SlugEntityCategory, category.CategoryName, categoryID, func(tx *gorm.DB, slug string) error {
+				if slug == categoryData.Slug {
+					return nil
+				}
+
+				if err := recordSlugHistory(tx, constants.SlugEntityCategory, categoryID, categoryData.Slug, slug); err != nil {
+					return err
+				}
+				category.Slug = slug
+				return tx.Model(&models.Category{}).Where("category_id=?", categoryID).UpdateColumn("slug", slug).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		//updates the category if it is the admin
+		data = tx.Where("category_id=?", categoryID).Updates(&category)
+		return data.Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	} else if data.RowsAffected == 0 {
 		return &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
 	}
@@ -3,18 +3,22 @@ package repositories
 import (
 	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CommentRepository interface {
-	CreateComment(comment *models.Comment) *dto.ErrorResponse
-	GetComments(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Comment, *dto.ErrorResponse, int64)
-	UpdateComment(comment *models.Comment, commentID uuid.UUID) *dto.ErrorResponse
+	CreateComment(comment *models.Comment, viewer dto.Viewer, check SpamCheck) *dto.ErrorResponse
+	GetComments(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Comment, *dto.ErrorResponse, int64)
+	GetThread(commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse)
+	UpdateComment(comment *models.Comment, commentID uuid.UUID, viewer dto.Viewer, check SpamCheck) *dto.ErrorResponse
 	DeleteComment(userID uuid.UUID, commentID uuid.UUID, role string) *dto.ErrorResponse
 }
 
@@ -26,97 +30,320 @@ func InitCommentRepository(db *gorm.DB) CommentRepository {
 	return &commentRepository{db}
 }
 
-// create a new comment
-func (db *commentRepository) CreateComment(comment *models.Comment) *dto.ErrorResponse {
-	//check if the post exists
-	data := db.Where("post_id=?", comment.PostID).First(&models.Post{})
-	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
-		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post does not exist"}
-	} else if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+// create a new comment, or an answer to another comment when it has a parent, the comment waits for moderation
+// when the rules ask for it
+func (db *commentRepository) CreateComment(comment *models.Comment, viewer dto.Viewer, check SpamCheck) *dto.ErrorResponse {
+	//suspended users cannot comment
+	if errorResponse := checkSuspended(db.DB, comment.UserID); errorResponse != nil {
+		return errorResponse
 	}
 
-	//create the comment
-	data = db.Create(&comment)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//only the comments the user can see can be answered, the deleted comments kept for their answers cannot
+	if comment.ParentID != nil {
+		data := db.Scopes(visibleComments(viewer)).Where("comment_id=? AND NOT deleted", *comment.ParentID).First(&models.Comment{})
+		if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+			return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+		} else if data.Error != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+	}
+
+	//place the comment in the thread of its parent
+	if errorResponse := placeComment(db.DB, comment); errorResponse != nil {
+		return errorResponse
+	}
+
+	//check if the post exists and the user can read it
+	if errorResponse := checkPostAccess(db.DB, comment.PostID, viewer); errorResponse != nil {
+		return errorResponse
+	}
+
+	//store the rendered html along with the source, the comment has not been edited yet
+	if err := renderComment(comment); err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	comment.EditedAt = nil
+	comment.EditCount = 0
+
+	//the comments on locked posts can still be read, only the moderators can add to them
+	post, errorResponse := commentablePost(db.DB, comment.PostID, viewer)
+	if errorResponse != nil {
+		return errorResponse
+	}
+
+	//hold the comment for moderation using the spam checks and the rules of the category
+	verdict := check()
+	if err := holdComment(db.DB, comment, post, viewer, verdict); err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	//create the comment along with its spam check and let the authors of the post know when it waits for moderation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+
+		if err := saveSpamCheck(tx, verdict, constants.ReactionTargetComment, &comment.CommentID, comment.UserID); err != nil {
+			return err
+		}
+
+		if comment.Status == constants.CommentStatusPending {
+			return notifyPendingComment(tx, comment, post)
+		}
+		return nil
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	}
 
 	return nil
 }
 
-// retrieve comments using post id
-func (db *commentRepository) GetComments(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Comment, *dto.ErrorResponse, int64) {
+// retrieve the threads of a post along with their answers, the comments found by a search are not nested
+func (db *commentRepository) GetComments(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Comment, *dto.ErrorResponse, int64) {
 	var comment []models.Comment
 	var count int64
 	search := keywords["search"].(string)
+	sort := keywords["sort"].(string)
 	limit := keywords["limit"].(int)
 	offset := keywords["offset"].(int)
 
-	//check if the post exists
-	data := db.Where("post_id=?", postID).First(&models.Post{})
-	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
-		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}, 0
-	} else if data.Error != nil {
-		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}, 0
+	//check if the post exists and the user can read it
+	if errorResponse := checkPostAccess(db.DB, postID, viewer); errorResponse != nil {
+		return nil, errorResponse, 0
+	}
+
+	//get the comments using content, or the comments starting the threads
+	query := db.Model(&models.Comment{}).Scopes(visibleComments(viewer)).Where("post_id=?", postID)
+	if search != "" {
+		query = query.Where("content ILIKE '%' || ? || '%'", search)
+	} else {
+		query = query.Where("parent_id IS NULL")
 	}
 
 	//retrieve the comments
-	data = db.Model(comment).Preload("Replies").Where("post_id=?", postID).Limit(limit).Offset(offset).Count(&count).Find(&comment)
+	data := query.Count(&count).Order(threadOrder(sort)).Limit(limit).Offset(offset).Find(&comment)
 	if data.Error != nil {
 		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}, 0
 	}
 
-	//get the comments using content
 	if search != "" {
-		db.Where("content LIKE '%' || ? || '%'", search)
+		if err := fillCommentsHTML(comment); err != nil {
+			return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}, 0
+		}
+
+		if err := fillCommentsReactions(db.DB, comment); err != nil {
+			return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}, 0
+		}
+
+		return &comment, nil, count
+	}
+
+	//nest the answers under the threads
+	if err := loadThreads(db.DB, comment, sort, viewer, false); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}, 0
 	}
 
 	return &comment, nil, count
 }
 
-// updates the existing comment
-func (db *commentRepository) UpdateComment(comment *models.Comment, commentID uuid.UUID) *dto.ErrorResponse {
+// retrieve a comment along with every answer nested under it
+func (db *commentRepository) GetThread(commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse) {
+	var comment models.Comment
+
+	data := db.Scopes(visibleComments(viewer)).Where("comment_id=?", commentID).First(&comment)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//check if the user can read the post of the comment
+	if errorResponse := checkPostAccess(db.DB, comment.PostID, viewer); errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	thread := []models.Comment{comment}
+	if err := loadThreads(db.DB, thread, sort, viewer, false); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &thread[0], nil
+}
+
+// updates the existing comment, the previous version is kept in its history
+func (db *commentRepository) UpdateComment(comment *models.Comment, commentID uuid.UUID, viewer dto.Viewer, check SpamCheck) *dto.ErrorResponse {
 	var commentData models.Comment
 
-	//check if the record exists and if the user can access it
-	data := db.Where("comment_id=?", commentID).First(&commentData)
+	//check if the record exists and if the user can access it, the deleted comments kept for their answers cannot be edited
+	data := db.Where("comment_id=? AND NOT deleted", commentID).First(&commentData)
 	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
 		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
 	} else if commentData.UserID != comment.UserID {
 		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot update other users comment"}
 	}
 
-	//updates the comment if it is the user created it
-	data = db.Preload("Reply").Where("comment_id=?", commentID).Updates(&comment)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
-	} else if data.RowsAffected == 0 {
+	//the comments can only be edited within the edit window
+	if window := commentEditWindow(); window > 0 && time.Since(commentData.CreatedAt) > window {
+		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "comment can no longer be edited"}
+	}
+
+	if comment.Content == "" || comment.Content == commentData.Content {
 		return &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
 	}
 
+	//render the html again as the content changes
+	if err := renderComment(comment); err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	//the comments on locked posts cannot be edited either, only the moderators can change them
+	comment.PostID = commentData.PostID
+	post, errorResponse := commentablePost(db.DB, comment.PostID, viewer)
+	if errorResponse != nil {
+		return errorResponse
+	}
+
+	//the spam checks and the hold rules check the new content again, an approved comment goes back to moderation when they hold it
+	verdict := check()
+	if err := holdComment(db.DB, comment, post, viewer, verdict); err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	held := commentData.Status == constants.CommentStatusApproved && comment.Status != constants.CommentStatusApproved
+	if !held {
+		comment.Status = commentData.Status
+		comment.HoldReason = commentData.HoldReason
+	}
+
+	//keep the previous version before updating the comment, the comment stays in its thread
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var previous models.Comment
+
+		//lock the comment so the edits made at the same time get their own versions
+		data := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("comment_id=?", commentID).First(&previous)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		writtenAt := previous.CreatedAt
+		if previous.EditedAt != nil {
+			writtenAt = *previous.EditedAt
+		}
+
+		revision := models.CommentRevision{CommentID: commentID, Version: previous.EditCount + 1, Content: previous.Content,
+			ContentHTML: previous.ContentHTML, WrittenAt: writtenAt}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		comment.EditedAt = &now
+		comment.EditCount = revision.Version
+		data = tx.Model(&models.Comment{}).Where("comment_id=?", commentID).Updates(map[string]interface{}{"content": comment.Content,
+			"content_html": comment.ContentHTML, "status": comment.Status, "hold_reason": comment.HoldReason, "edited_at": now,
+			"edit_count": revision.Version})
+		if data.Error != nil {
+			return data.Error
+		}
+
+		if err := saveSpamCheck(tx, verdict, constants.ReactionTargetComment, &commentID, comment.UserID); err != nil {
+			return err
+		}
+
+		//let the authors of the post know the edited comment waits for moderation
+		if held && comment.Status == constants.CommentStatusPending {
+			comment.CommentID = commentID
+			return notifyPendingComment(tx, comment, post)
+		}
+		return nil
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
 	return nil
 }
 
-// deletes the existing comment
+// time after its creation a comment can be edited, no window lets the comments be edited forever
+func commentEditWindow() time.Duration {
+	return time.Duration(helpers.EnvInt64("COMMENT_EDIT_WINDOW", constants.DefaultCommentEditWindow)) * time.Second
+}
+
+// deletes the existing comment, a comment with answers is emptied and kept so its answers stay in the thread
 func (db *commentRepository) DeleteComment(userID uuid.UUID, commentID uuid.UUID, role string) *dto.ErrorResponse {
 	var commentData models.Comment
 
 	//check if the record exists and if the user can access it
-	data := db.Where("comment_id=?", commentID).First(&commentData)
+	data := db.Where("comment_id=? AND NOT deleted", commentID).First(&commentData)
 	if data.Error != nil {
 		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: data.Error.Error()}
-	} else if commentData.UserID != userID && role != constants.AdminRole {
+	} else if commentData.UserID != userID && role != constants.AdminRole && role != constants.ModeratorRole {
 		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot delete other users comment"}
 	}
 
-	//deletes the record if the user created it or if it is the admin
-	data = db.Where("comment_id=?", commentID).Delete(&commentData)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//deletes the record if the user created it or if it is the admin or a moderator
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var answers int64
+
+		if err := tx.Model(&models.Comment{}).Where("parent_id=?", commentID).Count(&answers).Error; err != nil {
+			return err
+		}
+
+		if answers > 0 {
+			data = tx.Model(&models.Comment{}).Where("comment_id=?", commentID).Updates(map[string]interface{}{"content": "", "content_html": "",
+				"deleted": true})
+		} else {
+			data = tx.Where("comment_id=?", commentID).Delete(&models.Comment{})
+		}
+		if data.Error != nil {
+			return data.Error
+		}
+
+		//the notifications and the reactions about the comment go away along with it
+		if err := tx.Where("comment_id=?", commentID).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := deleteReactions(tx, constants.ReactionTargetComment, commentID); err != nil {
+			return err
+		}
+
+		if answers > 0 {
+			return nil
+		}
+		return deleteEmptiedParents(tx, commentData.ParentID)
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	} else if data.RowsAffected == 0 {
 		return &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
 	}
 	
 	return nil
 }
+
+// delete the emptied comments which were only kept for their answers once their last answer is deleted
+func deleteEmptiedParents(tx *gorm.DB, parentID *uuid.UUID) error {
+	for parentID != nil {
+		var parent models.Comment
+		var answers int64
+
+		data := tx.Where("comment_id=? AND deleted", *parentID).Limit(1).Find(&parent)
+		if data.Error != nil || data.RowsAffected == 0 {
+			return data.Error
+		}
+
+		if err := tx.Model(&models.Comment{}).Where("parent_id=?", parent.CommentID).Count(&answers).Error; err != nil {
+			return err
+		} else if answers > 0 {
+			return nil
+		}
+
+		if err := tx.Where("comment_id=?", parent.CommentID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
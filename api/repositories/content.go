@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/pkg/markdown"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+)
+
+// render the post content into sanitized html using the format of the post
+func renderPost(post *models.Post) error {
+	if post.ContentFormat == "" {
+		post.ContentFormat = constants.ContentFormatMarkdown
+	}
+
+	html, err := markdown.Render(post.Content, post.ContentFormat)
+	if err != nil {
+		return err
+	}
+
+	post.ContentHTML = html
+	return nil
+}
+
+// render the comment content into sanitized html
+func renderComment(comment *models.Comment) error {
+	html, err := markdown.Render(comment.Content, constants.ContentFormatMarkdown)
+	if err != nil {
+		return err
+	}
+
+	comment.ContentHTML = html
+	return nil
+}
+
+// render the posts and comments stored before the html was saved along with them
+func fillPostsHTML(posts []models.Post) error {
+	for i := range posts {
+		if err := fillPostHTML(&posts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render the post and its comments if they were stored before the html was saved along with them
+func fillPostHTML(post *models.Post) error {
+	if post.ContentHTML == "" && post.Content != "" {
+		if err := renderPost(post); err != nil {
+			return err
+		}
+	}
+
+	return fillCommentsHTML(post.Comments)
+}
+
+// render the comments stored before the html was saved along with them
+func fillCommentsHTML(comments []models.Comment) error {
+	for i := range comments {
+		if comments[i].ContentHTML == "" && comments[i].Content != "" {
+			if err := renderComment(&comments[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,716 @@
+package repositories
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/importer"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// number of item outcomes saved at once while the job runs
+const importFlushSize = 100
+
+// maximum length of the usernames given to imported users, leaving room for a numeric suffix
+const importUsernameLength = 15
+
+var (
+	//jobs running in this process, the jobs left running by a stopped process can be resumed
+	runningImports   = make(map[uuid.UUID]bool)
+	runningImportsMu sync.Mutex
+
+	//returned to roll back the dry runs
+	errImportDryRun = errors.New("dry run")
+)
+
+type ImportRepository interface {
+	StartImport(job *models.ImportJob) (bool, *dto.ErrorResponse)
+	RunImport(job *models.ImportJob, document *importer.Document) error
+	GetImports(limit, offset int) (*[]models.ImportJob, int64, *dto.ErrorResponse)
+	GetImport(jobID uuid.UUID) (*models.ImportJob, *dto.ErrorResponse)
+	GetImportItems(jobID uuid.UUID, action string, limit, offset int) (*[]models.ImportItem, int64, *dto.ErrorResponse)
+}
+
+type importRepository struct {
+	*gorm.DB
+}
+
+func InitImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{db}
+}
+
+// start the job, the unfinished job of the same file is resumed instead and the finished one is returned as it is
+func (db *importRepository) StartImport(job *models.ImportJob) (bool, *dto.ErrorResponse) {
+	var previous models.ImportJob
+
+	//dry runs never resume, they always report every item
+	if !job.DryRun {
+		data := db.Where("source=? AND checksum=? AND dry_run=?", job.Source, job.Checksum, false).Order("created_at DESC").Limit(1).Find(&previous)
+		if data.Error != nil {
+			return false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+
+		if data.RowsAffected > 0 {
+			if previous.Status == constants.ImportStatusCompleted {
+				*job = previous
+				return true, nil
+			}
+
+			if !claimImport(previous.JobID) {
+				return false, &dto.ErrorResponse{Status: http.StatusConflict, Error: "the file is already being imported"}
+			}
+
+			data = db.Model(&previous).Updates(map[string]interface{}{"status": constants.ImportStatusRunning, "error": "", "finished_at": nil})
+			if data.Error != nil {
+				releaseImport(previous.JobID)
+				return false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+			}
+
+			previous.Status, previous.Error, previous.FinishedAt = constants.ImportStatusRunning, "", nil
+			*job = previous
+			return false, nil
+		}
+	}
+
+	job.Status = constants.ImportStatusRunning
+	data := db.Create(job)
+	if data.Error != nil {
+		return false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+	claimImport(job.JobID)
+
+	return false, nil
+}
+
+// import every item of the document which was not imported yet, a dry run reports the items without keeping them
+func (db *importRepository) RunImport(job *models.ImportJob, document *importer.Document) error {
+	defer releaseImport(job.JobID)
+
+	run := &importRun{
+		root:       db.DB,
+		job:        job,
+		source:     job.Source + ":" + document.Site,
+		users:      make(map[string]uuid.UUID),
+		commenters: make(map[string]uuid.UUID),
+		categories: make(map[string]uuid.UUID),
+		pending:    make(map[string]int),
+	}
+
+	var err error
+	if job.DryRun {
+		//every item still runs in its own savepoint, so the report matches a real import
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := run.importDocument(tx, document); err != nil {
+				return err
+			}
+			return errImportDryRun
+		})
+		if errors.Is(err, errImportDryRun) {
+			err = nil
+		}
+	} else {
+		err = run.importDocument(db.DB, document)
+	}
+
+	if flushErr := run.flush(); err == nil {
+		err = flushErr
+	}
+
+	//the failed items are tried again when the same file is imported again
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = constants.ImportStatusCompleted
+	if err != nil {
+		job.Status, job.Error = constants.ImportStatusFailed, err.Error()
+	} else if job.Failed > 0 {
+		job.Status = constants.ImportStatusFailed
+	}
+
+	data := db.Model(job).Select("status", "error", "finished_at").Updates(job)
+	if data.Error != nil && err == nil {
+		err = data.Error
+	}
+
+	if !job.DryRun && run.posts > 0 {
+		invalidateRelatedPosts()
+	}
+
+	return err
+}
+
+// retrieve the import jobs, the latest first
+func (db *importRepository) GetImports(limit, offset int) (*[]models.ImportJob, int64, *dto.ErrorResponse) {
+	var jobs []models.ImportJob
+	var count int64
+
+	data := db.Model(&models.ImportJob{}).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &jobs, count, nil
+}
+
+// retrieve an import job along with its counts
+func (db *importRepository) GetImport(jobID uuid.UUID) (*models.ImportJob, *dto.ErrorResponse) {
+	var job models.ImportJob
+
+	data := db.Where("job_id=?", jobID).First(&job)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "import not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &job, nil
+}
+
+// retrieve the outcome of the items of an import job, optionally only those with the given action
+func (db *importRepository) GetImportItems(jobID uuid.UUID, action string, limit, offset int) (*[]models.ImportItem, int64, *dto.ErrorResponse) {
+	var items []models.ImportItem
+	var count int64
+
+	if _, errorResponse := db.GetImport(jobID); errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	query := db.Model(&models.ImportItem{}).Where("job_id=?", jobID)
+	if action != "" {
+		query = query.Where("action=?", action)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Order("kind, source_id").Limit(limit).Offset(offset).Find(&items)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &items, count, nil
+}
+
+// mark the job as running in this process, false when it already runs
+func claimImport(jobID uuid.UUID) bool {
+	runningImportsMu.Lock()
+	defer runningImportsMu.Unlock()
+
+	if runningImports[jobID] {
+		return false
+	}
+	runningImports[jobID] = true
+
+	return true
+}
+
+// mark the job as no longer running in this process
+func releaseImport(jobID uuid.UUID) {
+	runningImportsMu.Lock()
+	delete(runningImports, jobID)
+	runningImportsMu.Unlock()
+}
+
+// state of a running import job
+type importRun struct {
+	//saves the outcome of the items outside of the transaction of a dry run
+	root *gorm.DB
+	job  *models.ImportJob
+	//identifies the exported site in the import records
+	source string
+	//imported rows by the login of the authors, the commenters and the name and slug of the categories
+	users      map[string]uuid.UUID
+	commenters map[string]uuid.UUID
+	categories map[string]uuid.UUID
+	items      []models.ImportItem
+	//position of the pending items, an item reported twice before a flush keeps its last outcome
+	pending map[string]int
+	posts   int
+}
+
+// import the authors and categories first, then every post along with its comments
+func (run *importRun) importDocument(db *gorm.DB, document *importer.Document) error {
+	for _, issue := range document.Skipped {
+		run.report(constants.ImportKindPost, issue.SourceID, issue.Title, constants.ImportActionSkipped, nil, issue.Reason)
+	}
+	for _, issue := range document.Errors {
+		run.report(constants.ImportKindPost, issue.SourceID, issue.Title, constants.ImportActionFailed, nil, issue.Reason)
+	}
+
+	for _, author := range document.Authors {
+		run.importAuthor(db, author)
+	}
+
+	for _, category := range document.Categories {
+		run.importCategory(db, category)
+	}
+
+	for i := range document.Posts {
+		post := &document.Posts[i]
+		if postID, ok := run.importPost(db, post); ok {
+			run.importComments(db, post, postID)
+		}
+
+		if len(run.items) >= importFlushSize {
+			if err := run.flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// import an item once, the row is created along with its import record in a single transaction
+//
+// create returns the id of the row the item is imported as, along with a reason when it is an existing row
+func (run *importRun) importItem(db *gorm.DB, kind, sourceID, title string, create func(tx *gorm.DB) (uuid.UUID, string, error)) (uuid.UUID, bool) {
+	var record models.ImportRecord
+
+	data := db.Where("source=? AND kind=? AND source_id=?", run.source, kind, sourceID).Limit(1).Find(&record)
+	if data.Error != nil {
+		run.report(kind, sourceID, title, constants.ImportActionFailed, nil, data.Error.Error())
+		return uuid.Nil, false
+	}
+
+	//items imported by the same job were already reported when the job ran before
+	if data.RowsAffected > 0 {
+		if record.JobID != run.job.JobID {
+			run.report(kind, sourceID, title, constants.ImportActionSkipped, &record.TargetID, "already imported")
+		}
+		return record.TargetID, true
+	}
+
+	var targetID uuid.UUID
+	var reason string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if targetID, reason, err = create(tx); err != nil {
+			return err
+		}
+
+		return tx.Create(&models.ImportRecord{Source: run.source, Kind: kind, SourceID: sourceID, TargetID: targetID, JobID: run.job.JobID}).Error
+	})
+	if err != nil {
+		run.report(kind, sourceID, title, constants.ImportActionFailed, nil, err.Error())
+		return uuid.Nil, false
+	}
+
+	if reason != "" {
+		run.report(kind, sourceID, title, constants.ImportActionSkipped, &targetID, reason)
+	} else {
+		run.report(kind, sourceID, title, constants.ImportActionCreated, &targetID, "")
+	}
+
+	return targetID, true
+}
+
+// map an author onto the user with the same email, or the same username when the export has no emails
+func (run *importRun) importAuthor(db *gorm.DB, author importer.Author) {
+	login := cmp.Or(author.Login, author.Email)
+	if login == "" {
+		return
+	}
+
+	userID, ok := run.importItem(db, constants.ImportKindUser, login, cmp.Or(author.Name, login), func(tx *gorm.DB) (uuid.UUID, string, error) {
+		var user models.User
+
+		query := tx.Where("username=?", login)
+		if author.Email != "" {
+			query = tx.Where("email=?", author.Email)
+		}
+
+		data := query.Limit(1).Find(&user)
+		if data.Error != nil {
+			return uuid.Nil, "", data.Error
+		} else if data.RowsAffected > 0 {
+			return user.UserID, "user already exists", nil
+		}
+
+		return createImportUser(tx, login, author.Email, author.Name)
+	})
+	if ok {
+		run.users[login] = userID
+	}
+}
+
+// find the user who wrote a comment, commenters always get their own user because the emails of the comments were
+// never verified, they are neither matched with the authors nor kept on the user
+func (run *importRun) importCommenter(db *gorm.DB, comment *importer.Comment) (uuid.UUID, bool) {
+	//the keys of the commenters never match the logins or emails the authors are imported with
+	key := "commenter:" + cmp.Or(strings.ToLower(comment.Email), "name:"+comment.Author)
+	if userID, ok := run.commenters[key]; ok {
+		return userID, true
+	}
+
+	userID, ok := run.importItem(db, constants.ImportKindUser, key, comment.Author, func(tx *gorm.DB) (uuid.UUID, string, error) {
+		return createImportUser(tx, comment.Author, "", comment.Author)
+	})
+	if ok {
+		run.commenters[key] = userID
+	}
+
+	return userID, ok
+}
+
+// map a category onto the category with the same slug or name
+func (run *importRun) importCategory(db *gorm.DB, category importer.Category) (uuid.UUID, bool) {
+	name := cmp.Or(category.Name, category.Slug)
+	slug := cmp.Or(category.Slug, helpers.Slugify(name))
+	if name == "" {
+		return uuid.Nil, false
+	}
+
+	categoryID, ok := run.importItem(db, constants.ImportKindCategory, slug, name, func(tx *gorm.DB) (uuid.UUID, string, error) {
+		var existing models.Category
+
+		data := tx.Where("slug=? OR LOWER(category_name)=LOWER(?)", slug, name).Limit(1).Find(&existing)
+		if data.Error != nil {
+			return uuid.Nil, "", data.Error
+		} else if data.RowsAffected > 0 {
+			return existing.CategoryID, "category already exists", nil
+		}
+
+		created := models.Category{CategoryName: name, Description: category.Description}
+		err := saveWithSlug(tx, constants.SlugEntityCategory, slug, uuid.Nil, func(tx *gorm.DB, slug string) error {
+			created.Slug = slug
+			return tx.Create(&created).Error
+		})
+		if err != nil {
+			return uuid.Nil, "", err
+		}
+
+		return created.CategoryID, "", nil
+	})
+	if ok {
+		run.categories[strings.ToLower(name)] = categoryID
+		run.categories[slug] = categoryID
+	}
+
+	return categoryID, ok
+}
+
+// find the category a post is imported into, creating the categories the export did not list
+func (run *importRun) category(db *gorm.DB, name string) (uuid.UUID, bool) {
+	if categoryID, ok := run.categories[strings.ToLower(name)]; ok {
+		return categoryID, true
+	}
+
+	return run.importCategory(db, importer.Category{Name: name})
+}
+
+// import a post into its first category, posts without an author known to the site belong to the user importing them
+func (run *importRun) importPost(db *gorm.DB, post *importer.Post) (uuid.UUID, bool) {
+	//the category is imported on its own, so it is kept even when the post fails
+	categoryName := constants.ImportDefaultCategory
+	if len(post.Categories) > 0 {
+		categoryName = post.Categories[0]
+	}
+	categoryID, categoryOK := run.category(db, categoryName)
+
+	userID, ok := run.users[post.Author]
+	if !ok {
+		userID = run.job.UserID
+	}
+
+	postID, ok := run.importItem(db, constants.ImportKindPost, post.SourceID, post.Title, func(tx *gorm.DB) (uuid.UUID, string, error) {
+		if post.Title == "" {
+			return uuid.Nil, "", errors.New("title is required")
+		} else if !categoryOK {
+			return uuid.Nil, "", fmt.Errorf("category %s could not be imported", categoryName)
+		}
+
+		created := models.Post{
+			Title:         post.Title,
+			Content:       post.Content,
+			ContentFormat: post.ContentFormat,
+			Description:   post.Description,
+			Status:        post.Status,
+			Visibility:    post.Visibility,
+			UserID:        userID,
+			CategoryID:    categoryID,
+			CreatedAt:     post.CreatedAt,
+			UpdatedAt:     cmp.Or(post.UpdatedAt, post.CreatedAt),
+		}
+
+		if post.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(post.Password), 10)
+			if err != nil {
+				return uuid.Nil, "", err
+			}
+			created.Visibility, created.PasswordHash = constants.VisibilityPassword, string(hash)
+		}
+
+		if err := renderPost(&created); err != nil {
+			return uuid.Nil, "", err
+		}
+
+		err := saveWithSlug(tx, constants.SlugEntityPost, cmp.Or(post.Slug, post.Title), uuid.Nil, func(tx *gorm.DB, slug string) error {
+			created.Slug = slug
+			return tx.Create(&created).Error
+		})
+		if err != nil {
+			return uuid.Nil, "", err
+		}
+
+		owner := models.PostAuthor{PostID: created.PostID, UserID: userID, Role: constants.AuthorRoleOwner, Status: constants.AuthorStatusAccepted, AcceptedAt: &created.CreatedAt}
+		if err := tx.Create(&owner).Error; err != nil {
+			return uuid.Nil, "", err
+		}
+
+		tags := post.Tags
+		if len(tags) > constants.MaxTagsPerPost {
+			tags = tags[:constants.MaxTagsPerPost]
+		}
+
+		if err := assignTags(tx, &created, tags); err != nil {
+			return uuid.Nil, "", err
+		}
+
+		return created.PostID, "", nil
+	})
+	if ok {
+		run.posts++
+	}
+
+	return postID, ok
+}
+
+// import the approved comments of a post, the answers to comments are nested under the comment they answer
+func (run *importRun) importComments(db *gorm.DB, post *importer.Post, postID uuid.UUID) {
+	comments := make(map[string]*importer.Comment, len(post.Comments))
+	for i := range post.Comments {
+		comments[post.Comments[i].SourceID] = &post.Comments[i]
+	}
+
+	//the comments are imported before their answers
+	ordered := make([]*importer.Comment, 0, len(post.Comments))
+	for i := range post.Comments {
+		ordered = append(ordered, &post.Comments[i])
+	}
+	slices.SortStableFunc(ordered, func(a, b *importer.Comment) int {
+		return threadDepth(comments, a) - threadDepth(comments, b)
+	})
+
+	for _, comment := range ordered {
+		sourceID := post.SourceID + "/" + comment.SourceID
+
+		if !comment.Approved {
+			run.report(constants.ImportKindComment, sourceID, comment.Author, constants.ImportActionSkipped, nil, "comment is not approved")
+			continue
+		}
+
+		userID, ok := run.importCommenter(db, comment)
+		if !ok {
+			run.report(constants.ImportKindComment, sourceID, comment.Author, constants.ImportActionFailed, nil, "the author of the comment could not be imported")
+			continue
+		}
+
+		run.importItem(db, constants.ImportKindComment, sourceID, comment.Author, func(tx *gorm.DB) (uuid.UUID, string, error) {
+			created := models.Comment{Content: comment.Content, UserID: userID, PostID: postID, CreatedAt: comment.CreatedAt, UpdatedAt: comment.CreatedAt}
+
+			if comment.ParentID != "" {
+				var record models.ImportRecord
+
+				data := tx.Where("source=? AND kind=? AND source_id=?", run.source, constants.ImportKindComment, post.SourceID+"/"+comment.ParentID).Limit(1).Find(&record)
+				if data.Error != nil {
+					return uuid.Nil, "", data.Error
+				} else if data.RowsAffected == 0 {
+					return uuid.Nil, "", errors.New("the comment it answers was not imported")
+				}
+				created.ParentID = &record.TargetID
+			}
+
+			if errorResponse := placeComment(tx, &created); errorResponse != nil {
+				return uuid.Nil, "", errors.New(errorResponse.Error)
+			}
+
+			if err := renderComment(&created); err != nil {
+				return uuid.Nil, "", err
+			}
+
+			if err := tx.Create(&created).Error; err != nil {
+				return uuid.Nil, "", err
+			}
+
+			return created.CommentID, "", nil
+		})
+	}
+}
+
+// number of comments above the comment in its thread, a loop of parents stops at the first repeated comment
+func threadDepth(comments map[string]*importer.Comment, comment *importer.Comment) int {
+	seen := make(map[string]bool)
+	depth := 0
+
+	for comment.ParentID != "" && !seen[comment.SourceID] {
+		seen[comment.SourceID] = true
+
+		parent, ok := comments[comment.ParentID]
+		if !ok {
+			break
+		}
+		comment = parent
+		depth++
+	}
+
+	return depth
+}
+
+// keep the outcome of an item until the next flush
+func (run *importRun) report(kind, sourceID, title, action string, targetID *uuid.UUID, reason string) {
+	item := models.ImportItem{
+		JobID:    run.job.JobID,
+		Kind:     kind,
+		SourceID: sourceID,
+		Title:    helpers.Truncate(title, 200),
+		Action:   action,
+		TargetID: targetID,
+		Reason:   reason,
+	}
+
+	key := kind + "\x00" + sourceID
+	if index, ok := run.pending[key]; ok {
+		run.items[index] = item
+		return
+	}
+
+	run.pending[key] = len(run.items)
+	run.items = append(run.items, item)
+}
+
+// save the outcome of the reported items and update the counts of the job
+func (run *importRun) flush() error {
+	var counts []struct {
+		Action string
+		Count  int64
+	}
+
+	if len(run.items) > 0 {
+		data := run.root.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "job_id"}, {Name: "kind"}, {Name: "source_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "action", "target_id", "reason"}),
+		}).CreateInBatches(run.items, importFlushSize)
+		if data.Error != nil {
+			return data.Error
+		}
+		run.items = run.items[:0]
+		clear(run.pending)
+	}
+
+	data := run.root.Model(&models.ImportItem{}).Select("action, COUNT(*) AS count").Where("job_id=?", run.job.JobID).Group("action").Scan(&counts)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	run.job.Created, run.job.Skipped, run.job.Failed = 0, 0, 0
+	for _, count := range counts {
+		switch count.Action {
+		case constants.ImportActionCreated:
+			run.job.Created = count.Count
+		case constants.ImportActionSkipped:
+			run.job.Skipped = count.Count
+		case constants.ImportActionFailed:
+			run.job.Failed = count.Count
+		}
+	}
+
+	return run.root.Model(run.job).Select("created", "skipped", "failed").Updates(run.job).Error
+}
+
+// create a user for an imported author or commenter, the email is left out when another user already uses it
+//
+// the imported users get a random password which nobody knows
+func createImportUser(tx *gorm.DB, login, email, name string) (uuid.UUID, string, error) {
+	var count int64
+
+	username, err := importUsername(tx, cmp.Or(login, name, email))
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	if email != "" {
+		if err := tx.Unscoped().Model(&models.User{}).Where("email=?", email).Count(&count).Error; err != nil {
+			return uuid.Nil, "", err
+		}
+	}
+	if email == "" || count > 0 {
+		email = username + "@" + constants.ImportEmailDomain
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return uuid.Nil, "", err
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), 10)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	user := models.User{Email: email, Name: cmp.Or(name, username), Username: username, Password: string(password), Role: "user"}
+	if err := tx.Create(&user).Error; err != nil {
+		return uuid.Nil, "", err
+	}
+
+	return user.UserID, "", nil
+}
+
+// generate a username which no other user has, including the deleted users
+func importUsername(tx *gorm.DB, text string) (string, error) {
+	var err error
+
+	base := []rune(helpers.Slugify(text))
+	if len(base) > importUsernameLength {
+		base = base[:importUsernameLength]
+	}
+
+	username := strings.Trim(string(base), "-")
+	if len([]rune(username)) < 4 {
+		username = strings.Trim("user-"+username, "-")
+	}
+
+	username = helpers.UniqueSlug(username, func(candidate string) bool {
+		var count int64
+
+		if err != nil {
+			return false
+		}
+
+		data := tx.Unscoped().Model(&models.User{}).Where("username=?", candidate).Count(&count)
+		if data.Error != nil {
+			err = data.Error
+			return false
+		}
+
+		return count > 0
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return username, nil
+}
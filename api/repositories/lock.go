@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// lock or unlock the comments on a post, the owner, the co-authors and the moderators can do it
+func (db *postRepository) LockComments(postID uuid.UUID, locked bool, viewer dto.Viewer) *dto.ErrorResponse {
+	var post models.Post
+
+	data := db.Where("post_id=?", postID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if viewer.Role != constants.AdminRole && viewer.Role != constants.ModeratorRole {
+		role, err := authorRole(db.DB, postID, viewer.UserID)
+		if err != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		} else if role != constants.AuthorRoleOwner && role != constants.AuthorRoleCoAuthor {
+			return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot lock the comments on other users post"}
+		}
+	}
+
+	data = db.Model(&post).UpdateColumn("comments_locked", locked)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return nil
+}
+
+// check if the comments on the post are locked, either by its authors or a moderator, or because the post is older
+// than the lock days of its category
+func commentsLocked(db *gorm.DB, post *models.Post) (bool, error) {
+	var category models.Category
+
+	if post.CommentsLocked {
+		return true, nil
+	}
+
+	data := db.Select("comment_lock_days").Where("category_id=?", post.CategoryID).Limit(1).Find(&category)
+	if data.Error != nil {
+		return false, data.Error
+	} else if category.CommentLockDays == nil || *category.CommentLockDays <= 0 {
+		return false, nil
+	}
+
+	return time.Since(post.CreatedAt) > time.Duration(*category.CommentLockDays)*24*time.Hour, nil
+}
+
+// load the post of a new or edited comment, only the moderators can write on the posts with locked comments
+func commentablePost(db *gorm.DB, postID uuid.UUID, viewer dto.Viewer) (*models.Post, *dto.ErrorResponse) {
+	var post models.Post
+
+	data := db.Scopes(preloadAuthors).Where("post_id=?", postID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if viewer.Role != constants.AdminRole && viewer.Role != constants.ModeratorRole {
+		locked, err := commentsLocked(db, &post)
+		if err != nil {
+			return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		} else if locked {
+			return nil, &dto.ErrorResponse{Status: http.StatusLocked, Error: "comments are locked on this post"}
+		}
+	}
+
+	return &post, nil
+}
+
+// show the comments of the post as locked when its category locked them
+func fillCommentsLocked(db *gorm.DB, post *models.Post) error {
+	locked, err := commentsLocked(db, post)
+	post.CommentsLocked = locked
+
+	return err
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// returned when a post refers to media which does not exist or belongs to another user
+var errMediaNotFound = errors.New("media not found")
+
+type MediaRepository interface {
+	CreateMedia(media *models.Media, quota int64) *dto.ErrorResponse
+	GetUsage(userID uuid.UUID) (int64, *dto.ErrorResponse)
+	GetMedia(userID uuid.UUID, limit, offset int) (*[]models.Media, int64, *dto.ErrorResponse)
+	DeleteMedia(mediaID uuid.UUID, userID uuid.UUID, role string) (*models.Media, *dto.ErrorResponse)
+	DeleteOrphanedMedia(postID uuid.UUID) ([]models.Media, error)
+}
+
+type mediaRepository struct {
+	*gorm.DB
+}
+
+func InitMediaRepository(db *gorm.DB) MediaRepository {
+	return &mediaRepository{db}
+}
+
+// store the details of an uploaded file when it fits in the storage quota of the user
+func (db *mediaRepository) CreateMedia(media *models.Media, quota int64) *dto.ErrorResponse {
+	var errorResponse *dto.ErrorResponse
+
+	//lock the user so concurrent uploads check the quota one after the other
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var usage int64
+
+		data := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("user_id").Where("user_id=?", media.UserID).First(&models.User{})
+		if data.Error != nil {
+			return data.Error
+		}
+
+		data = tx.Model(&models.Media{}).Where("user_id=?", media.UserID).Select("COALESCE(SUM(size), 0)").Scan(&usage)
+		if data.Error != nil {
+			return data.Error
+		} else if usage+media.Size > quota {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusRequestEntityTooLarge, Error: "upload exceeds the storage quota"}
+			return nil
+		}
+
+		return tx.Create(media).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return errorResponse
+}
+
+// retrieve the total size of the files uploaded by the user
+func (db *mediaRepository) GetUsage(userID uuid.UUID) (int64, *dto.ErrorResponse) {
+	var usage int64
+
+	data := db.Model(&models.Media{}).Where("user_id=?", userID).Select("COALESCE(SUM(size), 0)").Scan(&usage)
+	if data.Error != nil {
+		return 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return usage, nil
+}
+
+// retrieve the files uploaded by the user
+func (db *mediaRepository) GetMedia(userID uuid.UUID, limit, offset int) (*[]models.Media, int64, *dto.ErrorResponse) {
+	var media []models.Media
+	var count int64
+
+	data := db.Model(&models.Media{}).Where("user_id=?", userID).Count(&count).Order("created_at DESC").Limit(limit).Offset(offset).Find(&media)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &media, count, nil
+}
+
+// delete the file details if the user uploaded it or if it is the admin
+func (db *mediaRepository) DeleteMedia(mediaID uuid.UUID, userID uuid.UUID, role string) (*models.Media, *dto.ErrorResponse) {
+	var media models.Media
+
+	//check if the record exists and if the user can access it
+	data := db.Where("media_id=?", mediaID).First(&media)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "media not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if media.UserID != userID && role != constants.AdminRole {
+		return nil, &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot delete other users media"}
+	}
+
+	//detach the file from the posts using it
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM post_media WHERE media_id = ?", mediaID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&media).Error
+	})
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &media, nil
+}
+
+// detach the media of a deleted post and delete the ones no other post uses
+func (db *mediaRepository) DeleteOrphanedMedia(postID uuid.UUID) ([]models.Media, error) {
+	var orphans []models.Media
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var mediaIDs []uuid.UUID
+
+		data := tx.Table("post_media").Where("post_id=?", postID).Pluck("media_id", &mediaIDs)
+		if data.Error != nil || len(mediaIDs) == 0 {
+			return data.Error
+		}
+
+		if err := tx.Exec("DELETE FROM post_media WHERE post_id = ?", postID).Error; err != nil {
+			return err
+		}
+
+		data = tx.Where("media_id IN ? AND NOT EXISTS (SELECT 1 FROM post_media WHERE post_media.media_id = media.media_id)", mediaIDs).Find(&orphans)
+		if data.Error != nil || len(orphans) == 0 {
+			return data.Error
+		}
+
+		return tx.Delete(&orphans).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
+
+// replace the media of the post with the given media uploaded by the user
+func assignMedia(tx *gorm.DB, post *models.Post, userID uuid.UUID, mediaIDs []uuid.UUID) error {
+	var media []models.Media
+
+	if len(mediaIDs) == 0 {
+		return tx.Model(post).Association("Media").Clear()
+	}
+
+	data := tx.Where("media_id IN ? AND user_id=?", mediaIDs, userID).Find(&media)
+	if data.Error != nil {
+		return data.Error
+	} else if len(media) != len(uniqueIDs(mediaIDs)) {
+		return errMediaNotFound
+	}
+
+	return tx.Model(post).Association("Media").Replace(media)
+}
+
+// remove the duplicate ids
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	var unique []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
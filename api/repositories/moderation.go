@@ -0,0 +1,329 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// matches the links written in the source of a comment, the links markdown finds on its own are found in the html
+var commentLinkPattern = regexp.MustCompile(`(?i)(https?://|www\.)`)
+
+type ModerationRepository interface {
+	GetQueue(status string, category string, limit, offset int) (*[]models.Comment, int64, *dto.ErrorResponse)
+	ModerateComments(moderation *dto.CommentModeration, moderatorID uuid.UUID) (int64, *dto.ErrorResponse)
+	GetRules() (*[]models.ModerationRule, *dto.ErrorResponse)
+	SaveRule(rule *models.ModerationRule) *dto.ErrorResponse
+	DeleteRule(ruleID uuid.UUID) *dto.ErrorResponse
+	GetRevisions(commentID uuid.UUID) (*[]models.CommentRevision, *dto.ErrorResponse)
+}
+
+type moderationRepository struct {
+	*gorm.DB
+}
+
+func InitModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db}
+}
+
+// retrieve the comments with the given status, the oldest first so they are moderated in the order they were written
+func (db *moderationRepository) GetQueue(status string, category string, limit, offset int) (*[]models.Comment, int64, *dto.ErrorResponse) {
+	var comments []models.Comment
+	var count int64
+
+	query := db.Model(&models.Comment{}).Where("comments.status=?", status).
+		Joins("JOIN posts ON posts.post_id = comments.post_id AND posts.deleted_at IS NULL")
+	if category != "" {
+		query = query.Where("posts.category_id IN (SELECT category_id FROM categories WHERE slug=? AND deleted_at IS NULL)", category)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Order("comments.created_at").Limit(limit).Offset(offset).Find(&comments)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if err := fillCommentsHTML(comments); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &comments, count, nil
+}
+
+// approve or reject the comments waiting in the queue, the notifications about them are marked as read
+//
+// the comments held by the reports are resolved along with their reports, the others are moderated through the reports
+func (db *moderationRepository) ModerateComments(moderation *dto.CommentModeration, moderatorID uuid.UUID) (int64, *dto.ErrorResponse) {
+	var moderated int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var reported []uuid.UUID
+		now := time.Now()
+
+		data := tx.Model(&models.Comment{}).Where("comment_id IN ? AND status=? AND hold_reason=?", moderation.CommentIDs,
+			constants.CommentStatusPending, constants.HoldReasonReported).Pluck("comment_id", &reported)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		data = tx.Model(&models.Comment{}).Where("comment_id IN ? AND status IN ?", moderation.CommentIDs,
+			[]string{constants.CommentStatusPending, constants.CommentStatusSpam}).
+			Updates(map[string]interface{}{"status": moderation.Status, "hold_reason": "", "moderated_at": now})
+		if data.Error != nil {
+			return data.Error
+		}
+		moderated = data.RowsAffected
+
+		if err := closeCommentReports(tx, reported, moderation.Status, moderatorID, now); err != nil {
+			return err
+		}
+
+		//the comments held or rejected by the spam checks tell how well the checks did
+		review := constants.SpamReviewHam
+		if moderation.Status == constants.CommentStatusSpam {
+			review = constants.SpamReviewSpam
+		}
+		if moderation.Status != constants.CommentStatusRejected {
+			data = tx.Model(&models.SpamCheck{}).Where("target_type=? AND target_id IN ? AND decision<>? AND review=''",
+				constants.ReactionTargetComment, moderation.CommentIDs, spam.DecisionAllow).
+				Updates(map[string]interface{}{"review": review, "reviewed_at": now})
+			if data.Error != nil {
+				return data.Error
+			}
+		}
+
+		return tx.Model(&models.Notification{}).Where("comment_id IN ? AND read_at IS NULL", moderation.CommentIDs).Update("read_at", now).Error
+	})
+	if err != nil {
+		return 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if moderated == 0 {
+		return 0, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comments do not exist or are not waiting for moderation"}
+	}
+
+	return moderated, nil
+}
+
+// close the open reports of the comments moderated from the queue, approving dismisses them and rejecting resolves
+// them, every comment whose reports were closed is recorded in the audit log
+func closeCommentReports(tx *gorm.DB, commentIDs []uuid.UUID, status string, moderatorID uuid.UUID, now time.Time) error {
+	action, reportStatus := constants.ReportActionRemove, constants.ReportStatusResolved
+	if status == constants.CommentStatusApproved {
+		action, reportStatus = constants.ReportActionDismiss, constants.ReportStatusDismissed
+	}
+
+	for _, commentID := range commentIDs {
+		data := tx.Model(&models.Report{}).Where("target_type=? AND target_id=? AND status=?", constants.ReactionTargetComment, commentID, constants.ReportStatusOpen).
+			Updates(map[string]interface{}{"status": reportStatus, "resolved_by": moderatorID, "resolved_at": now})
+		if data.Error != nil {
+			return data.Error
+		} else if data.RowsAffected == 0 {
+			continue
+		}
+
+		audit := models.AuditLog{ActorID: &moderatorID, Action: action, TargetType: constants.ReactionTargetComment, TargetID: commentID,
+			Details: "moderated from the comment queue as " + status}
+		if err := tx.Create(&audit).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retrieve the site-wide rules followed by the rules of the categories
+func (db *moderationRepository) GetRules() (*[]models.ModerationRule, *dto.ErrorResponse) {
+	var rules []models.ModerationRule
+
+	data := db.Order("category_id NULLS FIRST").Find(&rules)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &rules, nil
+}
+
+// create the rules of a category, or the site-wide rules without a category, saving them again replaces them
+func (db *moderationRepository) SaveRule(rule *models.ModerationRule) *dto.ErrorResponse {
+	var existing models.ModerationRule
+
+	if rule.CategoryID != nil {
+		var category models.Category
+
+		data := db.Where("category_id=?", *rule.CategoryID).First(&category)
+		if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+			return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "category not found"}
+		} else if data.Error != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("category_id IS NULL")
+		if rule.CategoryID != nil {
+			query = tx.Where("category_id=?", *rule.CategoryID)
+		}
+
+		data := query.Limit(1).Find(&existing)
+		if data.Error != nil {
+			return data.Error
+		} else if data.RowsAffected == 0 {
+			return tx.Create(rule).Error
+		}
+
+		//every flag is saved so the rules can be turned off
+		data = tx.Model(&existing).Select("hold_first_comment", "hold_links", "hold_all", "updated_by", "updated_at").Updates(rule)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		rule.RuleID = existing.RuleID
+		rule.CreatedAt = existing.CreatedAt
+		return nil
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return nil
+}
+
+// delete the rules, the comments of a category without rules follow the site-wide rules
+func (db *moderationRepository) DeleteRule(ruleID uuid.UUID) *dto.ErrorResponse {
+	data := db.Where("rule_id=?", ruleID).Delete(&models.ModerationRule{})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "rule not found"}
+	}
+
+	return nil
+}
+
+// retrieve the previous versions of a comment the oldest first, the versions of deleted comments are kept too
+func (db *moderationRepository) GetRevisions(commentID uuid.UUID) (*[]models.CommentRevision, *dto.ErrorResponse) {
+	var revisions []models.CommentRevision
+
+	data := db.Unscoped().Where("comment_id=?", commentID).First(&models.Comment{})
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Where("comment_id=?", commentID).Order("version").Find(&revisions)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &revisions, nil
+}
+
+// limits the query to the comments the viewer can see, the comments which are not approved are only seen by
+// their author, the authors of the post and the moderators
+func visibleComments(viewer dto.Viewer) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewer.Role == constants.AdminRole || viewer.Role == constants.ModeratorRole {
+			return db
+		} else if viewer.UserID == uuid.Nil {
+			return db.Where("comments.status = ?", constants.CommentStatusApproved)
+		}
+
+		return db.Where(`(comments.status = ? OR comments.user_id = ? OR EXISTS (SELECT 1 FROM post_authors
+			WHERE post_authors.post_id = comments.post_id AND post_authors.status = ? AND post_authors.user_id = ?))`,
+			constants.CommentStatusApproved, viewer.UserID, constants.AuthorStatusAccepted, viewer.UserID)
+	}
+}
+
+// set the status of a new comment using the spam checks and the rules of the category of its post, the rendered html has to be set
+//
+// the comments of the moderators and of the authors of the post are never held
+func holdComment(db *gorm.DB, comment *models.Comment, post *models.Post, viewer dto.Viewer, verdict *spam.Verdict) error {
+	var rules models.ModerationRule
+
+	comment.Status = constants.CommentStatusApproved
+	comment.HoldReason = ""
+
+	if viewer.Role == constants.AdminRole || viewer.Role == constants.ModeratorRole || post.HasAuthor(viewer.UserID) {
+		return nil
+	}
+
+	//the spam checks decide before the rules, rejected comments are kept as spam so they can still be approved
+	if verdict != nil && verdict.Decision == spam.DecisionReject {
+		comment.Status = constants.CommentStatusSpam
+		comment.HoldReason = constants.HoldReasonSpam
+		return nil
+	} else if verdict != nil && verdict.Decision == spam.DecisionHold {
+		comment.Status = constants.CommentStatusPending
+		comment.HoldReason = constants.HoldReasonSpam
+		return nil
+	}
+
+	//the rules of the category replace the site-wide rules
+	data := db.Where("category_id=? OR category_id IS NULL", post.CategoryID).Order("category_id NULLS LAST").Limit(1).Find(&rules)
+	if data.Error != nil {
+		return data.Error
+	} else if data.RowsAffected == 0 {
+		return nil
+	}
+
+	if rules.HoldAll {
+		comment.HoldReason = constants.HoldReasonAll
+	} else if rules.HoldLinks && (commentLinkPattern.MatchString(comment.Content) || strings.Contains(comment.ContentHTML, "<a ")) {
+		comment.HoldReason = constants.HoldReasonLinks
+	} else if rules.HoldFirstComment {
+		var approved int64
+
+		data = db.Model(&models.Comment{}).Where("user_id=? AND status=?", comment.UserID, constants.CommentStatusApproved).Count(&approved)
+		if data.Error != nil {
+			return data.Error
+		} else if approved == 0 {
+			comment.HoldReason = constants.HoldReasonFirstComment
+		}
+	}
+
+	if comment.HoldReason != "" {
+		comment.Status = constants.CommentStatusPending
+	}
+
+	return nil
+}
+
+// let the authors of the post know the comment waits for moderation
+func notifyPendingComment(db *gorm.DB, comment *models.Comment, post *models.Post) error {
+	var notifications []models.Notification
+
+	for _, author := range post.Authors {
+		if author.UserID == comment.UserID {
+			continue
+		}
+
+		notifications = append(notifications, models.Notification{
+			UserID:    author.UserID,
+			Type:      constants.NotificationCommentPending,
+			Message:   fmt.Sprintf("a comment on %q is waiting for moderation", post.Title),
+			PostID:    &post.PostID,
+			CommentID: &comment.CommentID,
+		})
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return db.Create(&notifications).Error
+}
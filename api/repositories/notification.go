@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	GetNotifications(userID uuid.UUID, unread bool, limit, offset int) (*[]models.Notification, int64, *dto.ErrorResponse)
+	ReadNotifications(userID uuid.UUID, notificationID uuid.UUID) (int64, *dto.ErrorResponse)
+}
+
+type notificationRepository struct {
+	*gorm.DB
+}
+
+func InitNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+// retrieve the notifications of the user, the latest first
+func (db *notificationRepository) GetNotifications(userID uuid.UUID, unread bool, limit, offset int) (*[]models.Notification, int64, *dto.ErrorResponse) {
+	var notifications []models.Notification
+	var count int64
+
+	query := db.Model(&models.Notification{}).Where("user_id=?", userID)
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &notifications, count, nil
+}
+
+// mark a notification of the user as read, or every notification without an id
+func (db *notificationRepository) ReadNotifications(userID uuid.UUID, notificationID uuid.UUID) (int64, *dto.ErrorResponse) {
+	query := db.Model(&models.Notification{}).Where("user_id=? AND read_at IS NULL", userID)
+	if notificationID != uuid.Nil {
+		var count int64
+
+		//a notification which was already read is not an error
+		data := db.Model(&models.Notification{}).Where("notification_id=? AND user_id=?", notificationID, userID).Count(&count)
+		if data.Error != nil {
+			return 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		} else if count == 0 {
+			return 0, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "notification not found"}
+		}
+
+		query = query.Where("notification_id=?", notificationID)
+	}
+
+	data := query.Update("read_at", time.Now())
+	if data.Error != nil {
+		return 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return data.RowsAffected, nil
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// matches the pins of the given scope which did not expire, category pins only count while the post stays in the category
+const activePinCondition = `post_pins.scope = ? AND (post_pins.category_id IS NULL OR post_pins.category_id = posts.category_id)
+	AND (post_pins.expires_at IS NULL OR post_pins.expires_at > NOW())`
+
+type PinRepository interface {
+	PinPost(pin *models.PostPin, position *int) *dto.ErrorResponse
+	UnpinPost(postID uuid.UUID, scope string) *dto.ErrorResponse
+	GetPins(scope string, category string, limit, offset int) (*[]models.PostPin, int64, *dto.ErrorResponse)
+}
+
+type pinRepository struct {
+	*gorm.DB
+}
+
+func InitPinRepository(db *gorm.DB) PinRepository {
+	return &pinRepository{db}
+}
+
+// pin a published post, pinning it again changes its position and expiry
+func (db *pinRepository) PinPost(pin *models.PostPin, position *int) *dto.ErrorResponse {
+	var post models.Post
+
+	data := db.Where("post_id=?", pin.PostID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if post.Status != constants.PostStatusPublished {
+		return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "only published posts can be pinned"}
+	}
+
+	//category pins are shown on top of the category the post belongs to
+	pin.CategoryID = nil
+	if pin.Scope == constants.PinScopeCategory {
+		if post.CategoryID == uuid.Nil {
+			return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "the post does not belong to a category"}
+		}
+		pin.CategoryID = &post.CategoryID
+	}
+
+	//posts pinned without a position go after the other pinned posts
+	if position != nil {
+		pin.Position = *position
+	} else {
+		query := db.Model(&models.PostPin{}).Select("COALESCE(MAX(position) + 1, 0)").
+			Where("scope=? AND post_id<>?", pin.Scope, pin.PostID)
+		if pin.CategoryID != nil {
+			query = query.Where("category_id=?", pin.CategoryID)
+		}
+
+		data = query.Scan(&pin.Position)
+		if data.Error != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+	}
+
+	data = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}, {Name: "scope"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category_id", "position", "expires_at", "pinned_by", "updated_at"}),
+	}).Create(pin)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return nil
+}
+
+// remove the pin of a post
+func (db *pinRepository) UnpinPost(postID uuid.UUID, scope string) *dto.ErrorResponse {
+	data := db.Where("post_id=? AND scope=?", postID, scope).Delete(&models.PostPin{})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "pin not found"}
+	}
+
+	return nil
+}
+
+// retrieve the pins of the scope which did not expire in their order, optionally only those of a category
+func (db *pinRepository) GetPins(scope string, category string, limit, offset int) (*[]models.PostPin, int64, *dto.ErrorResponse) {
+	var pins []models.PostPin
+	var count int64
+
+	query := db.Model(&models.PostPin{}).
+		Joins("JOIN posts ON posts.post_id = post_pins.post_id AND posts.deleted_at IS NULL").
+		Where(activePinCondition, scope)
+	if category != "" {
+		query = query.Joins("JOIN categories ON categories.category_id = post_pins.category_id").
+			Where("categories.slug=?", category)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Select("post_pins.*").Preload("Post", func(db *gorm.DB) *gorm.DB {
+		return db.Select("post_id", "title", "slug", "status", "visibility", "user_id", "category_id", "created_at")
+	}).Order("post_pins.category_id NULLS FIRST, post_pins.position, post_pins.created_at DESC").
+		Limit(limit).Offset(offset).Find(&pins)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &pins, count, nil
+}
+
+// order the pinned posts of the scope first by their position, followed by the given order
+//
+// gorm cannot merge an order expression with other orders, so the rest of the order is part of the expression
+func pinnedFirst(scope string, order string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(SELECT post_pins.position FROM post_pins WHERE post_pins.post_id = posts.post_id AND " + activePinCondition + ") NULLS LAST, " + order,
+			Vars: []interface{}{scope},
+		}})
+	}
+}
+
+// mark the posts which are pinned in the scope
+func fillPostsPinned(db *gorm.DB, posts []models.Post, scope string) error {
+	var pinned []uuid.UUID
+
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	data := db.Model(&models.PostPin{}).Joins("JOIN posts ON posts.post_id = post_pins.post_id").
+		Where("post_pins.post_id IN ?", postIDs).Where(activePinCondition, scope).Pluck("post_pins.post_id", &pinned)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	pins := make(map[uuid.UUID]bool, len(pinned))
+	for _, postID := range pinned {
+		pins[postID] = true
+	}
+
+	for i := range posts {
+		posts[i].Pinned = pins[posts[i].PostID]
+	}
+
+	return nil
+}
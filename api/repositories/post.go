@@ -3,21 +3,25 @@ package repositories
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type PostRepository interface {
-	CreatePost(post *models.Post) *dto.ErrorResponse
-	GetPosts(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Post, int64, error)
-	GetPost(postID uuid.UUID) (*models.Post, *dto.ErrorResponse)
-	UpdatePost(post *models.Post, postID uuid.UUID) *dto.ErrorResponse
+	CreatePost(post *models.Post, check SpamCheck) *dto.ErrorResponse
+	GetPosts(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Post, int64, error)
+	GetPost(postID uuid.UUID, viewer dto.Viewer) (*models.Post, *dto.ErrorResponse)
+	GetPostBySlug(slug string, viewer dto.Viewer) (*models.Post, bool, *dto.ErrorResponse)
+	UpdatePost(post *models.Post, postID uuid.UUID, check SpamCheck) *dto.ErrorResponse
 	DeletePost(userID uuid.UUID, postID uuid.UUID, role string) *dto.ErrorResponse
+	LockComments(postID uuid.UUID, locked bool, viewer dto.Viewer) *dto.ErrorResponse
 }
 
 type postRepository struct {
@@ -29,7 +33,12 @@ func InitPostRepository(db *gorm.DB) PostRepository {
 }
 
 // create a new post
-func (db *postRepository) CreatePost(post *models.Post) *dto.ErrorResponse {
+func (db *postRepository) CreatePost(post *models.Post, check SpamCheck) *dto.ErrorResponse {
+	//suspended users cannot write posts
+	if errorResponse := checkSuspended(db.DB, post.UserID); errorResponse != nil {
+		return errorResponse
+	}
+
 	//check if the category exists
 	data := db.Where("category_id=?", post.CategoryID).First(&models.Category{})
 	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
@@ -38,74 +47,335 @@ func (db *postRepository) CreatePost(post *models.Post) *dto.ErrorResponse {
 		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
 	}
 
-	//creates a new post
-	data = db.Create(&post)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//the posts rejected by the spam checks are not created, the held posts are hidden until a moderator reviews them
+	verdict := check()
+	if verdict != nil && verdict.Decision == spam.DecisionReject {
+		if err := saveSpamCheck(db.DB, verdict, constants.ReactionTargetPost, nil, post.UserID); err != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		return &dto.ErrorResponse{Status: http.StatusUnprocessableEntity, Error: "post was rejected as spam"}
+	} else if verdict != nil && verdict.Decision == spam.DecisionHold {
+		now := time.Now()
+		post.HiddenAt = &now
+		post.HiddenReason = constants.HoldReasonSpam
+	} else {
+		post.HiddenAt = nil
+		post.HiddenReason = ""
 	}
 
+	//authors are only added through invitations
+	post.Authors = nil
+
+	//store the rendered html along with the source
+	if err := renderPost(post); err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		//creates a new post with a unique slug generated from the title
+		err := saveWithSlug(tx, constants.SlugEntityPost, post.Title, uuid.Nil, func(tx *gorm.DB, slug string) error {
+			post.Slug = slug
+			return tx.Create(&post).Error
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := saveSpamCheck(tx, verdict, constants.ReactionTargetPost, &post.PostID, post.UserID); err != nil {
+			return err
+		}
+
+		//the creator of the post is its owner
+		now := time.Now()
+		owner := models.PostAuthor{PostID: post.PostID, UserID: post.UserID, Role: constants.AuthorRoleOwner, Status: constants.AuthorStatusAccepted, AcceptedAt: &now}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+
+		//label the post with the given tags, creating the missing ones
+		if err := assignTags(tx, post, post.TagNames); err != nil {
+			return err
+		}
+
+		//attach the media uploaded by the author
+		return assignMedia(tx, post, post.UserID, post.MediaIDs)
+	})
+	if errors.Is(err, errMediaNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: err.Error()}
+	} else if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	invalidateRelatedPosts()
+
 	return nil
 }
 
 // retrieve every users posts using either date or post id
-func (db *postRepository) GetPosts(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Post, int64, error) {
+func (db *postRepository) GetPosts(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Post, int64, error) {
 	var post []models.Post
 	var count int64
 	fromDate := keywords["fromDate"].(string)
 	toDate := keywords["toDate"].(string)
 	title := keywords["title"].(string)
+	author := keywords["author"].(string)
 	limit := keywords["limit"].(int)
 	offset := keywords["offset"].(int)
+	all, _ := keywords["all"].(bool)
+
+	//apply the post id, date and title filters on the posts the viewer can see, admins ask for every post explicitly
+	listed := listedPosts(viewer)
+	if all && viewer.Role == constants.AdminRole {
+		listed = allPosts
+	}
+	query := db.Model(&models.Post{}).Scopes(listed)
+	if postID != uuid.Nil {
+		query = query.Where("post_id=?", postID)
+	}
+	if fromDate != "" {
+		query = query.Where("created_at >= ?", fromDate)
+	}
+	if toDate != "" {
+		query = query.Where("created_at <= ?", toDate)
+	}
+	if title != "" {
+		query = query.Where("title ILIKE '%' || ? || '%'", title)
+	}
+	if author != "" {
+		query = query.Scopes(authoredBy(author))
+	}
 
-	//check whether to use date filter or post id
-	data := db.Model(post).Preload("Comments").Limit(limit).Offset(offset).Count(&count).Find(&post)
+	//the pinned posts come first, the count is not affected by the order
+	data := query.Count(&count).Scopes(preloadAuthors, pinnedFirst(constants.PinScopeSite, "posts.created_at DESC")).
+		Preload("Tags").Preload("Media").Preload("Comments", visibleComments(viewer)).Limit(limit).Offset(offset).Find(&post)
 	if data.Error != nil {
 		return nil, 0, data.Error
 	}
 
-	if fromDate != "" {
-		db.Preload("Comments").Where("created_at >= ?", fromDate)
-	} else if toDate != "" {
-		db.Preload("Comments").Where("created_at <= ?", toDate)
-	} else if title != "" {
-		db.Preload("Comments").Where("title LIKE '%' || ? || '%' ", title)
+	if err := fillPostsPinned(db.DB, post, constants.PinScopeSite); err != nil {
+		return nil, 0, err
+	}
+
+	if err := fillPostsHTML(post); err != nil {
+		return nil, 0, err
+	}
+	lockPosts(post, viewer)
+
+	if err := fillPostsReactions(db.DB, post); err != nil {
+		return nil, 0, err
+	}
+
+	if err := fillPostsBookmarked(db.DB, post, viewer); err != nil {
+		return nil, 0, err
 	}
 
 	return &post, count, nil
 }
 
-func (db *postRepository) GetPost(postID uuid.UUID) (*models.Post, *dto.ErrorResponse) {
+func (db *postRepository) GetPost(postID uuid.UUID, viewer dto.Viewer) (*models.Post, *dto.ErrorResponse) {
 	var post models.Post
 
-	data := db.Where("post_id=?", postID).Preload("Comments").First(&post)
+	data := db.Scopes(visiblePosts(viewer), preloadAuthors).Where("post_id=?", postID).Preload("Tags").Preload("Media").Preload("Comments", visibleComments(viewer)).First(&post)
 	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
 		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
 	} else if data.Error != nil {
 		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
 	}
 
+	if err := fillPostHTML(&post); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	lockPost(&post, viewer)
+
+	if err := fillPostReactions(db.DB, &post); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	if err := fillPostBookmarked(db.DB, &post, viewer); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	if err := fillPostSeries(db.DB, &post, viewer); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	if err := fillCommentsLocked(db.DB, &post); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
 	return &post, nil
 }
 
-// update a existing post
-func (db *postRepository) UpdatePost(post *models.Post, postID uuid.UUID) *dto.ErrorResponse {
+// retrieve a post using its current or old slug, moved is true when an old slug was used
+func (db *postRepository) GetPostBySlug(slug string, viewer dto.Viewer) (*models.Post, bool, *dto.ErrorResponse) {
+	var post models.Post
+
+	data := db.Scopes(visiblePosts(viewer), preloadAuthors).Where("slug=?", slug).Preload("Tags").Preload("Media").Preload("Comments", visibleComments(viewer)).First(&post)
+	if data.Error == nil {
+		if err := fillPostHTML(&post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		lockPost(&post, viewer)
+
+		if err := fillPostReactions(db.DB, &post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		if err := fillPostBookmarked(db.DB, &post, viewer); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		if err := fillPostSeries(db.DB, &post, viewer); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		if err := fillCommentsLocked(db.DB, &post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		return &post, false, nil
+	} else if !errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//check if the slug belonged to a renamed post
+	history, err := findSlugHistory(db.DB, constants.SlugEntityPost, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if err != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	data = db.Scopes(visiblePosts(viewer)).Where("post_id=?", history.EntityID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &post, true, nil
+}
+
+// update a existing post, the owner and the co-authors can edit it
+//
+// the edits are checked for spam like new posts, rejected edits are not saved and held posts are hidden until a
+// moderator reviews them
+func (db *postRepository) UpdatePost(post *models.Post, postID uuid.UUID, check SpamCheck) *dto.ErrorResponse {
 	var postData models.Post
 
 	//check if the record exists and if the user can access it
 	data := db.Where("post_id=?", postID).First(&postData)
 	if data.Error != nil {
 		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: data.Error.Error()}
-	} else if postData.UserID != post.UserID {
+	}
+
+	role, err := authorRole(db.DB, postID, post.UserID)
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if role != constants.AuthorRoleOwner && role != constants.AuthorRoleCoAuthor {
 		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot update other users post"}
 	}
 
-	//updates the record if the user created it or if it is the admin
-	data = db.Where("post_id=?", postID).Updates(&post)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	//the owner and the authors stay the same whoever edits the post
+	editorID := post.UserID
+	post.UserID = uuid.Nil
+	post.Authors = nil
+	post.HiddenAt = nil
+
+	//password protected posts need a password to unlock them
+	if post.Visibility == constants.VisibilityPassword && post.PasswordHash == "" && postData.PasswordHash == "" {
+		return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "password protected posts need a password"}
+	}
+
+	//the edits are only checked for spam once the user is allowed to make them
+	verdict := check()
+	if verdict != nil && verdict.Decision == spam.DecisionReject {
+		if err := saveSpamCheck(db.DB, verdict, constants.ReactionTargetPost, &postID, editorID); err != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		return &dto.ErrorResponse{Status: http.StatusUnprocessableEntity, Error: "post was rejected as spam"}
+	}
+
+	//slugs are only generated from the title
+	post.Slug = ""
+
+	//render the html again when either the content or its format changes
+	post.ContentHTML = ""
+	if post.Content != "" || post.ContentFormat != "" {
+		rendered := models.Post{Content: post.Content, ContentFormat: post.ContentFormat}
+		if rendered.Content == "" {
+			rendered.Content = postData.Content
+		}
+		if rendered.ContentFormat == "" {
+			rendered.ContentFormat = postData.ContentFormat
+		}
+
+		if err := renderPost(&rendered); err != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		post.ContentHTML = rendered.ContentHTML
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		//regenerate the slug when the title changes and keep the old one for redirects
+		if post.Title != "" && post.Title != postData.Title {
+			err := saveWithSlug(tx, constants.SlugEntityPost, post.Title, postID, func(tx *gorm.DB, slug string) error {
+				if slug == postData.Slug {
+					return nil
+				}
+
+				if err := recordSlugHistory(tx, constants.SlugEntityPost, postID, postData.Slug, slug); err != nil {
+					return err
+				}
+				post.Slug = slug
+				return tx.Model(&models.Post{}).Where("post_id=?", postID).UpdateColumn("slug", slug).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		//updates the record if the user created it or if it is the admin, only moderators hide posts
+		data = tx.Omit("hidden_at", "hidden_reason", "comments_locked").Where("post_id=?", postID).Updates(&post)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		if err := saveSpamCheck(tx, verdict, constants.ReactionTargetPost, &postID, editorID); err != nil {
+			return err
+		}
+
+		//the posts held by the spam checks are hidden unless they already are
+		if verdict != nil && verdict.Decision == spam.DecisionHold && postData.HiddenAt == nil {
+			now := time.Now()
+			err := tx.Model(&models.Post{}).Where("post_id=? AND hidden_at IS NULL", postID).
+				UpdateColumns(map[string]interface{}{"hidden_at": now, "hidden_reason": constants.HoldReasonSpam}).Error
+			if err != nil {
+				return err
+			}
+			postData.HiddenAt = &now
+			post.HiddenAt = &now
+		}
+
+		//replace the tags and media only when they were sent
+		if post.TagNames != nil {
+			if err := assignTags(tx, &models.Post{PostID: postID}, post.TagNames); err != nil {
+				return err
+			}
+		}
+
+		if post.MediaIDs != nil {
+			return assignMedia(tx, &models.Post{PostID: postID}, editorID, post.MediaIDs)
+		}
+
+		return nil
+	})
+	if errors.Is(err, errMediaNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: err.Error()}
+	} else if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	} else if data.RowsAffected == 0 {
 		return &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
 	}
+	invalidateRelatedPosts()
 
 	return nil
 }
@@ -123,12 +393,37 @@ func (db *postRepository) DeletePost(userID uuid.UUID, postID uuid.UUID, role st
 	}
 
 	//deletes the record if the user created it or if it is the admin
-	data = db.Where("post_id=?", postID).Delete(&postData)
-	if data.Error != nil {
-		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		data = tx.Where("post_id=?", postID).Delete(&postData)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		//the reactions, bookmarks, authors, pins and series part go away along with the post
+		if err := deleteReactions(tx, constants.ReactionTargetPost, postID); err != nil {
+			return err
+		}
+
+		if err := removePostFromSeries(tx, postID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("post_id=?", postID).Delete(&models.PostAuthor{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("post_id=?", postID).Delete(&models.PostPin{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("post_id=?", postID).Delete(&models.Bookmark{}).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
 	} else if data.RowsAffected == 0 {
 		return &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
 	}
+	invalidateRelatedPosts()
 
 	return nil
 }
@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PublicRepository interface {
+	GetPosts(filter dto.PublicPostFilter, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse)
+	GetPost(slug string, viewer dto.Viewer) (*models.Post, bool, *dto.ErrorResponse)
+	GetProtectedPost(slug string) (*models.Post, *dto.ErrorResponse)
+	GetComments(slug string, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Comment, int64, *dto.ErrorResponse)
+	GetThread(slug string, commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse)
+	GetCategories(limit, offset int) (*[]models.Category, int64, *dto.ErrorResponse)
+	GetCategory(slug string) (*models.Category, *dto.ErrorResponse)
+	GetTag(slug string) (*models.Tag, *dto.ErrorResponse)
+	GetAuthor(username string) (*models.User, int64, *dto.ErrorResponse)
+}
+
+type publicRepository struct {
+	*gorm.DB
+}
+
+func InitPublicRepository(db *gorm.DB) PublicRepository {
+	return &publicRepository{db}
+}
+
+// limits the query to the posts which are published
+func publishedPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ? AND posts.hidden_at IS NULL", constants.PostStatusPublished)
+}
+
+// retrieve the published posts the viewer can see filtered by category, tag or author
+func (db *publicRepository) GetPosts(filter dto.PublicPostFilter, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse) {
+	var posts []models.Post
+	var count int64
+
+	query := db.Model(&models.Post{}).Scopes(listedPosts(viewer))
+	if filter.Category != "" {
+		query = query.Joins("JOIN categories ON categories.category_id = posts.category_id AND categories.deleted_at IS NULL").
+			Where("categories.slug=?", filter.Category)
+	}
+	if filter.Tag != "" {
+		query = query.Joins("JOIN post_tags ON post_tags.post_id = posts.post_id").
+			Joins("JOIN tags ON tags.tag_id = post_tags.tag_id").
+			Where("tags.slug=?", filter.Tag)
+	}
+	if filter.Author != "" {
+		query = query.Scopes(authoredBy(filter.Author))
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//the posts pinned in the category come first when listing a category, otherwise the site-wide ones
+	pinScope := constants.PinScopeSite
+	if filter.Category != "" {
+		pinScope = constants.PinScopeCategory
+	}
+
+	order := pinnedFirst(pinScope, "posts.created_at DESC")
+	if filter.ByDate {
+		order = func(db *gorm.DB) *gorm.DB {
+			return db.Order("posts.created_at DESC")
+		}
+	}
+
+	data = query.Scopes(preloadAuthors, order).Preload("User").Preload("Category").Preload("Tags").Preload("Media").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&posts)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if err := fillPostsPinned(db.DB, posts, pinScope); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	if err := fillPostsHTML(posts); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	lockPosts(posts, viewer)
+
+	if err := fillPostsReactions(db.DB, posts); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &posts, count, nil
+}
+
+// retrieve a published post the viewer can see using its current or old slug
+func (db *publicRepository) GetPost(slug string, viewer dto.Viewer) (*models.Post, bool, *dto.ErrorResponse) {
+	var post models.Post
+
+	query := db.Scopes(visiblePosts(viewer), preloadAuthors).Preload("User").Preload("Category").Preload("Tags").Preload("Media")
+
+	data := query.Where("slug=?", slug).First(&post)
+	if data.Error == nil {
+		if err := fillPostHTML(&post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		lockPost(&post, viewer)
+
+		if err := fillPostReactions(db.DB, &post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		if err := fillPostSeries(db.DB, &post, viewer); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		if err := fillCommentsLocked(db.DB, &post); err != nil {
+			return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+
+		return &post, false, nil
+	} else if !errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//check if the slug belonged to the post before it was renamed
+	history, err := findSlugHistory(db.DB, constants.SlugEntityPost, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if err != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	data = db.Scopes(visiblePosts(viewer)).Where("post_id=?", history.EntityID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, false, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &post, true, nil
+}
+
+// retrieve a published password protected post along with its password hash
+func (db *publicRepository) GetProtectedPost(slug string) (*models.Post, *dto.ErrorResponse) {
+	var post models.Post
+
+	data := db.Scopes(publishedPosts).Where("slug=? AND visibility=?", slug, constants.VisibilityPassword).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &post, nil
+}
+
+// retrieve the threads of a published post along with their answers
+func (db *publicRepository) GetComments(slug string, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Comment, int64, *dto.ErrorResponse) {
+	var comments []models.Comment
+	var count int64
+
+	post, errorResponse := db.readablePost(slug, viewer)
+	if errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	query := db.Model(&models.Comment{}).Scopes(visibleComments(viewer)).Where("post_id=? AND parent_id IS NULL", post.PostID)
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Preload("User").Order(threadOrder(sort)).Limit(limit).Offset(offset).Find(&comments)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if err := loadThreads(db.DB, comments, sort, viewer, true); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &comments, count, nil
+}
+
+// retrieve a comment of a published post along with every answer nested under it
+func (db *publicRepository) GetThread(slug string, commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse) {
+	var comment models.Comment
+
+	post, errorResponse := db.readablePost(slug, viewer)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	data := db.Preload("User").Scopes(visibleComments(viewer)).Where("comment_id=? AND post_id=?", commentID, post.PostID).First(&comment)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	thread := []models.Comment{comment}
+	if err := loadThreads(db.DB, thread, sort, viewer, true); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &thread[0], nil
+}
+
+// check if the post exists and the viewer can read its comments
+func (db *publicRepository) readablePost(slug string, viewer dto.Viewer) (*models.Post, *dto.ErrorResponse) {
+	var post models.Post
+
+	data := db.Scopes(visiblePosts(viewer)).Where("slug=?", slug).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if !canReadPost(&post, viewer) {
+		return nil, &dto.ErrorResponse{Status: http.StatusForbidden, Error: "post is password protected, unlock it first"}
+	}
+
+	return &post, nil
+}
+
+// retrieve every category
+func (db *publicRepository) GetCategories(limit, offset int) (*[]models.Category, int64, *dto.ErrorResponse) {
+	var categories []models.Category
+	var count int64
+
+	data := db.Model(&models.Category{}).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Order("category_name").Limit(limit).Offset(offset).Find(&categories)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &categories, count, nil
+}
+
+// retrieve a category using its slug
+func (db *publicRepository) GetCategory(slug string) (*models.Category, *dto.ErrorResponse) {
+	var category models.Category
+
+	data := db.Where("slug=?", slug).First(&category)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "category not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &category, nil
+}
+
+// retrieve a tag using its slug
+func (db *publicRepository) GetTag(slug string) (*models.Tag, *dto.ErrorResponse) {
+	var tag models.Tag
+
+	data := db.Where("slug=?", slug).First(&tag)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "tag not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &tag, nil
+}
+
+// retrieve an author along with the number of posts they published alone or with others
+func (db *publicRepository) GetAuthor(username string) (*models.User, int64, *dto.ErrorResponse) {
+	var user models.User
+	var count int64
+
+	data := db.Where("username=?", username).First(&user)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "author not found"}
+	} else if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//only the posts anonymous visitors can find are counted, including the ones written with others
+	data = db.Model(&models.Post{}).Scopes(listedPosts(dto.Viewer{}), authoredBy(user.Username)).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &user, count, nil
+}
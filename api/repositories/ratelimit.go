@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/ratelimit"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type rateLimitRepository struct {
+	*gorm.DB
+}
+
+// keeps the rate limit buckets in postgres so the replicas share them
+func InitRateLimitRepository(db *gorm.DB) ratelimit.Store {
+	return &rateLimitRepository{db}
+}
+
+// take a token from the bucket of the key, the row is locked so concurrent requests are counted one after another
+func (db *rateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	var result ratelimit.Result
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var now time.Time
+		var bucket models.RateLimitBucket
+
+		//the clock of the database is shared by every replica
+		if err := tx.Raw("SELECT now()").Scan(&now).Error; err != nil {
+			return err
+		}
+
+		created := models.RateLimitBucket{BucketKey: key, Tokens: float64(limit.Capacity), UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+			return err
+		}
+
+		data := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("bucket_key=?", key).First(&bucket)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		state := ratelimit.Bucket{Tokens: bucket.Tokens, UpdatedAt: bucket.UpdatedAt}
+		result = state.Take(limit, now)
+
+		return tx.Model(&models.RateLimitBucket{}).Where("bucket_key=?", key).
+			UpdateColumns(map[string]interface{}{"tokens": state.Tokens, "updated_at": state.UpdatedAt}).Error
+	})
+
+	return result, err
+}
+
+// delete the buckets not used for the given time, measured with the clock of the database like the buckets
+func (db *rateLimitRepository) Prune(ctx context.Context, unusedFor time.Duration) error {
+	return db.WithContext(ctx).Where("updated_at < now() - make_interval(secs => ?)", unusedFor.Seconds()).Delete(&models.RateLimitBucket{}).Error
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository interface {
+	ToggleReaction(reaction *models.Reaction, viewer dto.Viewer) (*dto.ReactionResult, *dto.ErrorResponse)
+	GetReactors(targetType string, targetID uuid.UUID, reactionType string, limit, offset int, viewer dto.Viewer) ([]dto.Reactor, int64, *dto.ErrorResponse)
+}
+
+type reactionRepository struct {
+	*gorm.DB
+}
+
+func InitReactionRepository(db *gorm.DB) ReactionRepository {
+	return &reactionRepository{db}
+}
+
+// add the reaction of the user, or remove it when they already reacted with the same type
+func (db *reactionRepository) ToggleReaction(reaction *models.Reaction, viewer dto.Viewer) (*dto.ReactionResult, *dto.ErrorResponse) {
+	var result dto.ReactionResult
+
+	//check if the target exists and the user can read its post
+	if errorResponse := checkReactionTarget(db.DB, reaction.TargetType, reaction.TargetID, viewer); errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		target := tx.Where("target_type=? AND target_id=? AND type=?", reaction.TargetType, reaction.TargetID, reaction.Type)
+
+		//remove the existing reaction and decrement its counter
+		data := target.Session(&gorm.Session{}).Where("user_id=?", reaction.UserID).Delete(&models.Reaction{})
+		if data.Error != nil {
+			return data.Error
+		}
+
+		if data.RowsAffected > 0 {
+			return target.Session(&gorm.Session{}).Model(&models.ReactionCount{}).
+				Update("count", gorm.Expr("GREATEST(count - 1, 0)")).Error
+		}
+
+		//add the reaction, a concurrent request adding the same one wins
+		data = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+		if data.Error != nil {
+			return data.Error
+		} else if data.RowsAffected == 0 {
+			return nil
+		}
+
+		result.Reacted = true
+		counter := models.ReactionCount{TargetType: reaction.TargetType, TargetID: reaction.TargetID, Type: reaction.Type, Count: 1}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}, {Name: "type"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("reaction_counts.count + 1")}),
+		}).Create(&counter).Error
+	})
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	counts, err := getReactionCounts(db.DB, reaction.TargetType, []uuid.UUID{reaction.TargetID})
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	result.Reactions = counts[reaction.TargetID]
+	if result.Reactions == nil {
+		result.Reactions = map[string]int64{}
+	}
+
+	return &result, nil
+}
+
+// retrieve the users who reacted to the target, optionally limited to a reaction type
+func (db *reactionRepository) GetReactors(targetType string, targetID uuid.UUID, reactionType string, limit, offset int, viewer dto.Viewer) ([]dto.Reactor, int64, *dto.ErrorResponse) {
+	var reactors []dto.Reactor
+	var count int64
+
+	//check if the target exists and the user can read its post
+	if errorResponse := checkReactionTarget(db.DB, targetType, targetID, viewer); errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	query := db.Model(&models.Reaction{}).
+		Joins("JOIN users ON users.user_id = reactions.user_id AND users.deleted_at IS NULL").
+		Where("reactions.target_type=? AND reactions.target_id=?", targetType, targetID)
+	if reactionType != "" {
+		query = query.Where("reactions.type=?", reactionType)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Select("users.username, users.name, reactions.type, reactions.created_at AS reacted_at").
+		Order("reactions.created_at DESC").Limit(limit).Offset(offset).Scan(&reactors)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return reactors, count, nil
+}
+
+// check if the post or comment exists and the viewer can read the post it belongs to
+func checkReactionTarget(db *gorm.DB, targetType string, targetID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	postID := targetID
+
+	switch targetType {
+	case constants.ReactionTargetComment:
+		var comment models.Comment
+
+		data := db.Scopes(visibleComments(viewer)).Where("comment_id=? AND NOT deleted", targetID).First(&comment)
+		if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+			return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+		} else if data.Error != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+		postID = comment.PostID
+	}
+
+	return checkPostAccess(db, postID, viewer)
+}
+
+// retrieve the number of reactions of each type on the targets
+func getReactionCounts(db *gorm.DB, targetType string, targetIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	var counters []models.ReactionCount
+	counts := make(map[uuid.UUID]map[string]int64)
+
+	if len(targetIDs) == 0 {
+		return counts, nil
+	}
+
+	data := db.Where("target_type=? AND target_id IN ? AND count > 0", targetType, targetIDs).Find(&counters)
+	if data.Error != nil {
+		return nil, data.Error
+	}
+
+	for _, counter := range counters {
+		if counts[counter.TargetID] == nil {
+			counts[counter.TargetID] = make(map[string]int64)
+		}
+		counts[counter.TargetID][counter.Type] = counter.Count
+	}
+
+	return counts, nil
+}
+
+// fill the reaction counts of the posts
+func fillPostsReactions(db *gorm.DB, posts []models.Post) error {
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.PostID)
+	}
+
+	counts, err := getReactionCounts(db, constants.ReactionTargetPost, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].Reactions = counts[posts[i].PostID]
+	}
+
+	return nil
+}
+
+// fill the reaction counts of a single post
+func fillPostReactions(db *gorm.DB, post *models.Post) error {
+	counts, err := getReactionCounts(db, constants.ReactionTargetPost, []uuid.UUID{post.PostID})
+	if err != nil {
+		return err
+	}
+
+	post.Reactions = counts[post.PostID]
+	return nil
+}
+
+// fill the reaction counts of the comments
+func fillCommentsReactions(db *gorm.DB, comments []models.Comment) error {
+	var commentIDs []uuid.UUID
+	for _, comment := range comments {
+		commentIDs = append(commentIDs, comment.CommentID)
+	}
+
+	commentCounts, err := getReactionCounts(db, constants.ReactionTargetComment, commentIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range comments {
+		comments[i].Reactions = commentCounts[comments[i].CommentID]
+	}
+
+	return nil
+}
+
+// remove the reactions and counters of a deleted post or comment
+func deleteReactions(tx *gorm.DB, targetType string, targetID uuid.UUID) error {
+	if err := tx.Where("target_type=? AND target_id=?", targetType, targetID).Delete(&models.Reaction{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("target_type=? AND target_id=?", targetType, targetID).Delete(&models.ReactionCount{}).Error
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maximum number of related post lists kept in memory
+const relatedCacheSize = 1024
+
+type relatedEntry struct {
+	posts   []models.RelatedPost
+	expires time.Time
+}
+
+var (
+	relatedCache   = make(map[string]relatedEntry, relatedCacheSize)
+	relatedCacheMu sync.RWMutex
+
+	//increased whenever the cache is invalidated, lists computed before that are not stored
+	relatedGeneration uint64
+)
+
+type RelatedRepository interface {
+	GetRelatedPosts(postID uuid.UUID, limit int, viewer dto.Viewer) ([]models.RelatedPost, *dto.ErrorResponse)
+}
+
+type relatedRepository struct {
+	*gorm.DB
+}
+
+func InitRelatedRepository(db *gorm.DB) RelatedRepository {
+	return &relatedRepository{db}
+}
+
+// retrieve the published posts most similar to the post, using the shared tags, the category and the text
+//
+// the lists are cached per post for the anonymous visitors and the members, so only the published
+// posts listed for everyone or for members are recommended, even to their authors
+func (db *relatedRepository) GetRelatedPosts(postID uuid.UUID, limit int, viewer dto.Viewer) ([]models.RelatedPost, *dto.ErrorResponse) {
+	var post models.Post
+
+	data := db.Scopes(visiblePosts(viewer)).Where("post_id=?", postID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	levels := visibleLevels(viewer, constants.VisibilityPassword)
+	key := postID.String() + ":" + strings.Join(levels, ",")
+
+	related, generation, ok := cachedRelatedPosts(key)
+	if !ok {
+		var err error
+		if related, err = findRelatedPosts(db.DB, &post, levels); err != nil {
+			return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		cacheRelatedPosts(key, related, generation)
+	}
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related, nil
+}
+
+// score the published posts with the given visibility levels against the post
+func findRelatedPosts(db *gorm.DB, post *models.Post, levels []string) ([]models.RelatedPost, error) {
+	related := []models.RelatedPost{}
+
+	//the words of the title and description are matched with any of them instead of all of them
+	text := post.Title + " " + post.Description
+	condition, args := visibilityCondition(dto.Viewer{}, levels)
+
+	scores := db.Model(&models.Post{}).
+		Select(`posts.post_id, posts.title, posts.slug, posts.description, posts.created_at,
+			(SELECT COUNT(*) FROM post_tags WHERE post_tags.post_id = posts.post_id
+				AND post_tags.tag_id IN (SELECT tag_id FROM post_tags WHERE post_id = ?)) * ?::float8
+			+ CASE WHEN posts.category_id = ? THEN ?::float8 ELSE 0 END
+			+ ts_rank(posts.search_vector, replace(plainto_tsquery('english', ?)::text, '&', '|')::tsquery) * ?::float8 AS score`,
+			post.PostID, constants.RelatedTagWeight, post.CategoryID, constants.RelatedCategoryWeight, text, constants.RelatedTextWeight).
+		Where("posts.post_id <> ?", post.PostID).Where(condition, args...)
+
+	data := db.Table("(?) AS related", scores).Where("score > 0").
+		Order("score DESC, created_at DESC").Limit(constants.MaxRelatedPosts).Scan(&related)
+	if data.Error != nil {
+		return nil, data.Error
+	}
+
+	return related, nil
+}
+
+// retrieve the related posts computed earlier unless they expired, along with the current generation of the cache
+func cachedRelatedPosts(key string) ([]models.RelatedPost, uint64, bool) {
+	relatedCacheMu.RLock()
+	defer relatedCacheMu.RUnlock()
+
+	entry, ok := relatedCache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, relatedGeneration, false
+	}
+
+	return entry.posts, relatedGeneration, true
+}
+
+// keep the related posts until they expire or a post changes
+func cacheRelatedPosts(key string, posts []models.RelatedPost, generation uint64) {
+	ttl := time.Duration(helpers.EnvInt64("RELATED_CACHE_TTL", int64(constants.DefaultRelatedCacheTTL))) * time.Second
+
+	relatedCacheMu.Lock()
+	defer relatedCacheMu.Unlock()
+
+	//a post changed while the list was computed
+	if generation != relatedGeneration {
+		return
+	}
+
+	//drop the whole cache instead of tracking the usage of every entry
+	if len(relatedCache) >= relatedCacheSize {
+		relatedCache = make(map[string]relatedEntry, relatedCacheSize)
+	}
+	relatedCache[key] = relatedEntry{posts: posts, expires: time.Now().Add(ttl)}
+}
+
+// forget every cached list, a changed post can move up or down in the lists of any other post
+func invalidateRelatedPosts() {
+	relatedCacheMu.Lock()
+	relatedCache = make(map[string]relatedEntry, relatedCacheSize)
+	relatedGeneration++
+	relatedCacheMu.Unlock()
+}
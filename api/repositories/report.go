@@ -0,0 +1,368 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	CreateReport(report *models.Report, viewer dto.Viewer) *dto.ErrorResponse
+	GetReportGroups(targetType string, limit, offset int) (*[]dto.ReportGroup, int64, *dto.ErrorResponse)
+	GetReports(targetType string, targetID uuid.UUID) (*[]models.Report, *dto.ErrorResponse)
+	ResolveReports(targetType string, targetID uuid.UUID, resolution *dto.ReportResolution, moderatorID uuid.UUID) (*models.AuditLog, *dto.ErrorResponse)
+	GetAuditLogs(targetType string, targetID uuid.UUID, limit, offset int) (*[]models.AuditLog, int64, *dto.ErrorResponse)
+}
+
+type reportRepository struct {
+	*gorm.DB
+}
+
+func InitReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db}
+}
+
+// number of distinct users whose open reports hide the content until a moderator looks at it
+func reportHideThreshold() int64 {
+	return max(helpers.EnvInt64("REPORT_HIDE_THRESHOLD", int64(constants.DefaultReportHideThreshold)), 1)
+}
+
+// report a post or comment the user can see, the content is hidden once enough users reported it
+func (db *reportRepository) CreateReport(report *models.Report, viewer dto.Viewer) *dto.ErrorResponse {
+	var errorResponse *dto.ErrorResponse
+	var hidden bool
+
+	//the content is checked like the content the users react to
+	if errorResponse := checkReactionTarget(db.DB, report.TargetType, report.TargetID, viewer); errorResponse != nil {
+		return errorResponse
+	}
+
+	report.ReporterID = viewer.UserID
+	report.Status = constants.ReportStatusOpen
+	report.ResolvedBy = nil
+	report.ResolvedAt = nil
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var reported, reporters int64
+
+		//a reporter only counts once until the reports are resolved
+		open := tx.Model(&models.Report{}).Where("target_type=? AND target_id=? AND status=?", report.TargetType, report.TargetID, constants.ReportStatusOpen)
+
+		data := open.Session(&gorm.Session{}).Where("reporter_id=?", report.ReporterID).Count(&reported)
+		if data.Error != nil {
+			return data.Error
+		} else if reported > 0 {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusConflict, Error: "content was already reported"}
+			return nil
+		}
+
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+
+		data = open.Session(&gorm.Session{}).Distinct("reporter_id").Count(&reporters)
+		if data.Error != nil {
+			return data.Error
+		} else if reporters < reportHideThreshold() {
+			return nil
+		}
+
+		//the content is hidden once, by the first report reaching the threshold, even when concurrent reports skip past it
+		var err error
+		if hidden, err = hideReportedContent(tx, report.TargetType, report.TargetID); err != nil || !hidden {
+			return err
+		}
+
+		return tx.Create(&models.AuditLog{Action: constants.AuditActionHide, TargetType: report.TargetType, TargetID: report.TargetID,
+			Details: fmt.Sprintf("hidden after being reported by %d users", reporters)}).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if errorResponse != nil {
+		return errorResponse
+	}
+
+	//hidden posts leave the related posts of the others
+	if hidden && report.TargetType == constants.ReactionTargetPost {
+		invalidateRelatedPosts()
+	}
+
+	return nil
+}
+
+// retrieve the open reports grouped by the content they report, the most reported first
+func (db *reportRepository) GetReportGroups(targetType string, limit, offset int) (*[]dto.ReportGroup, int64, *dto.ErrorResponse) {
+	var groups []dto.ReportGroup
+	var count int64
+	var reasons []struct {
+		TargetType string
+		TargetID   uuid.UUID
+		Reason     string
+		Count      int64
+	}
+
+	open := func() *gorm.DB {
+		query := db.Model(&models.Report{}).Where("status=?", constants.ReportStatusOpen)
+		if targetType != "" {
+			query = query.Where("target_type=?", targetType)
+		}
+		return query
+	}
+
+	data := db.Table("(?) AS targets", open().Select("target_type, target_id").Group("target_type, target_id")).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = open().Select(`target_type, target_id, COUNT(DISTINCT reporter_id) AS reporters,
+		MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at`).
+		Group("target_type, target_id").Order("reporters DESC, first_reported_at").Limit(limit).Offset(offset).Scan(&groups)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if len(groups) == 0 {
+		return &groups, count, nil
+	}
+
+	targetIDs := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		targetIDs = append(targetIDs, group.TargetID)
+	}
+
+	data = open().Select("target_type, target_id, reason, COUNT(*) AS count").Where("target_id IN ?", targetIDs).
+		Group("target_type, target_id, reason").Scan(&reasons)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	for i := range groups {
+		groups[i].Reasons = make(map[string]int64)
+		for _, reason := range reasons {
+			if reason.TargetType == groups[i].TargetType && reason.TargetID == groups[i].TargetID {
+				groups[i].Reasons[reason.Reason] = reason.Count
+			}
+		}
+	}
+
+	return &groups, count, nil
+}
+
+// retrieve every report of a post or comment, the latest first
+func (db *reportRepository) GetReports(targetType string, targetID uuid.UUID) (*[]models.Report, *dto.ErrorResponse) {
+	var reports []models.Report
+
+	data := db.Where("target_type=? AND target_id=?", targetType, targetID).Order("created_at DESC").Find(&reports)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if len(reports) == 0 {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "content was not reported"}
+	}
+
+	return &reports, nil
+}
+
+// close the open reports of a post or comment with the action of the moderator, the action is recorded in the audit log
+//
+// dismissing shows the hidden content again, every other action removes it, and warning or suspending also
+// notifies its author
+func (db *reportRepository) ResolveReports(targetType string, targetID uuid.UUID, resolution *dto.ReportResolution, moderatorID uuid.UUID) (*models.AuditLog, *dto.ErrorResponse) {
+	var errorResponse *dto.ErrorResponse
+	audit := models.AuditLog{ActorID: &moderatorID, Action: resolution.Action, TargetType: targetType, TargetID: targetID, Details: resolution.Note}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		status := constants.ReportStatusResolved
+		if resolution.Action == constants.ReportActionDismiss {
+			status = constants.ReportStatusDismissed
+		}
+
+		data := tx.Model(&models.Report{}).Where("target_type=? AND target_id=? AND status=?", targetType, targetID, constants.ReportStatusOpen).
+			Updates(map[string]interface{}{"status": status, "resolved_by": moderatorID, "resolved_at": now})
+		if data.Error != nil {
+			return data.Error
+		} else if data.RowsAffected == 0 {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusNotFound, Error: "content has no open reports"}
+			return nil
+		}
+
+		if resolution.Action == constants.ReportActionDismiss {
+			if err := showReportedContent(tx, targetType, targetID); err != nil {
+				return err
+			}
+			return tx.Create(&audit).Error
+		}
+
+		if err := removeReportedContent(tx, targetType, targetID); err != nil {
+			return err
+		}
+
+		switch resolution.Action {
+		case constants.ReportActionWarn:
+			if err := warnAuthor(tx, targetType, targetID, "a moderator removed your "+targetType, resolution.Note); err != nil {
+				return err
+			}
+		case constants.ReportActionSuspend:
+			authorID, err := reportedAuthor(tx, targetType, targetID)
+			if err != nil {
+				return err
+			}
+
+			until := now.AddDate(0, 0, resolution.SuspendDays)
+			if err := tx.Model(&models.User{}).Where("user_id=?", authorID).UpdateColumn("suspended_until", until).Error; err != nil {
+				return err
+			}
+
+			message := fmt.Sprintf("a moderator removed your %s and suspended your account until %s", targetType, until.Format(time.RFC3339))
+			if err := warnAuthor(tx, targetType, targetID, message, resolution.Note); err != nil {
+				return err
+			}
+			audit.Details = fmt.Sprintf("suspended %s until %s", authorID, until.Format(time.RFC3339))
+			if resolution.Note != "" {
+				audit.Details += ": " + resolution.Note
+			}
+		}
+
+		return tx.Create(&audit).Error
+	})
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	//the post was either shown again or removed
+	if targetType == constants.ReactionTargetPost {
+		invalidateRelatedPosts()
+	}
+
+	return &audit, nil
+}
+
+// retrieve the audit log the latest first, optionally only the actions on a post or comment
+func (db *reportRepository) GetAuditLogs(targetType string, targetID uuid.UUID, limit, offset int) (*[]models.AuditLog, int64, *dto.ErrorResponse) {
+	var logs []models.AuditLog
+	var count int64
+
+	query := db.Model(&models.AuditLog{})
+	if targetType != "" {
+		query = query.Where("target_type=?", targetType)
+	}
+	if targetID != uuid.Nil {
+		query = query.Where("target_id=?", targetID)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &logs, count, nil
+}
+
+// hide the reported content from everyone except its authors and the moderators, the comments wait for moderation again
+//
+// content which is already hidden is left as it is, false is returned when nothing was hidden
+func hideReportedContent(db *gorm.DB, targetType string, targetID uuid.UUID) (bool, error) {
+	if targetType == constants.ReactionTargetComment {
+		data := db.Model(&models.Comment{}).Where("comment_id=? AND status=?", targetID, constants.CommentStatusApproved).
+			UpdateColumns(map[string]interface{}{"status": constants.CommentStatusPending, "hold_reason": constants.HoldReasonReported})
+		return data.RowsAffected > 0, data.Error
+	}
+
+	data := db.Model(&models.Post{}).Where("post_id=? AND hidden_at IS NULL", targetID).
+		UpdateColumns(map[string]interface{}{"hidden_at": time.Now(), "hidden_reason": constants.HoldReasonReported})
+	return data.RowsAffected > 0, data.Error
+}
+
+// show the content hidden by the reports again, the content hidden for other reasons stays hidden
+func showReportedContent(db *gorm.DB, targetType string, targetID uuid.UUID) error {
+	if targetType == constants.ReactionTargetComment {
+		return db.Model(&models.Comment{}).Where("comment_id=? AND status=? AND hold_reason=?", targetID, constants.CommentStatusPending, constants.HoldReasonReported).
+			UpdateColumns(map[string]interface{}{"status": constants.CommentStatusApproved, "hold_reason": ""}).Error
+	}
+
+	return db.Model(&models.Post{}).Where("post_id=? AND hidden_reason=?", targetID, constants.HoldReasonReported).
+		UpdateColumns(map[string]interface{}{"hidden_at": nil, "hidden_reason": ""}).Error
+}
+
+// remove the reported content, the comments are rejected and the posts stay hidden from everyone except their authors
+func removeReportedContent(db *gorm.DB, targetType string, targetID uuid.UUID) error {
+	if targetType == constants.ReactionTargetComment {
+		return db.Model(&models.Comment{}).Where("comment_id=?", targetID).
+			UpdateColumns(map[string]interface{}{"status": constants.CommentStatusRejected, "hold_reason": "", "moderated_at": time.Now()}).Error
+	}
+
+	//removed posts are not shown again by a later review of the spam checks
+	return db.Model(&models.Post{}).Where("post_id=?", targetID).
+		UpdateColumns(map[string]interface{}{"hidden_at": gorm.Expr("COALESCE(hidden_at, ?)", time.Now()), "hidden_reason": constants.HoldReasonRemoved}).Error
+}
+
+// user who wrote the reported content, the owner for the posts
+func reportedAuthor(db *gorm.DB, targetType string, targetID uuid.UUID) (uuid.UUID, error) {
+	var authorID uuid.UUID
+
+	query := db.Unscoped().Model(&models.Post{}).Select("user_id").Where("post_id=?", targetID)
+	if targetType == constants.ReactionTargetComment {
+		query = db.Unscoped().Model(&models.Comment{}).Select("user_id").Where("comment_id=?", targetID)
+	}
+
+	data := query.Scan(&authorID)
+	if data.Error != nil {
+		return uuid.Nil, data.Error
+	} else if authorID == uuid.Nil {
+		return uuid.Nil, errors.New("the author of the content does not exist")
+	}
+
+	return authorID, nil
+}
+
+// let the author of the reported content know what the moderator did
+func warnAuthor(db *gorm.DB, targetType string, targetID uuid.UUID, message string, note string) error {
+	authorID, err := reportedAuthor(db, targetType, targetID)
+	if err != nil {
+		return err
+	}
+
+	if note != "" {
+		message += ": " + note
+	}
+
+	notification := models.Notification{UserID: authorID, Type: constants.NotificationWarning, Message: message}
+	if targetType == constants.ReactionTargetComment {
+		notification.CommentID = &targetID
+	} else {
+		notification.PostID = &targetID
+	}
+
+	return db.Create(&notification).Error
+}
+
+// check the user is not suspended before they write
+func checkSuspended(db *gorm.DB, userID uuid.UUID) *dto.ErrorResponse {
+	var user models.User
+
+	data := db.Select("suspended_until").Where("user_id=?", userID).Limit(1).Find(&user)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "account is suspended until " + user.SuspendedUntil.Format(time.RFC3339)}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// markers placed around the matched words by ts_headline, replaced after escaping the snippet
+const (
+	headlineStart   = "[[mark]]"
+	headlineStop    = "[[/mark]]"
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxFragments=2, MaxWords=30, MinWords=10"
+)
+
+type SearchRepository interface {
+	Search(query dto.SearchQuery) (*dto.SearchResponse, int64, *dto.ErrorResponse)
+}
+
+type searchRepository struct {
+	*gorm.DB
+}
+
+func InitSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db}
+}
+
+// search the posts and optionally the comments ranked by relevance
+func (db *searchRepository) Search(query dto.SearchQuery) (*dto.SearchResponse, int64, *dto.ErrorResponse) {
+	var response dto.SearchResponse
+	var count int64
+
+	tsquery, tsqueryArgs := buildTSQuery(query.Query)
+	if tsquery == "" {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "search query cannot be empty"}
+	}
+
+	matches, matchesArgs := searchMatches(query)
+	with := "WITH q AS (SELECT " + tsquery + " AS query) "
+
+	var args []interface{}
+	args = append(args, tsqueryArgs...)
+	args = append(args, matchesArgs...)
+
+	var pageArgs []interface{}
+	pageArgs = append(pageArgs, tsqueryArgs...)
+	pageArgs = append(pageArgs, headlineOptions)
+	pageArgs = append(pageArgs, matchesArgs...)
+	pageArgs = append(pageArgs, query.Limit, query.Offset)
+
+	//count every match before paginating
+	data := db.Raw(with+"SELECT COUNT(*) FROM ("+matches+") matches", args...).Scan(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	//highlight only the rows of the requested page
+	data = db.Raw(with+`SELECT matches.type, matches.id, matches.post_id, matches.title, matches.slug, matches.rank,
+		matches.category_id, matches.user_id, matches.created_at,
+		ts_headline('english', matches.document, q.query, ?) AS snippet
+		FROM (`+matches+`) matches, q ORDER BY matches.rank DESC, matches.created_at DESC LIMIT ? OFFSET ?`, pageArgs...).
+		Scan(&response.Results)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	for i := range response.Results {
+		response.Results[i].Snippet = highlight(response.Results[i].Snippet)
+	}
+
+	//facets by category and author
+	data = db.Raw(with+`SELECT categories.category_id AS id, categories.category_name AS name, COUNT(*) AS count
+		FROM (`+matches+`) matches JOIN categories ON categories.category_id = matches.category_id
+		GROUP BY categories.category_id, categories.category_name ORDER BY count DESC LIMIT 10`, args...).
+		Scan(&response.Categories)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Raw(with+`SELECT users.user_id AS id, users.username AS name, COUNT(*) AS count
+		FROM (`+matches+`) matches JOIN users ON users.user_id = matches.user_id
+		GROUP BY users.user_id, users.username ORDER BY count DESC LIMIT 10`, args...).
+		Scan(&response.Authors)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &response, count, nil
+}
+
+// builds the tsquery expression for the search text along with its arguments
+func buildTSQuery(text string) (string, []interface{}) {
+	websearch, prefix := helpers.ParseSearchQuery(text)
+
+	switch {
+	case websearch != "" && prefix != "":
+		return "websearch_to_tsquery('english', ?) && to_tsquery('english', ?)", []interface{}{websearch, prefix}
+	case websearch != "":
+		return "websearch_to_tsquery('english', ?)", []interface{}{websearch}
+	case prefix != "":
+		return "to_tsquery('english', ?)", []interface{}{prefix}
+	default:
+		return "", nil
+	}
+}
+
+// builds the query selecting every matching post and comment using the q.query tsquery
+func searchMatches(query dto.SearchQuery) (string, []interface{}) {
+	var selects []string
+	var args []interface{}
+
+	//password protected and unlisted posts are left out for everyone but their authors
+	visibility, visibilityArgs := visibilityCondition(query.Viewer, visibleLevels(query.Viewer))
+
+	filters := func(where string) string {
+		where += " AND " + visibility
+		args = append(args, visibilityArgs...)
+
+		if query.CategoryID != uuid.Nil {
+			where += " AND posts.category_id = ?"
+			args = append(args, query.CategoryID)
+		}
+
+		return where
+	}
+
+	if query.Type != constants.SearchTypeComments {
+		where := filters("posts.deleted_at IS NULL AND posts.search_vector @@ q.query")
+		if query.UserID != uuid.Nil {
+			where += " AND posts.user_id = ?"
+			args = append(args, query.UserID)
+		}
+
+		selects = append(selects, `SELECT 'post' AS type, posts.post_id AS id, posts.post_id, posts.title, posts.slug,
+			ts_rank_cd(posts.search_vector, q.query) AS rank, posts.category_id, posts.user_id, posts.created_at,
+			posts.content AS document FROM posts, q WHERE `+where)
+	}
+
+	if query.Type == constants.SearchTypeComments || query.Type == constants.SearchTypeAll {
+		where := filters("comments.deleted_at IS NULL AND comments.status = '" + constants.CommentStatusApproved +
+			"' AND posts.deleted_at IS NULL AND comments.search_vector @@ q.query")
+		if query.UserID != uuid.Nil {
+			where += " AND comments.user_id = ?"
+			args = append(args, query.UserID)
+		}
+
+		selects = append(selects, `SELECT 'comment' AS type, comments.comment_id AS id, posts.post_id, posts.title, posts.slug,
+			ts_rank_cd(comments.search_vector, q.query) AS rank, posts.category_id, comments.user_id, comments.created_at,
+			comments.content AS document FROM comments JOIN posts ON posts.post_id = comments.post_id, q WHERE `+where)
+	}
+
+	return strings.Join(selects, " UNION ALL "), args
+}
+
+// escapes the snippet and turns the ts_headline markers into mark tags
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, headlineStart, "<mark>")
+
+	return strings.ReplaceAll(snippet, headlineStop, "</mark>")
+}
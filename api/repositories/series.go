@@ -0,0 +1,370 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeriesRepository interface {
+	CreateSeries(series *models.Series) *dto.ErrorResponse
+	GetSeriesList(userID uuid.UUID, limit, offset int) (*[]models.Series, int64, *dto.ErrorResponse)
+	GetSeries(seriesID uuid.UUID, viewer dto.Viewer) (*models.Series, *dto.ErrorResponse)
+	UpdateSeries(series *models.Series, seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	DeleteSeries(seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	AddPart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) (*models.SeriesPost, *dto.ErrorResponse)
+	ReorderParts(seriesID uuid.UUID, postIDs []uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	RemovePart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+}
+
+type seriesRepository struct {
+	*gorm.DB
+}
+
+func InitSeriesRepository(db *gorm.DB) SeriesRepository {
+	return &seriesRepository{db}
+}
+
+// errors returned from the transactions which are turned into responses
+var (
+	errSeriesNotFound  = errors.New("series not found")
+	errSeriesForbidden = errors.New("cannot change other users series")
+)
+
+// create a new series
+func (db *seriesRepository) CreateSeries(series *models.Series) *dto.ErrorResponse {
+	data := db.Create(series)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return nil
+}
+
+// retrieve the series of the user along with the number of posts in them
+func (db *seriesRepository) GetSeriesList(userID uuid.UUID, limit, offset int) (*[]models.Series, int64, *dto.ErrorResponse) {
+	var series []models.Series
+	var count int64
+
+	data := db.Model(&models.Series{}).Where("user_id=?", userID).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Model(&models.Series{}).Select("series.*, COUNT(series_posts.post_id) AS part_count").
+		Joins("LEFT JOIN series_posts ON series_posts.series_id = series.series_id").
+		Where("series.user_id=?", userID).Group("series.series_id").
+		Order("series.created_at DESC").Limit(limit).Offset(offset).Find(&series)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &series, count, nil
+}
+
+// retrieve a series along with the parts the viewer can see in order
+func (db *seriesRepository) GetSeries(seriesID uuid.UUID, viewer dto.Viewer) (*models.Series, *dto.ErrorResponse) {
+	var series models.Series
+
+	data := db.Where("series_id=?", seriesID).First(&series)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "series not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	parts, err := getSeriesParts(db.DB, seriesID, viewer)
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	series.Parts = parts
+	series.PartCount = int64(len(parts))
+
+	return &series, nil
+}
+
+// update the title and description of a series
+func (db *seriesRepository) UpdateSeries(series *models.Series, seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	if _, err := findOwnSeries(db.DB, seriesID, viewer); err != nil {
+		return seriesError(err)
+	}
+
+	data := db.Model(&models.Series{}).Where("series_id=?", seriesID).Updates(map[string]interface{}{
+		"title":       series.Title,
+		"description": series.Description,
+	})
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return nil
+}
+
+// delete a series, its posts are kept
+func (db *seriesRepository) DeleteSeries(seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findOwnSeries(tx, seriesID, viewer); err != nil {
+			return err
+		}
+
+		if err := tx.Where("series_id=?", seriesID).Delete(&models.SeriesPost{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("series_id=?", seriesID).Delete(&models.Series{}).Error
+	})
+	if err != nil {
+		return seriesError(err)
+	}
+
+	return nil
+}
+
+// add a post written by the user at the end of the series
+func (db *seriesRepository) AddPart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) (*models.SeriesPost, *dto.ErrorResponse) {
+	var errorResponse *dto.ErrorResponse
+	part := models.SeriesPost{SeriesID: seriesID, PostID: postID}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findOwnSeries(tx, seriesID, viewer); err != nil {
+			return err
+		}
+
+		//only the authors who can edit the post can add it to a series
+		role, err := authorRole(tx, postID, viewer.UserID)
+		if err != nil {
+			return err
+		} else if role != constants.AuthorRoleOwner && role != constants.AuthorRoleCoAuthor {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusForbidden, Error: "only the authors of the post can add it to a series"}
+			return nil
+		}
+
+		data := tx.Model(&models.SeriesPost{}).Select("COALESCE(MAX(position), 0) + 1").Where("series_id=?", seriesID).Scan(&part.Position)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		data = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&part)
+		if data.Error != nil {
+			return data.Error
+		} else if data.RowsAffected == 0 {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusConflict, Error: "post already belongs to a series"}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, seriesError(err)
+	} else if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	return &part, nil
+}
+
+// change the order of the posts in the series, every post of the series has to be given
+func (db *seriesRepository) ReorderParts(seriesID uuid.UUID, postIDs []uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	var errorResponse *dto.ErrorResponse
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var current []uuid.UUID
+
+		if _, err := findOwnSeries(tx, seriesID, viewer); err != nil {
+			return err
+		}
+
+		data := tx.Model(&models.SeriesPost{}).Where("series_id=?", seriesID).Pluck("post_id", &current)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		//the new order has to contain the same posts exactly once
+		parts := make(map[uuid.UUID]bool, len(current))
+		for _, postID := range current {
+			parts[postID] = true
+		}
+
+		if len(postIDs) != len(current) {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "the order has to contain every post of the series"}
+			return nil
+		}
+
+		for _, postID := range postIDs {
+			if !parts[postID] {
+				errorResponse = &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "the order has to contain every post of the series exactly once"}
+				return nil
+			}
+			delete(parts, postID)
+		}
+
+		for i, postID := range postIDs {
+			data = tx.Model(&models.SeriesPost{}).Where("series_id=? AND post_id=?", seriesID, postID).Update("position", i+1)
+			if data.Error != nil {
+				return data.Error
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return seriesError(err)
+	}
+
+	return errorResponse
+}
+
+// remove a post from the series and move the posts after it up
+func (db *seriesRepository) RemovePart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	var errorResponse *dto.ErrorResponse
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findOwnSeries(tx, seriesID, viewer); err != nil {
+			return err
+		}
+
+		removed, err := removeSeriesPost(tx, seriesID, postID)
+		if err != nil {
+			return err
+		} else if !removed {
+			errorResponse = &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post is not part of the series"}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return seriesError(err)
+	}
+
+	return errorResponse
+}
+
+// retrieve the series and lock it until the transaction ends, only its owner and the admin can change it
+func findOwnSeries(tx *gorm.DB, seriesID uuid.UUID, viewer dto.Viewer) (*models.Series, error) {
+	var series models.Series
+
+	data := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("series_id=?", seriesID).First(&series)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, errSeriesNotFound
+	} else if data.Error != nil {
+		return nil, data.Error
+	}
+
+	if series.UserID != viewer.UserID && viewer.Role != constants.AdminRole {
+		return nil, errSeriesForbidden
+	}
+
+	return &series, nil
+}
+
+// turn the errors of the series transactions into responses
+func seriesError(err error) *dto.ErrorResponse {
+	switch {
+	case errors.Is(err, errSeriesNotFound):
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: err.Error()}
+	case errors.Is(err, errSeriesForbidden):
+		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: err.Error()}
+	default:
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+}
+
+// remove the post from the series, the positions of the posts after it move up by one
+func removeSeriesPost(tx *gorm.DB, seriesID uuid.UUID, postID uuid.UUID) (bool, error) {
+	var part models.SeriesPost
+
+	data := tx.Where("series_id=? AND post_id=?", seriesID, postID).Limit(1).Find(&part)
+	if data.Error != nil || data.RowsAffected == 0 {
+		return false, data.Error
+	}
+
+	if err := tx.Where("series_id=? AND post_id=?", seriesID, postID).Delete(&models.SeriesPost{}).Error; err != nil {
+		return false, err
+	}
+
+	data = tx.Model(&models.SeriesPost{}).Where("series_id=? AND position > ?", seriesID, part.Position).
+		Update("position", gorm.Expr("position - 1"))
+	if data.Error != nil {
+		return false, data.Error
+	}
+
+	return true, nil
+}
+
+// remove the post from the series it belongs to, if any
+func removePostFromSeries(tx *gorm.DB, postID uuid.UUID) error {
+	var part models.SeriesPost
+
+	data := tx.Where("post_id=?", postID).Limit(1).Find(&part)
+	if data.Error != nil || data.RowsAffected == 0 {
+		return data.Error
+	}
+
+	_, err := removeSeriesPost(tx, part.SeriesID, postID)
+	return err
+}
+
+// retrieve the posts of the series the viewer can see, numbered in order
+func getSeriesParts(db *gorm.DB, seriesID uuid.UUID, viewer dto.Viewer) ([]models.SeriesPart, error) {
+	var parts []models.SeriesPart
+
+	condition, args := visibilityCondition(viewer, visibleLevels(viewer, constants.VisibilityPassword, constants.VisibilityUnlisted))
+	data := db.Table("series_posts").Select("posts.post_id, posts.title, posts.slug, posts.description").
+		Joins("JOIN posts ON posts.post_id = series_posts.post_id AND posts.deleted_at IS NULL").
+		Where("series_posts.series_id=?", seriesID).Where(condition, args...).
+		Order("series_posts.position").Scan(&parts)
+	if data.Error != nil {
+		return nil, data.Error
+	}
+
+	//number the parts without the gaps left by the posts the viewer cannot see
+	for i := range parts {
+		parts[i].Position = i + 1
+	}
+
+	return parts, nil
+}
+
+// fill the series the post belongs to along with the parts before and after it
+func fillPostSeries(db *gorm.DB, post *models.Post, viewer dto.Viewer) error {
+	var part models.SeriesPost
+	var series models.Series
+
+	data := db.Where("post_id=?", post.PostID).Limit(1).Find(&part)
+	if data.Error != nil || data.RowsAffected == 0 {
+		return data.Error
+	}
+
+	data = db.Where("series_id=?", part.SeriesID).First(&series)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	parts, err := getSeriesParts(db, part.SeriesID, viewer)
+	if err != nil {
+		return err
+	}
+
+	for i := range parts {
+		if parts[i].PostID != post.PostID {
+			continue
+		}
+
+		navigation := models.SeriesNavigation{SeriesID: series.SeriesID, Title: series.Title, Position: parts[i].Position, Total: len(parts)}
+		if i > 0 {
+			navigation.Previous = &parts[i-1]
+		}
+		if i < len(parts)-1 {
+			navigation.Next = &parts[i+1]
+		}
+		post.Series = &navigation
+	}
+
+	return nil
+}
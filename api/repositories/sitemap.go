@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+type SitemapRepository interface {
+	CountPosts() (int64, *dto.ErrorResponse)
+	GetPosts(limit, offset int) ([]dto.SitemapEntry, *dto.ErrorResponse)
+	GetPostPages(pageSize int) ([]dto.SitemapPage, *dto.ErrorResponse)
+	GetCategories() ([]dto.SitemapEntry, *dto.ErrorResponse)
+}
+
+type sitemapRepository struct {
+	*gorm.DB
+}
+
+func InitSitemapRepository(db *gorm.DB) SitemapRepository {
+	return &sitemapRepository{db}
+}
+
+// limits the query to the published public posts search engines may index
+func indexablePosts(db *gorm.DB) *gorm.DB {
+	return db.Scopes(publishedPosts).Where("posts.visibility = ? AND posts.no_index = ?", constants.VisibilityPublic, false)
+}
+
+// count the posts listed in the sitemap
+func (db *sitemapRepository) CountPosts() (int64, *dto.ErrorResponse) {
+	var count int64
+
+	data := db.Model(&models.Post{}).Scopes(indexablePosts).Count(&count)
+	if data.Error != nil {
+		return 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return count, nil
+}
+
+// retrieve a page of the posts listed in the sitemap
+func (db *sitemapRepository) GetPosts(limit, offset int) ([]dto.SitemapEntry, *dto.ErrorResponse) {
+	var entries []dto.SitemapEntry
+
+	data := db.Model(&models.Post{}).Scopes(indexablePosts).
+		Select("slug, canonical_url, updated_at").
+		Order("created_at, post_id").Limit(limit).Offset(offset).Scan(&entries)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return entries, nil
+}
+
+// retrieve the last modification date of every page of the post sitemaps
+func (db *sitemapRepository) GetPostPages(pageSize int) ([]dto.SitemapPage, *dto.ErrorResponse) {
+	var pages []dto.SitemapPage
+
+	posts := db.Model(&models.Post{}).Scopes(indexablePosts).
+		Select("(ROW_NUMBER() OVER (ORDER BY created_at, post_id) - 1) / ? AS page, updated_at", pageSize)
+
+	data := db.Table("(?) AS pages", posts).
+		Select("page, MAX(updated_at) AS updated_at").
+		Group("page").Order("page").Scan(&pages)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return pages, nil
+}
+
+// retrieve every category along with the date its latest post was updated
+func (db *sitemapRepository) GetCategories() ([]dto.SitemapEntry, *dto.ErrorResponse) {
+	var entries []dto.SitemapEntry
+
+	data := db.Model(&models.Category{}).
+		Select("categories.slug, GREATEST(categories.updated_at, MAX(posts.updated_at)) AS updated_at").
+		Joins("LEFT JOIN posts ON posts.category_id = categories.category_id AND posts.deleted_at IS NULL AND posts.hidden_at IS NULL AND posts.status = ? AND posts.visibility = ? AND posts.no_index = ?", constants.PostStatusPublished, constants.VisibilityPublic, false).
+		Group("categories.category_id").Order("categories.category_name").Scan(&entries)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// generates a slug for the entity which is not used by any other post or category
+func generateSlug(db *gorm.DB, entityType string, text string, entityID uuid.UUID) (string, error) {
+	var err error
+
+	table, idColumn := "posts", "post_id"
+	if entityType == constants.SlugEntityCategory {
+		table, idColumn = "categories", "category_id"
+	}
+
+	slug := helpers.UniqueSlug(helpers.Slugify(text), func(candidate string) bool {
+		var count int64
+
+		if err != nil {
+			return false
+		}
+
+		//check the current slugs including the soft deleted rows
+		data := db.Unscoped().Table(table).Where("slug=? AND "+idColumn+"<>?", candidate, entityID).Count(&count)
+		if data.Error != nil {
+			err = data.Error
+			return false
+		} else if count > 0 {
+			return true
+		}
+
+		//old slugs of other entities keep redirecting, so they cannot be reused
+		data = db.Model(&models.SlugHistory{}).Where("entity_type=? AND slug=? AND entity_id<>?", entityType, candidate, entityID).Count(&count)
+		if data.Error != nil {
+			err = data.Error
+			return false
+		}
+
+		return count > 0
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return slug, nil
+}
+
+// generates a slug and saves the entity with it, when another request stored the same slug in the meantime the slug is
+// generated again, the save runs in a savepoint so the transaction can go on after the conflict
+func saveWithSlug(tx *gorm.DB, entityType string, text string, entityID uuid.UUID, save func(tx *gorm.DB, slug string) error) error {
+	var err error
+
+	for attempt := 0; attempt < constants.SlugSaveAttempts; attempt++ {
+		var slug string
+
+		slug, err = generateSlug(tx, entityType, text, entityID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Transaction(func(tx *gorm.DB) error {
+			return save(tx, slug)
+		})
+		if !slugConflict(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// check if the error is a unique violation on a slug
+func slugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, "slug")
+}
+
+// stores the old slug of the entity so that old links still resolve
+func recordSlugHistory(tx *gorm.DB, entityType string, entityID uuid.UUID, oldSlug string, newSlug string) error {
+	//the new slug might be an old slug of the same entity
+	data := tx.Where("entity_type=? AND slug=?", entityType, newSlug).Delete(&models.SlugHistory{})
+	if data.Error != nil {
+		return data.Error
+	}
+
+	if oldSlug == "" {
+		return nil
+	}
+
+	return tx.Create(&models.SlugHistory{EntityType: entityType, EntityID: entityID, Slug: oldSlug}).Error
+}
+
+// find the entity id an old slug used to point to
+func findSlugHistory(db *gorm.DB, entityType string, slug string) (*models.SlugHistory, error) {
+	var history models.SlugHistory
+
+	data := db.Where("entity_type=? AND slug=?", entityType, slug).First(&history)
+	if data.Error != nil {
+		return nil, data.Error
+	}
+
+	return &history, nil
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// scores the written content, it is only called once the user was allowed to write it and returns nil when the
+// content is not checked
+type SpamCheck func() *spam.Verdict
+
+type SpamRepository interface {
+	spam.History
+	GetSpamChecks(decision, targetType, review string, limit, offset int) (*[]models.SpamCheck, int64, *dto.ErrorResponse)
+	ReviewSpamCheck(checkID uuid.UUID, review string, moderatorID uuid.UUID) (*models.SpamCheck, *dto.ErrorResponse)
+}
+
+type spamRepository struct {
+	*gorm.DB
+}
+
+func InitSpamRepository(db *gorm.DB) SpamRepository {
+	return &spamRepository{db}
+}
+
+// count the checked contents with the same hash since the given time
+func (db *spamRepository) CountDuplicates(ctx context.Context, hash string, since time.Time) (int64, error) {
+	var count int64
+
+	err := db.WithContext(ctx).Model(&models.SpamCheck{}).Where("content_hash=? AND created_at >= ?", hash, since).Count(&count).Error
+	return count, err
+}
+
+// count the contents the author wrote since the given time
+func (db *spamRepository) CountRecent(ctx context.Context, authorID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+
+	err := db.WithContext(ctx).Model(&models.SpamCheck{}).Where("user_id=? AND created_at >= ?", authorID, since).Count(&count).Error
+	return count, err
+}
+
+// retrieve when the account of the author was created
+func (db *spamRepository) AccountCreatedAt(ctx context.Context, authorID uuid.UUID) (time.Time, error) {
+	var user models.User
+
+	err := db.WithContext(ctx).Select("created_at").Where("user_id=?", authorID).First(&user).Error
+	return user.CreatedAt, err
+}
+
+// retrieve the spam checks the latest first, optionally only those with the given decision, type or review
+func (db *spamRepository) GetSpamChecks(decision, targetType, review string, limit, offset int) (*[]models.SpamCheck, int64, *dto.ErrorResponse) {
+	var checks []models.SpamCheck
+	var count int64
+
+	query := db.Model(&models.SpamCheck{})
+	if decision != "" {
+		query = query.Where("decision=?", decision)
+	}
+	if targetType != "" {
+		query = query.Where("target_type=?", targetType)
+	}
+	if review == "none" {
+		query = query.Where("review=''")
+	} else if review != "" {
+		query = query.Where("review=?", review)
+	}
+
+	data := query.Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&checks)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &checks, count, nil
+}
+
+// record whether the checked content was spam, held content marked as ham is shown and content marked as spam stays hidden
+func (db *spamRepository) ReviewSpamCheck(checkID uuid.UUID, review string, moderatorID uuid.UUID) (*models.SpamCheck, *dto.ErrorResponse) {
+	var check models.SpamCheck
+
+	data := db.Where("check_id=?", checkID).First(&check)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "spam check not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if check.TargetID != nil && check.Decision != spam.DecisionAllow {
+			if err := reviewSpamTarget(tx, &check, review, now); err != nil {
+				return err
+			}
+		}
+
+		check.Review = review
+		check.ReviewedBy = &moderatorID
+		check.ReviewedAt = &now
+		return tx.Model(&check).Select("review", "reviewed_by", "reviewed_at").Updates(&check).Error
+	})
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	//the reviewed post was either shown or hidden
+	if check.TargetType == constants.ReactionTargetPost && check.TargetID != nil && check.Decision != spam.DecisionAllow {
+		invalidateRelatedPosts()
+	}
+
+	return &check, nil
+}
+
+// show or hide the content held by the spam checks once a moderator reviewed it
+func reviewSpamTarget(tx *gorm.DB, check *models.SpamCheck, review string, now time.Time) error {
+	if check.TargetType == constants.ReactionTargetPost {
+		//ham only shows the posts the spam checks hid, and the posts removed by the moderators stay removed
+		query := tx.Model(&models.Post{}).Where("post_id=? AND hidden_reason=?", *check.TargetID, constants.HoldReasonSpam)
+		columns := map[string]interface{}{"hidden_at": nil, "hidden_reason": ""}
+		if review == constants.SpamReviewSpam {
+			query = tx.Model(&models.Post{}).Where("post_id=? AND hidden_reason<>?", *check.TargetID, constants.HoldReasonRemoved)
+			columns = map[string]interface{}{"hidden_at": gorm.Expr("COALESCE(hidden_at, ?)", now), "hidden_reason": constants.HoldReasonSpam}
+		}
+
+		return query.UpdateColumns(columns).Error
+	}
+
+	status := constants.CommentStatusApproved
+	if review == constants.SpamReviewSpam {
+		status = constants.CommentStatusSpam
+	}
+
+	data := tx.Model(&models.Comment{}).Where("comment_id=?", *check.TargetID).
+		UpdateColumns(map[string]interface{}{"status": status, "hold_reason": "", "moderated_at": now})
+	if data.Error != nil {
+		return data.Error
+	}
+
+	return tx.Model(&models.Notification{}).Where("comment_id=? AND read_at IS NULL", *check.TargetID).Update("read_at", now).Error
+}
+
+// store the spam check of the content, the target is empty when the content was rejected before being created
+func saveSpamCheck(db *gorm.DB, verdict *spam.Verdict, targetType string, targetID *uuid.UUID, userID uuid.UUID) error {
+	if verdict == nil {
+		return nil
+	}
+
+	results := make([]models.SpamCheckResult, 0, len(verdict.Results))
+	for _, result := range verdict.Results {
+		results = append(results, models.SpamCheckResult{Checker: result.Checker, Score: result.Score, Reason: result.Reason, Error: result.Error})
+	}
+
+	return db.Create(&models.SpamCheck{TargetType: targetType, TargetID: targetID, UserID: userID, ContentHash: verdict.Hash,
+		Score: verdict.Score, Decision: verdict.Decision, Results: results}).Error
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/views"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatsRepository interface {
+	SaveViews(batch views.Batch) error
+	GetPostStats(postID uuid.UUID, userID uuid.UUID, role string, from, to time.Time) (*dto.PostStats, *dto.ErrorResponse)
+}
+
+type statsRepository struct {
+	*gorm.DB
+}
+
+func InitStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db}
+}
+
+// add a batch of views to the daily aggregates and the view count of the posts
+func (db *statsRepository) SaveViews(batch views.Batch) error {
+	days := make([]models.PostDailyStat, 0, len(batch.Days))
+	totals := make(map[uuid.UUID]int64)
+	for _, count := range batch.Days {
+		days = append(days, models.PostDailyStat{PostID: count.PostID, Day: count.Day, Views: count.Views, Visitors: count.Visitors})
+		totals[count.PostID] += count.Views
+	}
+
+	referrers := make([]models.PostReferrerStat, 0, len(batch.Referrers))
+	for _, count := range batch.Referrers {
+		referrers = append(referrers, models.PostReferrerStat{PostID: count.PostID, Day: count.Day, Referrer: count.Referrer, Views: count.Views})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(days) > 0 {
+			data := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "post_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"views":    gorm.Expr("post_daily_stats.views + excluded.views"),
+					"visitors": gorm.Expr("post_daily_stats.visitors + excluded.visitors"),
+				}),
+			}).Create(&days)
+			if data.Error != nil {
+				return data.Error
+			}
+		}
+
+		if len(referrers) > 0 {
+			data := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}, {Name: "referrer"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("post_referrer_stats.views + excluded.views")}),
+			}).Create(&referrers)
+			if data.Error != nil {
+				return data.Error
+			}
+		}
+
+		//keep the total on the post so listings do not need to sum the aggregates
+		for postID, total := range totals {
+			if err := tx.Exec("UPDATE posts SET view_count = view_count + ? WHERE post_id = ?", total, postID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// retrieve the daily views and the top referrers of a post if the user is one of its authors or if it is the admin
+func (db *statsRepository) GetPostStats(postID uuid.UUID, userID uuid.UUID, role string, from, to time.Time) (*dto.PostStats, *dto.ErrorResponse) {
+	var post models.Post
+	stats := dto.PostStats{PostID: postID, From: from, To: to, Days: []dto.DailyStat{}, Referrers: []dto.ReferrerStat{}}
+
+	//check if the record exists and if the user can access it
+	data := db.Where("post_id=?", postID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	postRole, err := authorRole(db.DB, postID, userID)
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	} else if post.UserID != userID && postRole == "" && role != constants.AdminRole {
+		return nil, &dto.ErrorResponse{Status: http.StatusForbidden, Error: "cannot view the stats of other users post"}
+	}
+	stats.TotalViews = post.ViewCount
+
+	data = db.Model(&models.PostDailyStat{}).Select("day, views, visitors").
+		Where("post_id=? AND day BETWEEN ? AND ?", postID, from, to).Order("day").Scan(&stats.Days)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	for _, day := range stats.Days {
+		stats.Views += day.Views
+		stats.Visitors += day.Visitors
+	}
+
+	data = db.Model(&models.PostReferrerStat{}).Select("referrer, SUM(views) AS views").
+		Where("post_id=? AND day BETWEEN ? AND ?", postID, from, to).
+		Group("referrer").Order("views DESC").Limit(constants.MaxStatsReferrers).Scan(&stats.Referrers)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &stats, nil
+}
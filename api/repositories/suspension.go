@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"github.com/marees7/rishi-aug-2024/common/dto"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SuspensionRepository interface {
+	CheckSuspended(userID uuid.UUID) *dto.ErrorResponse
+}
+
+type suspensionRepository struct {
+	*gorm.DB
+}
+
+func InitSuspensionRepository(db *gorm.DB) SuspensionRepository {
+	return &suspensionRepository{db}
+}
+
+// check the user is not suspended before they change anything
+func (db *suspensionRepository) CheckSuspended(userID uuid.UUID) *dto.ErrorResponse {
+	return checkSuspended(db.DB, userID)
+}
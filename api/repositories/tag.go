@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	GetTags(limit, offset int, sort string) (*[]models.Tag, int64, *dto.ErrorResponse)
+	GetPostsByTag(slug string, limit, offset int, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse)
+	RenameTag(tagID uuid.UUID, name string) (*models.Tag, *dto.ErrorResponse)
+	MergeTag(sourceID uuid.UUID, targetID uuid.UUID) *dto.ErrorResponse
+}
+
+type tagRepository struct {
+	*gorm.DB
+}
+
+func InitTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db}
+}
+
+// retrieve every tag along with the number of posts using it
+func (db *tagRepository) GetTags(limit, offset int, sort string) (*[]models.Tag, int64, *dto.ErrorResponse) {
+	var tags []models.Tag
+	var count int64
+
+	data := db.Model(&models.Tag{}).Count(&count)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	order := "usage_count DESC, tags.name"
+	if sort == "name" {
+		order = "tags.name"
+	}
+
+	//count only the posts which are not deleted
+	data = db.Model(&models.Tag{}).
+		Select("tags.*, COUNT(posts.post_id) AS usage_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.tag_id").
+		Joins("LEFT JOIN posts ON posts.post_id = post_tags.post_id AND posts.deleted_at IS NULL").
+		Group("tags.tag_id").Order(order).Limit(limit).Offset(offset).Find(&tags)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	return &tags, count, nil
+}
+
+// retrieve the posts labelled with the tag
+func (db *tagRepository) GetPostsByTag(slug string, limit, offset int, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse) {
+	var tag models.Tag
+	var posts []models.Post
+	var count int64
+
+	//check if the tag exists
+	data := db.Where("slug=?", slug).First(&tag)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "tag not found"}
+	} else if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	data = db.Model(&models.Post{}).Scopes(listedPosts(viewer)).Preload("Tags").Preload("Media").
+		Joins("JOIN post_tags ON post_tags.post_id = posts.post_id").
+		Where("post_tags.tag_id=?", tag.TagID).
+		Count(&count).Order("posts.created_at DESC").Limit(limit).Offset(offset).Find(&posts)
+	if data.Error != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if err := fillPostsHTML(posts); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	lockPosts(posts, viewer)
+
+	if err := fillPostsReactions(db.DB, posts); err != nil {
+		return nil, 0, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &posts, count, nil
+}
+
+// rename an existing tag
+func (db *tagRepository) RenameTag(tagID uuid.UUID, name string) (*models.Tag, *dto.ErrorResponse) {
+	var tag models.Tag
+
+	//check if the tag exists
+	data := db.Where("tag_id=?", tagID).First(&tag)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "tag not found"}
+	} else if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	name = helpers.NormalizeTag(name)
+	slug := helpers.Slugify(name)
+
+	//check if another tag already uses the name
+	var count int64
+	data = db.Model(&models.Tag{}).Where("(slug=? OR name=?) AND tag_id<>?", slug, name, tagID).Count(&count)
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if count > 0 {
+		return nil, &dto.ErrorResponse{Status: http.StatusConflict, Error: "tag already exists, merge the tags instead"}
+	}
+
+	data = db.Model(&tag).Updates(models.Tag{Name: name, Slug: slug})
+	if data.Error != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if data.RowsAffected == 0 {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotModified, Error: "no changes were made"}
+	}
+
+	return &tag, nil
+}
+
+// move every post of the source tag to the target tag and delete the source tag
+func (db *tagRepository) MergeTag(sourceID uuid.UUID, targetID uuid.UUID) *dto.ErrorResponse {
+	if sourceID == targetID {
+		return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "cannot merge a tag into itself"}
+	}
+
+	//check if both the tags exist
+	var count int64
+	data := db.Model(&models.Tag{}).Where("tag_id IN ?", []uuid.UUID{sourceID, targetID}).Count(&count)
+	if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	} else if count != 2 {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "tag not found"}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		//posts already labelled with the target tag are skipped
+		data := tx.Exec("INSERT INTO post_tags (post_id, tag_id) SELECT post_id, ? FROM post_tags WHERE tag_id = ? ON CONFLICT DO NOTHING", targetID, sourceID)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		data = tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", sourceID)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		return tx.Where("tag_id=?", sourceID).Delete(&models.Tag{}).Error
+	})
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	invalidateRelatedPosts()
+
+	return nil
+}
+
+// find the tags with the given names and create the missing ones
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		name = helpers.NormalizeTag(name)
+		slug := helpers.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		//create the tag if no tag uses the slug yet
+		tag := models.Tag{Name: name, Slug: slug}
+		data := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag)
+		if data.Error != nil {
+			return nil, data.Error
+		}
+
+		if data.RowsAffected == 0 {
+			tag = models.Tag{}
+			if err := tx.Where("slug=?", slug).First(&tag).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// replace the tags of the post with the given tag names
+func assignTags(tx *gorm.DB, post *models.Post, names []string) error {
+	tags, err := findOrCreateTags(tx, names)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return tx.Model(post).Association("Tags").Clear()
+	}
+
+	return tx.Model(post).Association("Tags").Replace(tags)
+}
@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deepest level a comment can be nested at, the comments starting a thread are at level 0
+func commentMaxDepth() int {
+	return max(int(helpers.EnvInt64("COMMENT_MAX_DEPTH", int64(constants.DefaultCommentMaxDepth))), 1)
+}
+
+// most answers loaded along with the comment starting a thread
+func commentMaxAnswers() int {
+	return max(int(helpers.EnvInt64("COMMENT_MAX_ANSWERS", int64(constants.DefaultCommentMaxAnswers))), 1)
+}
+
+// place the comment in the thread of its parent before creating it
+//
+// answers to a comment nested as deep as allowed answer its parent instead, and the answers
+// without a post are given the post of their parent
+func placeComment(db *gorm.DB, comment *models.Comment) *dto.ErrorResponse {
+	comment.CommentID = uuid.New()
+	comment.Path = comment.CommentID.String()
+	comment.Depth = 0
+
+	if comment.ParentID == nil {
+		return nil
+	}
+
+	var parent models.Comment
+
+	data := db.Where("comment_id=?", *comment.ParentID).First(&parent)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "comment does not exist"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if comment.PostID == uuid.Nil {
+		comment.PostID = parent.PostID
+	} else if comment.PostID != parent.PostID {
+		return &dto.ErrorResponse{Status: http.StatusBadRequest, Error: "comment belongs to another post"}
+	}
+
+	//the ancestor at the deepest level which can still be answered is found in the path of the parent
+	if maxDepth := commentMaxDepth(); parent.Depth >= maxDepth {
+		ancestors := strings.Split(parent.Path, "/")
+		if len(ancestors) < maxDepth {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: "invalid thread of the comment"}
+		}
+
+		data = db.Where("comment_id=?", ancestors[maxDepth-1]).First(&parent)
+		if data.Error != nil {
+			return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+		}
+	}
+
+	comment.ParentID = &parent.CommentID
+	comment.Path = parent.Path + "/" + comment.CommentID.String()
+	comment.Depth = parent.Depth + 1
+
+	return nil
+}
+
+// order of the comments starting the threads
+func threadOrder(sort string) clause.OrderBy {
+	switch sort {
+	case constants.CommentSortNewest:
+		return clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "comments.created_at"}, Desc: true}}}
+	case constants.CommentSortTop:
+		return clause.OrderBy{Expression: clause.Expr{
+			SQL: `(SELECT COALESCE(SUM(reaction_counts.count), 0) FROM reaction_counts
+				WHERE reaction_counts.target_type = ? AND reaction_counts.target_id = comments.comment_id) DESC, comments.created_at DESC`,
+			Vars: []interface{}{constants.ReactionTargetComment},
+		}}
+	default:
+		return clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "comments.created_at"}}}}
+	}
+}
+
+// load the answers the viewer can see along with their authors, and nest them under the comments in the given order
+//
+// the comments are at the same depth, each of them comes with its shallowest and oldest answers up to the most answers
+// of a thread, and the comments whose answers were left out are flagged
+func loadThreads(db *gorm.DB, comments []models.Comment, sort string, viewer dto.Viewer, withUsers bool) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	depth := comments[0].Depth
+	maxAnswers := commentMaxAnswers()
+
+	prefixes := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		prefixes = append(prefixes, comment.Path+"/%")
+	}
+
+	var answers []models.Comment
+
+	//the answers are ranked within the thread of the comment found at the same depth in their path, the prefixes are
+	//expanded into the array without the parentheses gorm adds around slices
+	ranked := db.Model(&models.Comment{}).Scopes(visibleComments(viewer)).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY split_part(comments.path, '/', ?) ORDER BY comments.depth, comments.created_at) AS answer_rank", depth+1).
+		Where(clause.Expr{SQL: "comments.path LIKE ANY (ARRAY[?])", Vars: []interface{}{prefixes}, WithoutParentheses: true})
+	query := db.Table("(?) AS comments", ranked).Where("answer_rank <= ?", maxAnswers)
+	if withUsers {
+		query = query.Preload("User")
+	}
+	if err := query.Order("comments.depth, comments.created_at").Find(&answers).Error; err != nil {
+		return err
+	}
+
+	//the comments and their answers are completed together before being nested
+	all := append(slices.Clone(comments), answers...)
+	if err := fillCommentsHTML(all); err != nil {
+		return err
+	}
+	if err := fillCommentsReactions(db, all); err != nil {
+		return err
+	}
+
+	children := make(map[uuid.UUID][]*models.Comment)
+	for i := range all[len(comments):] {
+		answer := &all[len(comments)+i]
+		if answer.ParentID != nil {
+			children[*answer.ParentID] = append(children[*answer.ParentID], answer)
+		}
+	}
+
+	if err := flagMoreReplies(db, all, children, depth, maxAnswers, viewer); err != nil {
+		return err
+	}
+
+	for i := range comments {
+		comments[i] = nestComment(&all[i], children, sort)
+	}
+
+	return nil
+}
+
+// flag the comments of the threads which reached the most answers when some of their answers were not loaded
+func flagMoreReplies(db *gorm.DB, all []models.Comment, children map[uuid.UUID][]*models.Comment, depth int, maxAnswers int, viewer dto.Viewer) error {
+	var counts []struct {
+		ParentID uuid.UUID
+		Answers  int
+	}
+
+	loaded := make(map[string]int)
+	for _, comment := range all {
+		if comment.Depth > depth {
+			loaded[threadKey(comment, depth)]++
+		}
+	}
+
+	var ids []uuid.UUID
+	for _, comment := range all {
+		if loaded[threadKey(comment, depth)] >= maxAnswers {
+			ids = append(ids, comment.CommentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	data := db.Model(&models.Comment{}).Scopes(visibleComments(viewer)).Select("comments.parent_id, COUNT(*) AS answers").
+		Where("comments.parent_id IN ?", ids).Group("comments.parent_id").Scan(&counts)
+	if data.Error != nil {
+		return data.Error
+	}
+
+	more := make(map[uuid.UUID]bool, len(counts))
+	for _, count := range counts {
+		more[count.ParentID] = len(children[count.ParentID]) < count.Answers
+	}
+	for i := range all {
+		all[i].MoreReplies = more[all[i].CommentID]
+	}
+
+	return nil
+}
+
+// id of the comment at the given depth the comment belongs to
+func threadKey(comment models.Comment, depth int) string {
+	ancestors := strings.Split(comment.Path, "/")
+	if depth >= len(ancestors) {
+		return comment.Path
+	}
+
+	return ancestors[depth]
+}
+
+// nest the answers under the comment, the answers of deleted or hidden comments are left out along with them
+func nestComment(comment *models.Comment, children map[uuid.UUID][]*models.Comment, sort string) models.Comment {
+	answers := children[comment.CommentID]
+	sortComments(answers, sort)
+
+	comment.Replies = nil
+	comment.ReplyCount = 0
+	for _, answer := range answers {
+		nested := nestComment(answer, children, sort)
+		comment.Replies = append(comment.Replies, nested)
+		comment.ReplyCount += nested.ReplyCount + 1
+	}
+
+	return *comment
+}
+
+// sort the answers of a comment like the threads are sorted
+func sortComments(comments []*models.Comment, sort string) {
+	slices.SortStableFunc(comments, func(a, b *models.Comment) int {
+		switch sort {
+		case constants.CommentSortNewest:
+			return b.CreatedAt.Compare(a.CreatedAt)
+		case constants.CommentSortTop:
+			if score := reactionTotal(b.Reactions) - reactionTotal(a.Reactions); score != 0 {
+				if score > 0 {
+					return 1
+				}
+				return -1
+			}
+			return b.CreatedAt.Compare(a.CreatedAt)
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	})
+}
+
+// total number of reactions of every type
+func reactionTotal(reactions map[string]int64) int64 {
+	var total int64
+
+	for _, count := range reactions {
+		total += count
+	}
+
+	return total
+}
@@ -58,8 +58,8 @@ func (db *userRepository) GetUser(username string) (*models.User, *dto.ErrorResp
 }
 
 func (db *userRepository) UpdateUser(user *models.User) *dto.ErrorResponse {
-	//updates the user
-	data := db.Where("email=?", user.Email).Updates(&user)
+	//updates the user, only moderators suspend users
+	data := db.Omit("suspended_until").Where("email=?", user.Email).Updates(&user)
 	if data.Error != nil {
 		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
 	} else if data.RowsAffected == 0 {
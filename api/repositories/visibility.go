@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// visibility levels the viewer can see on posts of other users along with the given levels
+func visibleLevels(viewer dto.Viewer, levels ...string) []string {
+	levels = append([]string{constants.VisibilityPublic}, levels...)
+	if viewer.UserID != uuid.Nil {
+		levels = append(levels, constants.VisibilityMembers)
+	}
+
+	return levels
+}
+
+// condition matching the published posts with the given levels which were not hidden, authors always see their own posts
+//
+// admins are filtered like every other user, they only see the drafts and hidden posts of others through allPosts
+func visibilityCondition(viewer dto.Viewer, levels []string) (string, []interface{}) {
+	if viewer.UserID == uuid.Nil {
+		return "(posts.status = ? AND posts.visibility IN ? AND posts.hidden_at IS NULL)", []interface{}{constants.PostStatusPublished, levels}
+	}
+
+	authored, authoredArgs := authoredCondition("post_authors.user_id = ?", viewer.UserID)
+	return "((posts.status = ? AND posts.visibility IN ? AND posts.hidden_at IS NULL) OR posts.user_id = ? OR " + authored + ")",
+		append([]interface{}{constants.PostStatusPublished, levels, viewer.UserID}, authoredArgs...)
+}
+
+// condition matching the posts having an accepted author which satisfies the given condition
+func authoredCondition(condition string, args ...interface{}) (string, []interface{}) {
+	return "EXISTS (SELECT 1 FROM post_authors JOIN users ON users.user_id = post_authors.user_id AND users.deleted_at IS NULL " +
+			"WHERE post_authors.post_id = posts.post_id AND post_authors.status = ? AND " + condition + ")",
+		append([]interface{}{constants.AuthorStatusAccepted}, args...)
+}
+
+// limits the query to the posts written by the user with the given username, alone or with others
+func authoredBy(username string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		condition, args := authoredCondition("users.username = ?", username)
+		return db.Where(condition, args...)
+	}
+}
+
+// load the accepted authors of the posts along with their names, the owner comes first
+func preloadAuthors(db *gorm.DB) *gorm.DB {
+	return db.Preload("Authors", func(db *gorm.DB) *gorm.DB {
+		return db.Select("post_authors.*, users.username, users.name").
+			Joins("JOIN users ON users.user_id = post_authors.user_id AND users.deleted_at IS NULL").
+			Where("post_authors.status = ?", constants.AuthorStatusAccepted).
+			Order("post_authors.role = 'owner' DESC, post_authors.created_at")
+	})
+}
+
+// limits the query to the posts shown in listings, unlisted posts are only reachable by link
+func listedPosts(viewer dto.Viewer) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		condition, args := visibilityCondition(viewer, visibleLevels(viewer, constants.VisibilityPassword))
+		return db.Where(condition, args...)
+	}
+}
+
+// limits the query to the posts the viewer can open by link, admins can open every post to moderate it
+func visiblePosts(viewer dto.Viewer) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewer.Role == constants.AdminRole {
+			return allPosts(db)
+		}
+
+		condition, args := visibilityCondition(viewer, visibleLevels(viewer, constants.VisibilityPassword, constants.VisibilityUnlisted))
+		return db.Where(condition, args...)
+	}
+}
+
+// leaves the posts unfiltered, including the drafts and hidden posts, only used for admins
+func allPosts(db *gorm.DB) *gorm.DB {
+	return db
+}
+
+// check if the viewer can read the content of the post
+func canReadPost(post *models.Post, viewer dto.Viewer) bool {
+	if post.Visibility != constants.VisibilityPassword || viewer.Role == constants.AdminRole || post.HasAuthor(viewer.UserID) {
+		return true
+	}
+
+	for _, postID := range viewer.Grants {
+		if postID == post.PostID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hide the content of the password protected posts the viewer has not unlocked
+func lockPosts(posts []models.Post, viewer dto.Viewer) {
+	for i := range posts {
+		lockPost(&posts[i], viewer)
+	}
+}
+
+// hide the content of the post if it is password protected and the viewer has not unlocked it
+func lockPost(post *models.Post, viewer dto.Viewer) {
+	if canReadPost(post, viewer) {
+		return
+	}
+
+	post.Content = ""
+	post.ContentHTML = ""
+	post.Comments = nil
+	post.Media = nil
+	post.Locked = true
+}
+
+// check if the viewer can read the post and its comments
+func checkPostAccess(db *gorm.DB, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	var post models.Post
+
+	data := db.Scopes(visiblePosts(viewer), preloadAuthors).Where("post_id=?", postID).First(&post)
+	if errors.Is(data.Error, gorm.ErrRecordNotFound) {
+		return &dto.ErrorResponse{Status: http.StatusNotFound, Error: "post does not exist"}
+	} else if data.Error != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: data.Error.Error()}
+	}
+
+	if !canReadPost(&post, viewer) {
+		return &dto.ErrorResponse{Status: http.StatusForbidden, Error: "post is password protected, unlock it first"}
+	}
+
+	return nil
+}
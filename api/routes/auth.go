@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
 	"github.com/marees7/rishi-aug-2024/api/repositories"
 	"github.com/marees7/rishi-aug-2024/api/services"
 
@@ -20,5 +21,5 @@ func AuthRoute(server *echo.Echo, db *gorm.DB) {
 	handler := &handlers.AuthHandler{AuthServices: authService}
 
 	server.POST("/signup", handler.Signup)
-	server.POST("/login", handler.Login)
+	server.POST("/login", handler.Login, middlewares.RateLimitLogin(rateLimiter(db)))
 }
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func AuthorRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	authorRepository := repositories.InitAuthorRepository(db)
+
+	//send the repo to the services package
+	authorService := services.InitAuthorService(authorRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.AuthorHandler{AuthorServices: authorService}
+
+	//group post author routes
+	users := server.Group("v1/users/post")
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	users.GET("/invitations", handler.GetInvitations)
+	users.POST("/invitations/:post_id/accept", handler.AcceptInvitation)
+	users.DELETE("/invitations/:post_id", handler.DeclineInvitation)
+	users.GET("/:post_id/authors", handler.GetAuthors)
+	users.POST("/:post_id/authors", handler.InviteAuthor)
+	users.DELETE("/:post_id/authors/:username", handler.RemoveAuthor)
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func BookmarkRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	bookmarkRepository := repositories.InitBookmarkRepository(db)
+
+	//send the repo to the services package
+	bookmarkService := services.InitBookmarkService(bookmarkRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.BookmarkHandler{BookmarkServices: bookmarkService}
+
+	//group bookmark routes
+	users := server.Group("v1/users/bookmarks")
+	users.Use(middlewares.ValidateToken)
+
+	users.GET("", handler.GetBookmarks)
+	users.GET("/collections", handler.GetCollections)
+	users.DELETE("/collections/:collection_id", handler.DeleteCollection)
+	users.POST("/:post_id", handler.CreateBookmark)
+	users.DELETE("/:post_id", handler.DeleteBookmark)
+}
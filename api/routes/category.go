@@ -26,9 +26,15 @@ func CategoryRoute(server *echo.Echo, db *gorm.DB) {
 
 	users.GET("", handler.GetCategories)
 
+	//group category lookup routes
+	categories := server.Group("v1/categories")
+	categories.Use(middlewares.ValidateToken)
+
+	categories.GET("/by-slug/:slug", handler.GetCategoryBySlug)
+
 	//group admin routes
 	admin := server.Group("v1/admin/categories")
-	admin.Use(middlewares.ValidateToken)
+	admin.Use(middlewares.ValidateToken, rejectSuspended(db))
 
 	admin.POST("", handler.CreateCategory)
 	admin.PUT("/:category_id", handler.UpdateCategory)
@@ -5,6 +5,7 @@ import (
 	"github.com/marees7/rishi-aug-2024/api/middlewares"
 	"github.com/marees7/rishi-aug-2024/api/repositories"
 	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -15,17 +16,18 @@ func CommentRoute(server *echo.Echo, db *gorm.DB) {
 	commentRepository := repositories.InitCommentRepository(db)
 
 	//send the repo to the services package
-	commentService := services.InitCommentService(commentRepository)
+	commentService := services.InitCommentService(commentRepository, spamPipeline(db))
 
 	//Initialize the handler struct
 	handler := &handlers.CommentHandler{CommentServices: commentService}
 
 	//group user routes
 	users := server.Group("v1/users/comment")
-	users.Use(middlewares.ValidateToken)
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
 
-	users.POST("/:post_id", handler.CreateComment)
+	users.POST("/:post_id", handler.CreateComment, limitAction(db, constants.RateLimitActionComment))
 	users.GET("/:post_id", handler.GetComments)
+	users.GET("/:comment_id/replies", handler.GetThread)
 	users.PUT("/:comment_id", handler.UpdateComment)
 	users.DELETE("/:comment_id", handler.DeleteComment)
 }
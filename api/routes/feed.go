@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func FeedRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	publicRepository := repositories.InitPublicRepository(db)
+
+	//send the repo to the services package
+	feedService := services.InitFeedService(publicRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.FeedHandler{FeedServices: feedService}
+
+	rateLimit := helpers.EnvInt64("PUBLIC_RATE_LIMIT", int64(constants.DefaultPublicRateLimit))
+	maxAge := helpers.EnvInt64("PUBLIC_CACHE_MAX_AGE", int64(constants.DefaultPublicCacheMaxAge))
+
+	//group feed routes which do not need a login
+	feeds := server.Group("feeds")
+	feeds.Use(middlewares.RateLimitByIP(int(rateLimit)))
+	feeds.Use(middlewares.CacheControl(int(maxAge)))
+
+	//register every format for the site, category, tag and author feeds
+	for _, prefix := range []string{"", "/categories/:category", "/tags/:tag", "/authors/:username"} {
+		feeds.GET(prefix+"/posts.rss", handler.GetRSS)
+		feeds.GET(prefix+"/posts.atom", handler.GetAtom)
+		feeds.GET(prefix+"/posts.json", handler.GetJSON)
+	}
+}
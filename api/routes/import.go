@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func ImportRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	importRepository := repositories.InitImportRepository(db)
+
+	//send the repo to the services package
+	importService := services.InitImportService(importRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.ImportHandler{ImportServices: importService}
+
+	//group admin routes
+	admin := server.Group("v1/admin/imports")
+	admin.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	admin.POST("", handler.CreateImport)
+	admin.GET("", handler.GetImports)
+	admin.GET("/:job_id", handler.GetImport)
+	admin.GET("/:job_id/items", handler.GetImportItems)
+}
@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
+)
+
+func MediaRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	mediaRepository := repositories.InitMediaRepository(db)
+
+	//send the repo and the storage to the services package
+	mediaService := services.InitMediaService(mediaRepository, newStorage())
+
+	//Initialize the handler struct
+	handler := &handlers.MediaHandler{MediaServices: mediaService}
+
+	//serve the uploaded files when they are stored on the local disk, anyone with the link can read them
+	if backend := os.Getenv("STORAGE_BACKEND"); backend == "" || backend == storage.BackendLocal {
+		server.Static("/media", storage.LocalDir())
+	}
+
+	//group media routes
+	media := server.Group("v1/users/media")
+	media.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	//refuse the bodies larger than a file, leaving room for the multipart headers, before they are spooled to the disk
+	maxSize := helpers.EnvInt64("MEDIA_MAX_SIZE", constants.DefaultMediaMaxSize)
+	media.Use(middleware.BodyLimit(strconv.FormatInt(maxSize+constants.MediaUploadOverhead, 10)))
+
+	media.POST("", handler.UploadMedia)
+	media.GET("", handler.GetMedia)
+	media.DELETE("/:media_id", handler.DeleteMedia)
+}
+
+// create the storage configured for the uploaded files
+func newStorage() storage.Storage {
+	store, err := storage.New()
+	if err != nil {
+		loggers.Error.Fatalln("Failed to initialize the storage", err)
+	}
+
+	return store
+}
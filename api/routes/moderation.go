@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func ModerationRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	moderationRepository := repositories.InitModerationRepository(db)
+
+	//send the repo to the services package
+	moderationService := services.InitModerationService(moderationRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.ModerationHandler{ModerationServices: moderationService}
+
+	//group comment moderation routes of the admins and moderators
+	moderation := server.Group("v1/moderation")
+	moderation.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	moderation.GET("/comments", handler.GetQueue)
+	moderation.PUT("/comments", handler.ModerateComments)
+	moderation.GET("/comments/:comment_id/revisions", handler.GetRevisions)
+	moderation.GET("/rules", handler.GetRules)
+	moderation.PUT("/rules", handler.SaveRule)
+	moderation.DELETE("/rules/:rule_id", handler.DeleteRule)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func NotificationRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	notificationRepository := repositories.InitNotificationRepository(db)
+
+	//send the repo to the services package
+	notificationService := services.InitNotificationService(notificationRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.NotificationHandler{NotificationServices: notificationService}
+
+	//group user routes
+	users := server.Group("v1/users/notifications")
+	users.Use(middlewares.ValidateToken)
+
+	users.GET("", handler.GetNotifications)
+	users.PUT("/read", handler.ReadNotifications)
+	users.PUT("/:notification_id/read", handler.ReadNotification)
+}
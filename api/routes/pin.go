@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func PinRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	pinRepository := repositories.InitPinRepository(db)
+
+	//send the repo to the services package
+	pinService := services.InitPinService(pinRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.PinHandler{PinServices: pinService}
+
+	//group pin routes of the admins and moderators
+	pins := server.Group("v1/moderation/pins")
+	pins.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	pins.GET("", handler.GetPins)
+	pins.PUT("/:post_id", handler.PinPost)
+	pins.DELETE("/:post_id", handler.UnpinPost)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/marees7/rishi-aug-2024/api/middlewares"
 	"github.com/marees7/rishi-aug-2024/api/repositories"
 	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -13,20 +14,28 @@ import (
 func PostRoute(server *echo.Echo, db *gorm.DB) {
 	//send the db connection to the repository package
 	postRepository := repositories.InitPostRepository(db)
+	mediaRepository := repositories.InitMediaRepository(db)
 
 	//send the repo to the services package
-	postService := services.InitPostService(postRepository)
+	postService := services.InitPostService(postRepository, mediaRepository, newStorage(), viewTracker(db), spamPipeline(db))
 
 	//Initialize the handler struct
 	handler := &handlers.PostHandler{PostServices: postService}
 
 	//group user routes
 	users := server.Group("v1/users/post")
-	users.Use(middlewares.ValidateToken)
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
 
-	users.POST("", handler.CreatePost)
+	users.POST("", handler.CreatePost, limitAction(db, constants.RateLimitActionPost))
 	users.GET("", handler.GetPosts)
 	users.GET("/:post_id", handler.GetPost)
 	users.PUT("/:post_id", handler.UpdatePost)
 	users.DELETE("/:post_id", handler.DeletePost)
+	users.PUT("/:post_id/comments/lock", handler.LockComments)
+
+	//group post lookup routes
+	posts := server.Group("v1/posts")
+	posts.Use(middlewares.ValidateToken)
+
+	posts.GET("/by-slug/:slug", handler.GetPostBySlug)
 }
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func PublicRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	publicRepository := repositories.InitPublicRepository(db)
+
+	//send the repo to the services package
+	publicService := services.InitPublicService(publicRepository, viewTracker(db))
+
+	//Initialize the handler struct
+	handler := &handlers.PublicHandler{PublicServices: publicService}
+
+	rateLimit := helpers.EnvInt64("PUBLIC_RATE_LIMIT", int64(constants.DefaultPublicRateLimit))
+	maxAge := helpers.EnvInt64("PUBLIC_CACHE_MAX_AGE", int64(constants.DefaultPublicCacheMaxAge))
+
+	//group public routes which do not need a login
+	public := server.Group("v1/public")
+	public.Use(middlewares.RateLimitByIP(int(rateLimit)))
+	public.Use(middlewares.CacheControl(int(maxAge)))
+	public.Use(middlewares.OptionalToken)
+
+	public.GET("/posts", handler.GetPosts)
+	public.GET("/posts/:slug", handler.GetPost)
+	public.POST("/posts/:slug/unlock", handler.UnlockPost)
+	public.GET("/posts/:slug/comments", handler.GetComments)
+	public.GET("/posts/:slug/comments/:comment_id", handler.GetThread)
+	public.GET("/categories", handler.GetCategories)
+	public.GET("/authors/:username", handler.GetAuthor)
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/ratelimit"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var (
+	limiterOnce sync.Once
+	limiter     *ratelimit.Limiter
+)
+
+// the rate limiter is shared by the routes creating content and the login, the buckets are kept in memory unless
+// the replicas have to share them through postgres
+func rateLimiter(db *gorm.DB) *ratelimit.Limiter {
+	limiterOnce.Do(func() {
+		var store ratelimit.Store
+
+		switch backend := os.Getenv("RATE_LIMIT_BACKEND"); backend {
+		case "", ratelimit.BackendMemory:
+			store = ratelimit.NewMemoryStore()
+		case ratelimit.BackendPostgres:
+			store = repositories.InitRateLimitRepository(db)
+		default:
+			loggers.Error.Fatalln("Unknown rate limit backend", backend)
+		}
+
+		limits := make(map[string]ratelimit.Limit)
+		envLimit(limits, constants.RateLimitActionPost, "RATE_LIMIT_POST", constants.DefaultRateLimitPost)
+		envLimit(limits, constants.RateLimitActionComment, "RATE_LIMIT_COMMENT", constants.DefaultRateLimitComment)
+		envLimit(limits, constants.RateLimitActionReply, "RATE_LIMIT_REPLY", constants.DefaultRateLimitReply)
+		envLimit(limits, constants.RateLimitActionLogin, "RATE_LIMIT_LOGIN", constants.DefaultRateLimitLogin)
+		envLimit(limits, constants.RateLimitActionLoginIP, "RATE_LIMIT_LOGIN_IP", constants.DefaultRateLimitLoginIP)
+		envLimit(limits, constants.RateLimitActionLoginAccount, "RATE_LIMIT_LOGIN_ACCOUNT", constants.DefaultRateLimitLoginAccount)
+
+		limiter = ratelimit.NewLimiter(store, limits)
+
+		//the ticker needs a positive interval
+		interval := max(helpers.EnvInt64("RATE_LIMIT_PRUNE_INTERVAL", int64(constants.DefaultRateLimitPruneInterval)), 1)
+		go limiter.Run(time.Duration(interval)*time.Second, func(err error) {
+			loggers.Warn.Println("Failed to prune the rate limit buckets", err)
+		})
+	})
+
+	return limiter
+}
+
+// read the limit of the action from the env variable, off leaves the action unlimited
+func envLimit(limits map[string]ratelimit.Limit, action string, name string, fallback string) {
+	value := os.Getenv(name)
+	if value == "off" {
+		return
+	} else if value == "" {
+		value = fallback
+	}
+
+	limit, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		loggers.Error.Fatalln("Invalid", name, err)
+	}
+	limits[action] = limit
+}
+
+// limit the action of the logged in users, the trusted roles are exempt
+func limitAction(db *gorm.DB, action string) echo.MiddlewareFunc {
+	return middlewares.RateLimitByUser(rateLimiter(db), action, helpers.EnvList("RATE_LIMIT_EXEMPT_ROLES", constants.DefaultRateLimitExemptRoles))
+}
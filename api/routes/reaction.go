@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func ReactionRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	reactionRepository := repositories.InitReactionRepository(db)
+
+	//send the repo to the services package
+	reactionService := services.InitReactionService(reactionRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.ReactionHandler{ReactionServices: reactionService}
+
+	//group reaction routes
+	users := server.Group("v1/users/reactions")
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	users.POST("/:target_type/:target_id", handler.ToggleReaction)
+	users.GET("/:target_type/:target_id", handler.GetReactors)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func RelatedRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	relatedRepository := repositories.InitRelatedRepository(db)
+
+	//send the repo to the services package
+	relatedService := services.InitRelatedService(relatedRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.RelatedHandler{RelatedServices: relatedService}
+
+	//group related post routes
+	posts := server.Group("v1/posts")
+	posts.Use(middlewares.ValidateToken)
+
+	posts.GET("/:post_id/related", handler.GetRelatedPosts)
+}
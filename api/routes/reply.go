@@ -5,26 +5,28 @@ import (
 	"github.com/marees7/rishi-aug-2024/api/middlewares"
 	"github.com/marees7/rishi-aug-2024/api/repositories"
 	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
+// replies are comments answering another comment, these routes are kept for the clients written before threads
 func ReplyRoute(server *echo.Echo, db *gorm.DB) {
 	//send the db connection to the repository package
-	replyRepository := repositories.InitReplyRepository(db)
+	commentRepository := repositories.InitCommentRepository(db)
 
 	//send the repo to the services package
-	replyService := services.ReplyServices(replyRepository)
+	commentService := services.InitCommentService(commentRepository, spamPipeline(db))
 
 	//Initialize the handler struct
-	handler := &handlers.ReplyHandler{ReplyServices: replyService}
+	handler := &handlers.CommentHandler{CommentServices: commentService}
 
 	//group user routes
 	users := server.Group("v1/users/reply")
-	users.Use(middlewares.ValidateToken)
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
 
-	users.POST("/:comment_id", handler.CreateReply)
-	users.PUT("/:reply_id", handler.UpdateReply)
-	users.DELETE("/:reply_id", handler.DeleteReply)
+	users.POST("/:comment_id", handler.CreateReply, limitAction(db, constants.RateLimitActionReply))
+	users.PUT("/:comment_id", handler.UpdateComment)
+	users.DELETE("/:comment_id", handler.DeleteComment)
 }
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func ReportRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	reportRepository := repositories.InitReportRepository(db)
+
+	//send the repo to the services package
+	reportService := services.InitReportService(reportRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.ReportHandler{ReportServices: reportService}
+
+	//group user routes
+	users := server.Group("v1/reports")
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	users.POST("", handler.CreateReport)
+
+	//group report routes of the admins and moderators
+	moderation := server.Group("v1/moderation")
+	moderation.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	moderation.GET("/reports", handler.GetReportGroups)
+	moderation.GET("/reports/:target_type/:target_id", handler.GetReports)
+	moderation.POST("/reports/:target_type/:target_id/resolve", handler.ResolveReports)
+	moderation.GET("/audit", handler.GetAuditLogs)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func SearchRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	searchRepository := repositories.InitSearchRepository(db)
+
+	//send the repo to the services package
+	searchService := services.InitSearchService(searchRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.SearchHandler{SearchServices: searchService}
+
+	//group user routes
+	users := server.Group("v1/search")
+	users.Use(middlewares.ValidateToken)
+
+	users.GET("", handler.Search)
+}
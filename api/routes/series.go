@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func SeriesRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	seriesRepository := repositories.InitSeriesRepository(db)
+
+	//send the repo to the services package
+	seriesService := services.InitSeriesService(seriesRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.SeriesHandler{SeriesServices: seriesService}
+
+	//group series routes of the logged in user
+	users := server.Group("v1/users/series")
+	users.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	users.POST("", handler.CreateSeries)
+	users.GET("", handler.GetSeriesList)
+	users.PUT("/:series_id", handler.UpdateSeries)
+	users.DELETE("/:series_id", handler.DeleteSeries)
+	users.POST("/:series_id/posts", handler.AddPart)
+	users.PUT("/:series_id/posts", handler.ReorderParts)
+	users.DELETE("/:series_id/posts/:post_id", handler.RemovePart)
+
+	//group series routes which do not need a login
+	series := server.Group("v1/series")
+	series.Use(middlewares.OptionalToken)
+
+	series.GET("/:series_id", handler.GetSeries)
+}
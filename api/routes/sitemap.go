@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func SitemapRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	sitemapRepository := repositories.InitSitemapRepository(db)
+
+	//send the repo to the services package
+	sitemapService := services.InitSitemapService(sitemapRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.SitemapHandler{SitemapServices: sitemapService}
+
+	rateLimit := helpers.EnvInt64("PUBLIC_RATE_LIMIT", int64(constants.DefaultPublicRateLimit))
+	maxAge := helpers.EnvInt64("PUBLIC_CACHE_MAX_AGE", int64(constants.DefaultPublicCacheMaxAge))
+
+	//sitemaps do not need a login
+	server.GET("/sitemap.xml", handler.GetSitemap, middlewares.RateLimitByIP(int(rateLimit)), middlewares.CacheControl(int(maxAge)))
+
+	sitemaps := server.Group("sitemaps")
+	sitemaps.Use(middlewares.RateLimitByIP(int(rateLimit)))
+	sitemaps.Use(middlewares.CacheControl(int(maxAge)))
+
+	sitemaps.GET("/categories.xml", handler.GetCategorySitemap)
+	sitemaps.GET("/posts-:page", handler.GetPostSitemap)
+}
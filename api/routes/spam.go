@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var (
+	spamOnce sync.Once
+	pipeline *spam.Pipeline
+)
+
+func SpamRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	spamRepository := repositories.InitSpamRepository(db)
+
+	//send the repo to the services package
+	spamService := services.InitSpamService(spamRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.SpamHandler{SpamServices: spamService}
+
+	//group spam review routes of the admins and moderators
+	moderation := server.Group("v1/moderation")
+	moderation.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	moderation.GET("/spam", handler.GetSpamChecks)
+	moderation.PUT("/spam/:check_id", handler.ReviewSpamCheck)
+}
+
+// the spam checks are shared by the post, comment and reply routes, the blocklist and the classifier are optional
+func spamPipeline(db *gorm.DB) *spam.Pipeline {
+	spamOnce.Do(func() {
+		history := repositories.InitSpamRepository(db)
+		var checkers []spam.Checker
+
+		if path := os.Getenv("SPAM_BLOCKLIST_FILE"); path != "" {
+			blocklist, err := spam.LoadBlocklist(path)
+			if err != nil {
+				loggers.Error.Fatalln("Failed to load the spam blocklist", err)
+			}
+			checkers = append(checkers, blocklist)
+		}
+
+		checkers = append(checkers,
+			&spam.LinkCount{MaxLinks: int(helpers.EnvInt64("SPAM_MAX_LINKS", int64(constants.DefaultSpamMaxLinks)))},
+			&spam.Duplicate{
+				History:   history,
+				Window:    time.Duration(helpers.EnvInt64("SPAM_DUPLICATE_WINDOW", int64(constants.DefaultSpamDuplicateWindow))) * time.Second,
+				MinLength: int(helpers.EnvInt64("SPAM_DUPLICATE_LENGTH", int64(constants.DefaultSpamDuplicateLength))),
+			},
+			&spam.Velocity{
+				History:    history,
+				AccountAge: time.Duration(helpers.EnvInt64("SPAM_NEW_ACCOUNT_AGE", int64(constants.DefaultSpamNewAccountAge))) * time.Second,
+				Limit:      helpers.EnvInt64("SPAM_NEW_ACCOUNT_LIMIT", int64(constants.DefaultSpamNewAccountLimit)),
+			},
+		)
+
+		if url := os.Getenv("SPAM_CLASSIFIER_URL"); url != "" {
+			timeout := helpers.EnvInt64("SPAM_CLASSIFIER_TIMEOUT", int64(constants.DefaultSpamClassifierTimeout))
+			checkers = append(checkers, spam.NewClassifier(url, helpers.EnvFloat("SPAM_CLASSIFIER_WEIGHT", constants.DefaultSpamClassifierWeight), time.Duration(timeout)*time.Second))
+		}
+
+		pipeline = spam.NewPipeline(helpers.EnvFloat("SPAM_HOLD_SCORE", constants.DefaultSpamHoldScore),
+			helpers.EnvFloat("SPAM_REJECT_SCORE", constants.DefaultSpamRejectScore), checkers...)
+	})
+
+	return pipeline
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func StatsRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	statsRepository := repositories.InitStatsRepository(db)
+
+	//send the repo to the services package
+	statsService := services.InitStatsService(statsRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.StatsHandler{StatsServices: statsService}
+
+	//group stats routes
+	users := server.Group("v1/users/post")
+	users.Use(middlewares.ValidateToken)
+
+	users.GET("/:post_id/stats", handler.GetPostStats)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// reject the writes of suspended users, used after the token was validated
+func rejectSuspended(db *gorm.DB) echo.MiddlewareFunc {
+	return middlewares.RejectSuspended(repositories.InitSuspensionRepository(db).CheckSuspended)
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/handlers"
+	"github.com/marees7/rishi-aug-2024/api/middlewares"
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func TagRoute(server *echo.Echo, db *gorm.DB) {
+	//send the db connection to the repository package
+	tagRepository := repositories.InitTagRepository(db)
+
+	//send the repo to the services package
+	tagService := services.InitTagService(tagRepository)
+
+	//Initialize the handler struct
+	handler := &handlers.TagHandler{TagServices: tagService}
+
+	//group user routes
+	users := server.Group("v1/tags")
+	users.Use(middlewares.ValidateToken)
+
+	users.GET("", handler.GetTags)
+	users.GET("/:slug/posts", handler.GetPostsByTag)
+
+	//group admin routes
+	admin := server.Group("v1/admin/tags")
+	admin.Use(middlewares.ValidateToken, rejectSuspended(db))
+
+	admin.PUT("/:tag_id", handler.RenameTag)
+	admin.POST("/:tag_id/merge", handler.MergeTag)
+}
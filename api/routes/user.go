@@ -31,6 +31,6 @@ func AdminRoute(server *echo.Echo, db *gorm.DB) {
 	user := server.Group("v1/users")
 	user.Use(middlewares.ValidateToken)
 
-	user.PUT("", handler.UpdateUser)
+	user.PUT("", handler.UpdateUser, rejectSuspended(db))
 	user.DELETE("", handler.DeleteUser)
 }
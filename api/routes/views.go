@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/views"
+
+	"gorm.io/gorm"
+)
+
+var (
+	trackerOnce sync.Once
+	tracker     *views.Tracker
+)
+
+// the view tracker is shared by the post and public routes so a visitor is counted once
+func viewTracker(db *gorm.DB) *views.Tracker {
+	trackerOnce.Do(func() {
+		//no window counts every view, the ticker needs a positive interval
+		window := max(helpers.EnvInt64("VIEW_WINDOW", int64(constants.DefaultViewWindow)), 0)
+		interval := max(helpers.EnvInt64("VIEW_FLUSH_INTERVAL", int64(constants.DefaultViewFlushInterval)), 1)
+
+		tracker = views.NewTracker(time.Duration(window)*time.Second, repositories.InitStatsRepository(db).SaveViews)
+		go tracker.Run(time.Duration(interval)*time.Second, func(err error) {
+			loggers.Warn.Println("Failed to store the post views", err)
+		})
+	})
+
+	return tracker
+}
+
+// store the views still buffered, called once the server stopped taking requests
+func FlushViews() {
+	if tracker == nil {
+		return
+	}
+
+	if err := tracker.Flush(); err != nil {
+		loggers.Error.Println("Failed to store the post views", err)
+	}
+}
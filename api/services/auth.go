@@ -2,6 +2,7 @@ package services
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/marees7/rishi-aug-2024/api/repositories"
 	"github.com/marees7/rishi-aug-2024/common/dto"
@@ -49,5 +50,10 @@ func (repo *authService) Login(login *dto.LoginRequest) (*models.User, *dto.Erro
 		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: "password didn't match"}
 	}
 
+	//suspended users cannot log in until their suspension ends
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		return nil, &dto.ErrorResponse{Status: http.StatusForbidden, Error: "account is suspended until " + user.SuspendedUntil.Format(time.RFC3339)}
+	}
+
 	return user, nil
 }
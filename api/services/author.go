@@ -0,0 +1,56 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type AuthorServices interface {
+	GetAuthors(postID uuid.UUID, viewer dto.Viewer) (*[]models.PostAuthor, *dto.ErrorResponse)
+	InviteAuthor(author *models.PostAuthor, username string, viewer dto.Viewer) *dto.ErrorResponse
+	RemoveAuthor(postID uuid.UUID, username string, viewer dto.Viewer) *dto.ErrorResponse
+	GetInvitations(userID uuid.UUID, limit, offset int) (*[]models.PostAuthor, int64, *dto.ErrorResponse)
+	AcceptInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+	DeclineInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+}
+
+type authorService struct {
+	repositories.AuthorRepository
+}
+
+func InitAuthorService(author repositories.AuthorRepository) AuthorServices {
+	return &authorService{author}
+}
+
+// retrieve the authors of a post
+func (repo *authorService) GetAuthors(postID uuid.UUID, viewer dto.Viewer) (*[]models.PostAuthor, *dto.ErrorResponse) {
+	return repo.AuthorRepository.GetAuthors(postID, viewer)
+}
+
+// invite a user to write the post
+func (repo *authorService) InviteAuthor(author *models.PostAuthor, username string, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.AuthorRepository.InviteAuthor(author, username, viewer)
+}
+
+// remove an author of the post
+func (repo *authorService) RemoveAuthor(postID uuid.UUID, username string, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.AuthorRepository.RemoveAuthor(postID, username, viewer)
+}
+
+// retrieve the pending invitations of the user
+func (repo *authorService) GetInvitations(userID uuid.UUID, limit, offset int) (*[]models.PostAuthor, int64, *dto.ErrorResponse) {
+	return repo.AuthorRepository.GetInvitations(userID, limit, offset)
+}
+
+// accept the invitation to write the post
+func (repo *authorService) AcceptInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	return repo.AuthorRepository.AcceptInvitation(userID, postID)
+}
+
+// decline the invitation to write the post
+func (repo *authorService) DeclineInvitation(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	return repo.AuthorRepository.DeclineInvitation(userID, postID)
+}
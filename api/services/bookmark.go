@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type BookmarkServices interface {
+	CreateBookmark(bookmark *models.Bookmark, collection string, viewer dto.Viewer) *dto.ErrorResponse
+	GetBookmarks(userID uuid.UUID, collectionID uuid.UUID, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Bookmark, int64, *dto.ErrorResponse)
+	DeleteBookmark(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse
+	GetCollections(userID uuid.UUID) (*[]models.BookmarkCollection, *dto.ErrorResponse)
+	DeleteCollection(userID uuid.UUID, collectionID uuid.UUID) *dto.ErrorResponse
+}
+
+type bookmarkService struct {
+	repositories.BookmarkRepository
+}
+
+func InitBookmarkService(bookmark repositories.BookmarkRepository) BookmarkServices {
+	return &bookmarkService{bookmark}
+}
+
+// bookmark a post, optionally into a named collection
+func (repo *bookmarkService) CreateBookmark(bookmark *models.Bookmark, collection string, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.BookmarkRepository.CreateBookmark(bookmark, collection, viewer)
+}
+
+// retrieve the bookmarks of the user
+func (repo *bookmarkService) GetBookmarks(userID uuid.UUID, collectionID uuid.UUID, sort string, limit, offset int, viewer dto.Viewer) (*[]models.Bookmark, int64, *dto.ErrorResponse) {
+	return repo.BookmarkRepository.GetBookmarks(userID, collectionID, sort, limit, offset, viewer)
+}
+
+// remove the bookmark of a post
+func (repo *bookmarkService) DeleteBookmark(userID uuid.UUID, postID uuid.UUID) *dto.ErrorResponse {
+	return repo.BookmarkRepository.DeleteBookmark(userID, postID)
+}
+
+// retrieve the bookmark collections of the user
+func (repo *bookmarkService) GetCollections(userID uuid.UUID) (*[]models.BookmarkCollection, *dto.ErrorResponse) {
+	return repo.BookmarkRepository.GetCollections(userID)
+}
+
+// delete a bookmark collection
+func (repo *bookmarkService) DeleteCollection(userID uuid.UUID, collectionID uuid.UUID) *dto.ErrorResponse {
+	return repo.BookmarkRepository.DeleteCollection(userID, collectionID)
+}
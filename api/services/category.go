@@ -11,6 +11,7 @@ import (
 type CategoryServices interface {
 	CreateCategory(category *models.Category) *dto.ErrorResponse
 	GetCategories(limit, offset int) (*[]models.Category, *dto.ErrorResponse, int64)
+	GetCategoryBySlug(slug string) (*models.Category, bool, *dto.ErrorResponse)
 	UpdateCategory(category *models.Category, categoryID uuid.UUID) *dto.ErrorResponse
 	DeleteCategory(categoryID uuid.UUID) *dto.ErrorResponse
 }
@@ -33,6 +34,11 @@ func (repo *userService) GetCategories(limit, offset int) (*[]models.Category, *
 	return repo.Category.GetCategories(limit, offset)
 }
 
+// retrieve a category using its current or old slug
+func (repo *userService) GetCategoryBySlug(slug string) (*models.Category, bool, *dto.ErrorResponse) {
+	return repo.Category.GetCategoryBySlug(slug)
+}
+
 // update a existing category
 func (repo *userService) UpdateCategory(category *models.Category, categoryID uuid.UUID) *dto.ErrorResponse {
 	return repo.Category.UpdateCategory(category, categoryID)
@@ -1,41 +1,61 @@
 package services
 
 import (
+	"context"
+
 	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
 
 	"github.com/google/uuid"
 )
 
 type CommentServices interface {
-	CreateComment(comment *models.Comment) *dto.ErrorResponse
-	GetComments(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Comment, *dto.ErrorResponse, int64)
-	UpdateComment(comment *models.Comment, commentID uuid.UUID) *dto.ErrorResponse
+	CreateComment(ctx context.Context, comment *models.Comment, viewer dto.Viewer) *dto.ErrorResponse
+	GetComments(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Comment, *dto.ErrorResponse, int64)
+	GetThread(commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse)
+	UpdateComment(ctx context.Context, comment *models.Comment, commentID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
 	DeleteComment(userID uuid.UUID, commentID uuid.UUID, role string) *dto.ErrorResponse
 }
 
 type commentService struct {
 	repositories.CommentRepository
+	Spam *spam.Pipeline
 }
 
-func InitCommentService(comment repositories.CommentRepository) CommentServices {
-	return &commentService{comment}
+func InitCommentService(comment repositories.CommentRepository, pipeline *spam.Pipeline) CommentServices {
+	return &commentService{comment, pipeline}
 }
 
-// create a new comment
-func (repo *commentService) CreateComment(comment *models.Comment) *dto.ErrorResponse {
-	return repo.CommentRepository.CreateComment(comment)
+// create a new comment once the spam checks scored it
+func (repo *commentService) CreateComment(ctx context.Context, comment *models.Comment, viewer dto.Viewer) *dto.ErrorResponse {
+	check := checkSpam(ctx, repo.Spam, viewer, spam.Content{Type: constants.ReactionTargetComment, AuthorID: comment.UserID, Text: comment.Content})
+
+	return repo.CommentRepository.CreateComment(comment, viewer, check)
 }
 
 // retrieve comments using post id
-func (repo *commentService) GetComments(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Comment, *dto.ErrorResponse, int64) {
-	return repo.CommentRepository.GetComments(postID, keywords)
+func (repo *commentService) GetComments(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Comment, *dto.ErrorResponse, int64) {
+	return repo.CommentRepository.GetComments(postID, keywords, viewer)
+}
+
+// retrieve a comment along with its answers
+func (repo *commentService) GetThread(commentID uuid.UUID, sort string, viewer dto.Viewer) (*models.Comment, *dto.ErrorResponse) {
+	return repo.CommentRepository.GetThread(commentID, sort, viewer)
 }
 
 // update a existing comment
-func (repo *commentService) UpdateComment(comment *models.Comment, commentID uuid.UUID) *dto.ErrorResponse {
-	return repo.CommentRepository.UpdateComment(comment, commentID)
+func (repo *commentService) UpdateComment(ctx context.Context, comment *models.Comment, commentID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	//the edits are checked for spam like new comments
+	pipeline := repo.Spam
+	if comment.Content == "" {
+		pipeline = nil
+	}
+	check := checkSpam(ctx, pipeline, viewer, spam.Content{Type: constants.ReactionTargetComment, AuthorID: comment.UserID, Text: comment.Content})
+
+	return repo.CommentRepository.UpdateComment(comment, commentID, viewer, check)
 }
 
 // delete the existing comment
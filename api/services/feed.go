@@ -0,0 +1,117 @@
+package services
+
+import (
+	"os"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/feeds"
+)
+
+type FeedServices interface {
+	GetFeed(filter dto.PublicPostFilter, feedURL string) (*feeds.Feed, *dto.ErrorResponse)
+}
+
+type feedService struct {
+	repositories.PublicRepository
+}
+
+func InitFeedService(public repositories.PublicRepository) FeedServices {
+	return &feedService{public}
+}
+
+// build the feed of the latest published posts matching the filter
+func (repo *feedService) GetFeed(filter dto.PublicPostFilter, feedURL string) (*feeds.Feed, *dto.ErrorResponse) {
+	siteTitle := os.Getenv("SITE_TITLE")
+	if siteTitle == "" {
+		siteTitle = constants.DefaultSiteTitle
+	}
+
+	feed := feeds.Feed{
+		Title:       siteTitle,
+		Description: os.Getenv("SITE_DESCRIPTION"),
+		Link:        helpers.SiteURL(),
+		FeedURL:     feedURL,
+	}
+
+	//name the feed after the category, tag or author it is limited to
+	switch {
+	case filter.Category != "":
+		category, errorResponse := repo.PublicRepository.GetCategory(filter.Category)
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+		feed.Title = category.CategoryName + " - " + siteTitle
+		feed.Description = category.Description
+		feed.Link = helpers.SiteLink("categories", category.Slug)
+	case filter.Tag != "":
+		tag, errorResponse := repo.PublicRepository.GetTag(filter.Tag)
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+		feed.Title = "#" + tag.Name + " - " + siteTitle
+		feed.Link = helpers.SiteLink("tags", tag.Slug)
+	case filter.Author != "":
+		author, _, errorResponse := repo.PublicRepository.GetAuthor(filter.Author)
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+		feed.Title = author.Name + " - " + siteTitle
+		feed.Link = helpers.SiteLink("authors", author.Username)
+	}
+
+	//the pinned posts are not put ahead so old pins cannot push the latest posts out of the feed
+	filter.Limit = int(helpers.EnvInt64("FEED_LIMIT", int64(constants.DefaultFeedLimit)))
+	filter.Offset = 0
+	filter.ByDate = true
+
+	//feeds are read anonymously so members only posts are left out
+	posts, _, errorResponse := repo.PublicRepository.GetPosts(filter, dto.Viewer{})
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	//the feed is as recent as its most recently updated post
+	for _, post := range *posts {
+		//password protected posts have nothing to show in a feed
+		if post.Locked {
+			continue
+		}
+
+		item := feeds.Item{
+			ID:          "urn:uuid:" + post.PostID.String(),
+			Title:       post.Title,
+			Link:        helpers.SiteLink("posts", post.Slug),
+			Summary:     post.Description,
+			ContentHTML: post.ContentHTML,
+			Published:   post.CreatedAt,
+			Updated:     post.UpdatedAt,
+		}
+
+		if post.User != nil {
+			item.Author = post.User.Name
+		}
+		if post.Category != nil {
+			item.Categories = append(item.Categories, post.Category.CategoryName)
+		}
+		for _, tag := range post.Tags {
+			item.Categories = append(item.Categories, tag.Name)
+		}
+
+		if post.UpdatedAt.After(feed.Updated) {
+			feed.Updated = post.UpdatedAt
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	//a feed without posts has no date of its own, the feed formats still need one
+	if len(feed.Items) == 0 {
+		feed.Updated = time.Now()
+	}
+
+	return &feed, nil
+}
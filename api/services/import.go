@@ -0,0 +1,59 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/importer"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type ImportServices interface {
+	StartImport(job *models.ImportJob, document *importer.Document) (bool, *dto.ErrorResponse)
+	GetImports(limit, offset int) (*[]models.ImportJob, int64, *dto.ErrorResponse)
+	GetImport(jobID uuid.UUID) (*models.ImportJob, *dto.ErrorResponse)
+	GetImportItems(jobID uuid.UUID, action string, limit, offset int) (*[]models.ImportItem, int64, *dto.ErrorResponse)
+}
+
+type importService struct {
+	repositories.ImportRepository
+}
+
+func InitImportService(imports repositories.ImportRepository) ImportServices {
+	return &importService{imports}
+}
+
+// start importing the document in the background, true when the same file was already imported
+func (repo *importService) StartImport(job *models.ImportJob, document *importer.Document) (bool, *dto.ErrorResponse) {
+	imported, errorResponse := repo.ImportRepository.StartImport(job)
+	if errorResponse != nil || imported {
+		return imported, errorResponse
+	}
+
+	//the job keeps running after the response is sent, so it updates its own copy
+	running := *job
+	go func() {
+		if err := repo.ImportRepository.RunImport(&running, document); err != nil {
+			loggers.Error.Println(err)
+		}
+	}()
+
+	return false, nil
+}
+
+// retrieve the import jobs
+func (repo *importService) GetImports(limit, offset int) (*[]models.ImportJob, int64, *dto.ErrorResponse) {
+	return repo.ImportRepository.GetImports(limit, offset)
+}
+
+// retrieve an import job
+func (repo *importService) GetImport(jobID uuid.UUID) (*models.ImportJob, *dto.ErrorResponse) {
+	return repo.ImportRepository.GetImport(jobID)
+}
+
+// retrieve the outcome of the items of an import job
+func (repo *importService) GetImportItems(jobID uuid.UUID, action string, limit, offset int) (*[]models.ImportItem, int64, *dto.ErrorResponse) {
+	return repo.ImportRepository.GetImportItems(jobID, action, limit, offset)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/imaging"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/storage"
+
+	"github.com/google/uuid"
+)
+
+type MediaServices interface {
+	UploadMedia(userID uuid.UUID, fileName string, contentType string, extension string, content []byte) (*models.Media, *dto.ErrorResponse)
+	GetMedia(userID uuid.UUID, limit, offset int) (*[]models.Media, int64, *dto.ErrorResponse)
+	DeleteMedia(mediaID uuid.UUID, userID uuid.UUID, role string) *dto.ErrorResponse
+}
+
+type mediaService struct {
+	repositories.MediaRepository
+	Storage storage.Storage
+}
+
+func InitMediaService(media repositories.MediaRepository, storage storage.Storage) MediaServices {
+	return &mediaService{media, storage}
+}
+
+// stores the file along with its thumbnail if the user has enough quota left
+func (repo *mediaService) UploadMedia(userID uuid.UUID, fileName string, contentType string, extension string, content []byte) (*models.Media, *dto.ErrorResponse) {
+	ctx := context.Background()
+
+	media := models.Media{
+		MediaID:     uuid.New(),
+		UserID:      userID,
+		FileName:    path.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+	}
+	media.StorageKey = fmt.Sprintf("%s/%s%s", userID, media.MediaID, extension)
+
+	//refuse the images with too many pixels before anything decodes them
+	maxPixels := helpers.EnvInt64("MEDIA_MAX_PIXELS", constants.DefaultMediaMaxPixels)
+	isImage := false
+	if strings.HasPrefix(contentType, "image/") {
+		width, height, err := imaging.Dimensions(bytes.NewReader(content), maxPixels)
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, &dto.ErrorResponse{Status: http.StatusRequestEntityTooLarge, Error: err.Error()}
+		} else if err != nil {
+			loggers.Warn.Println("could not read the image dimensions:", err)
+		} else {
+			media.Width, media.Height = width, height
+			isImage = true
+		}
+	}
+
+	//check the quota early so files over it are never stored, it is checked again when the details are saved
+	quota := helpers.EnvInt64("MEDIA_USER_QUOTA", constants.DefaultMediaUserQuota)
+	usage, errorResponse := repo.MediaRepository.GetUsage(userID)
+	if errorResponse != nil {
+		return nil, errorResponse
+	} else if usage+media.Size > quota {
+		return nil, quotaExceeded(quota)
+	}
+
+	if err := repo.Storage.Put(ctx, media.StorageKey, bytes.NewReader(content), media.Size, contentType); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	media.URL = repo.Storage.URL(media.StorageKey)
+
+	//generate a thumbnail for the images
+	if isImage {
+		thumbnail, thumbnailType, err := imaging.Thumbnail(content, constants.ThumbnailSize, maxPixels)
+		if err != nil {
+			loggers.Warn.Println("could not generate thumbnail:", err)
+		} else {
+			media.ThumbnailKey = fmt.Sprintf("%s/%s_thumb%s", userID, media.MediaID, thumbnailExtension(thumbnailType))
+			if err := repo.Storage.Put(ctx, media.ThumbnailKey, thumbnail, int64(thumbnail.Len()), thumbnailType); err != nil {
+				repo.deleteFiles(media)
+				return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+			}
+			media.ThumbnailURL = repo.Storage.URL(media.ThumbnailKey)
+		}
+	}
+
+	//remove the stored files if the details could not be saved or another upload used up the quota meanwhile
+	if errorResponse := repo.MediaRepository.CreateMedia(&media, quota); errorResponse != nil {
+		repo.deleteFiles(media)
+		if errorResponse.Status == http.StatusRequestEntityTooLarge {
+			return nil, quotaExceeded(quota)
+		}
+		return nil, errorResponse
+	}
+
+	return &media, nil
+}
+
+// retrieve the files uploaded by the user
+func (repo *mediaService) GetMedia(userID uuid.UUID, limit, offset int) (*[]models.Media, int64, *dto.ErrorResponse) {
+	return repo.MediaRepository.GetMedia(userID, limit, offset)
+}
+
+// delete the file details along with the stored files
+func (repo *mediaService) DeleteMedia(mediaID uuid.UUID, userID uuid.UUID, role string) *dto.ErrorResponse {
+	media, errorResponse := repo.MediaRepository.DeleteMedia(mediaID, userID, role)
+	if errorResponse != nil {
+		return errorResponse
+	}
+
+	repo.deleteFiles(*media)
+	return nil
+}
+
+// remove the stored file and its thumbnail, failures only leave unused files behind
+func (repo *mediaService) deleteFiles(media models.Media) {
+	deleteMediaFiles(repo.Storage, media)
+}
+
+// remove the stored file and its thumbnail from the storage
+func deleteMediaFiles(store storage.Storage, media models.Media) {
+	for _, key := range []string{media.StorageKey, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+
+		if err := store.Delete(context.Background(), key); err != nil {
+			loggers.Warn.Println("could not delete", key, err)
+		}
+	}
+}
+
+// file extension of the thumbnail content type
+func thumbnailExtension(contentType string) string {
+	if contentType == "image/png" {
+		return ".png"
+	}
+
+	return ".jpg"
+}
+
+// error returned when the upload does not fit in the storage quota of the user
+func quotaExceeded(quota int64) *dto.ErrorResponse {
+	return &dto.ErrorResponse{Status: http.StatusRequestEntityTooLarge, Error: fmt.Sprintf("upload exceeds the storage quota of %d bytes", quota)}
+}
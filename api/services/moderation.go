@@ -0,0 +1,56 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type ModerationServices interface {
+	GetQueue(status string, category string, limit, offset int) (*[]models.Comment, int64, *dto.ErrorResponse)
+	ModerateComments(moderation *dto.CommentModeration, moderatorID uuid.UUID) (int64, *dto.ErrorResponse)
+	GetRules() (*[]models.ModerationRule, *dto.ErrorResponse)
+	SaveRule(rule *models.ModerationRule) *dto.ErrorResponse
+	DeleteRule(ruleID uuid.UUID) *dto.ErrorResponse
+	GetRevisions(commentID uuid.UUID) (*[]models.CommentRevision, *dto.ErrorResponse)
+}
+
+type moderationService struct {
+	repositories.ModerationRepository
+}
+
+func InitModerationService(moderation repositories.ModerationRepository) ModerationServices {
+	return &moderationService{moderation}
+}
+
+// retrieve the comments waiting for moderation, or the ones with another status
+func (repo *moderationService) GetQueue(status string, category string, limit, offset int) (*[]models.Comment, int64, *dto.ErrorResponse) {
+	return repo.ModerationRepository.GetQueue(status, category, limit, offset)
+}
+
+// approve or reject comments in bulk
+func (repo *moderationService) ModerateComments(moderation *dto.CommentModeration, moderatorID uuid.UUID) (int64, *dto.ErrorResponse) {
+	return repo.ModerationRepository.ModerateComments(moderation, moderatorID)
+}
+
+// retrieve the moderation rules
+func (repo *moderationService) GetRules() (*[]models.ModerationRule, *dto.ErrorResponse) {
+	return repo.ModerationRepository.GetRules()
+}
+
+// create or replace the moderation rules of a category or of the site
+func (repo *moderationService) SaveRule(rule *models.ModerationRule) *dto.ErrorResponse {
+	return repo.ModerationRepository.SaveRule(rule)
+}
+
+// delete moderation rules
+func (repo *moderationService) DeleteRule(ruleID uuid.UUID) *dto.ErrorResponse {
+	return repo.ModerationRepository.DeleteRule(ruleID)
+}
+
+// retrieve the previous versions of a comment
+func (repo *moderationService) GetRevisions(commentID uuid.UUID) (*[]models.CommentRevision, *dto.ErrorResponse) {
+	return repo.ModerationRepository.GetRevisions(commentID)
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type NotificationServices interface {
+	GetNotifications(userID uuid.UUID, unread bool, limit, offset int) (*[]models.Notification, int64, *dto.ErrorResponse)
+	ReadNotifications(userID uuid.UUID, notificationID uuid.UUID) (int64, *dto.ErrorResponse)
+}
+
+type notificationService struct {
+	repositories.NotificationRepository
+}
+
+func InitNotificationService(notification repositories.NotificationRepository) NotificationServices {
+	return &notificationService{notification}
+}
+
+// retrieve the notifications of the user
+func (repo *notificationService) GetNotifications(userID uuid.UUID, unread bool, limit, offset int) (*[]models.Notification, int64, *dto.ErrorResponse) {
+	return repo.NotificationRepository.GetNotifications(userID, unread, limit, offset)
+}
+
+// mark the notifications of the user as read
+func (repo *notificationService) ReadNotifications(userID uuid.UUID, notificationID uuid.UUID) (int64, *dto.ErrorResponse) {
+	return repo.NotificationRepository.ReadNotifications(userID, notificationID)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type PinServices interface {
+	PinPost(pin *models.PostPin, position *int) *dto.ErrorResponse
+	UnpinPost(postID uuid.UUID, scope string) *dto.ErrorResponse
+	GetPins(scope string, category string, limit, offset int) (*[]models.PostPin, int64, *dto.ErrorResponse)
+}
+
+type pinService struct {
+	repositories.PinRepository
+}
+
+func InitPinService(pin repositories.PinRepository) PinServices {
+	return &pinService{pin}
+}
+
+// pin a post on top of the listing
+func (repo *pinService) PinPost(pin *models.PostPin, position *int) *dto.ErrorResponse {
+	return repo.PinRepository.PinPost(pin, position)
+}
+
+// remove the pin of a post
+func (repo *pinService) UnpinPost(postID uuid.UUID, scope string) *dto.ErrorResponse {
+	return repo.PinRepository.UnpinPost(postID, scope)
+}
+
+// retrieve the pinned posts in their order
+func (repo *pinService) GetPins(scope string, category string, limit, offset int) (*[]models.PostPin, int64, *dto.ErrorResponse) {
+	return repo.PinRepository.GetPins(scope, category, limit, offset)
+}
@@ -1,50 +1,140 @@
 package services
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
 	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
+	"github.com/marees7/rishi-aug-2024/pkg/storage"
+	"github.com/marees7/rishi-aug-2024/pkg/views"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type PostServices interface {
-	CreatePost(post *models.Post) *dto.ErrorResponse
-	GetPosts(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Post, int64, error)
-	GetPost(postID uuid.UUID) (*models.Post, *dto.ErrorResponse)
-	UpdatePost(post *models.Post, postID uuid.UUID) *dto.ErrorResponse
+	CreatePost(ctx context.Context, post *models.Post, viewer dto.Viewer) *dto.ErrorResponse
+	GetPosts(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Post, int64, error)
+	GetPost(postID uuid.UUID, viewer dto.Viewer, visit dto.Visit) (*models.Post, *dto.ErrorResponse)
+	GetPostBySlug(slug string, viewer dto.Viewer, visit dto.Visit) (*models.Post, bool, *dto.ErrorResponse)
+	UpdatePost(ctx context.Context, post *models.Post, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
 	DeletePost(userID uuid.UUID, postID uuid.UUID, role string) *dto.ErrorResponse
+	LockComments(postID uuid.UUID, locked bool, viewer dto.Viewer) *dto.ErrorResponse
 }
 
 type postService struct {
 	repositories.PostRepository
+	Media   repositories.MediaRepository
+	Storage storage.Storage
+	Views   *views.Tracker
+	Spam    *spam.Pipeline
 }
 
-func InitPostService(post repositories.PostRepository) PostServices {
-	return &postService{post}
+func InitPostService(post repositories.PostRepository, media repositories.MediaRepository, storage storage.Storage, tracker *views.Tracker, pipeline *spam.Pipeline) PostServices {
+	return &postService{post, media, storage, tracker, pipeline}
 }
 
-// create a new post
-func (repo postService) CreatePost(post *models.Post) *dto.ErrorResponse {
-	return repo.PostRepository.CreatePost(post)
+// create a new post once the spam checks scored it
+func (repo postService) CreatePost(ctx context.Context, post *models.Post, viewer dto.Viewer) *dto.ErrorResponse {
+	if errorResponse := hashPostPassword(post); errorResponse != nil {
+		return errorResponse
+	}
+
+	check := checkSpam(ctx, repo.Spam, viewer, spam.Content{Type: constants.ReactionTargetPost, AuthorID: post.UserID, Title: post.Title, Text: post.Content})
+
+	return repo.PostRepository.CreatePost(post, check)
 }
 
 // retrieve every users posts using date or post id
-func (repo postService) GetPosts(postID uuid.UUID, keywords map[string]interface{}) (*[]models.Post, int64, error) {
-	return repo.PostRepository.GetPosts(postID, keywords)
+func (repo postService) GetPosts(postID uuid.UUID, keywords map[string]interface{}, viewer dto.Viewer) (*[]models.Post, int64, error) {
+	return repo.PostRepository.GetPosts(postID, keywords, viewer)
 }
 
 // retrieve single user posts using title or post id
-func (repo postService) GetPost(postID uuid.UUID) (*models.Post, *dto.ErrorResponse) {
-	return repo.PostRepository.GetPost(postID)
+func (repo postService) GetPost(postID uuid.UUID, viewer dto.Viewer, visit dto.Visit) (*models.Post, *dto.ErrorResponse) {
+	post, errorResponse := repo.PostRepository.GetPost(postID, viewer)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	recordView(repo.Views, post, viewer, visit)
+	return post, nil
+}
+
+// retrieve a post using its current or old slug
+func (repo postService) GetPostBySlug(slug string, viewer dto.Viewer, visit dto.Visit) (*models.Post, bool, *dto.ErrorResponse) {
+	post, moved, errorResponse := repo.PostRepository.GetPostBySlug(slug, viewer)
+	if errorResponse != nil {
+		return nil, false, errorResponse
+	}
+
+	//the view is counted once the old slug is redirected
+	if !moved {
+		recordView(repo.Views, post, viewer, visit)
+	}
+
+	return post, moved, nil
 }
 
 // update a existing post
-func (repo postService) UpdatePost(post *models.Post, postID uuid.UUID) *dto.ErrorResponse {
-	return repo.PostRepository.UpdatePost(post, postID)
+func (repo postService) UpdatePost(ctx context.Context, post *models.Post, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	if errorResponse := hashPostPassword(post); errorResponse != nil {
+		return errorResponse
+	}
+
+	//only the edited title and content are checked for spam
+	pipeline := repo.Spam
+	if post.Title == "" && post.Content == "" {
+		pipeline = nil
+	}
+	check := checkSpam(ctx, pipeline, viewer, spam.Content{Type: constants.ReactionTargetPost, AuthorID: post.UserID, Title: post.Title, Text: post.Content})
+
+	return repo.PostRepository.UpdatePost(post, postID, check)
 }
 
 // delete a existing post
 func (repo postService) DeletePost(userID uuid.UUID, postID uuid.UUID, role string) *dto.ErrorResponse {
-	return repo.PostRepository.DeletePost(userID, postID, role)
+	if errorResponse := repo.PostRepository.DeletePost(userID, postID, role); errorResponse != nil {
+		return errorResponse
+	}
+
+	//remove the media which is no longer used by any post
+	orphans, err := repo.Media.DeleteOrphanedMedia(postID)
+	if err != nil {
+		loggers.Warn.Println("could not delete the media of the post:", err)
+		return nil
+	}
+
+	for _, media := range orphans {
+		deleteMediaFiles(repo.Storage, media)
+	}
+
+	return nil
+}
+
+// hash the password of the post, the plain password is never stored or returned
+func hashPostPassword(post *models.Post) *dto.ErrorResponse {
+	if post.Password == "" {
+		return nil
+	}
+
+	hashedPass, err := bcrypt.GenerateFromPassword([]byte(post.Password), 10)
+	if err != nil {
+		return &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: "could not hash the post password"}
+	}
+
+	post.PasswordHash = string(hashedPass)
+	post.Password = ""
+
+	return nil
+}
+
+// lock or unlock the comments on a post
+func (repo postService) LockComments(postID uuid.UUID, locked bool, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.PostRepository.LockComments(postID, locked, viewer)
 }
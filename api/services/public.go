@@ -0,0 +1,291 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/views"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type PublicServices interface {
+	GetPosts(filter dto.PublicPostFilter, viewer dto.Viewer) ([]dto.PublicPost, int64, *dto.ErrorResponse)
+	GetPost(slug string, viewer dto.Viewer, visit dto.Visit) (*dto.PublicPost, bool, *dto.ErrorResponse)
+	UnlockPost(slug string, password string) (*dto.PostGrant, *dto.ErrorResponse)
+	GetComments(slug string, sort string, limit, offset int, viewer dto.Viewer) ([]dto.PublicComment, int64, *dto.ErrorResponse)
+	GetThread(slug string, commentID uuid.UUID, sort string, viewer dto.Viewer) (*dto.PublicComment, *dto.ErrorResponse)
+	GetCategories(limit, offset int) ([]dto.PublicCategory, int64, *dto.ErrorResponse)
+	GetAuthor(username string) (*dto.PublicAuthorProfile, *dto.ErrorResponse)
+}
+
+type publicService struct {
+	repositories.PublicRepository
+	Views *views.Tracker
+}
+
+func InitPublicService(public repositories.PublicRepository, tracker *views.Tracker) PublicServices {
+	return &publicService{public, tracker}
+}
+
+// retrieve the published posts without the private details of their authors
+func (repo *publicService) GetPosts(filter dto.PublicPostFilter, viewer dto.Viewer) ([]dto.PublicPost, int64, *dto.ErrorResponse) {
+	posts, count, errorResponse := repo.PublicRepository.GetPosts(filter, viewer)
+	if errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	publicPosts := make([]dto.PublicPost, 0, len(*posts))
+	for i := range *posts {
+		publicPosts = append(publicPosts, toPublicPost(&(*posts)[i]))
+	}
+
+	return publicPosts, count, nil
+}
+
+// retrieve a published post using its current or old slug
+func (repo *publicService) GetPost(slug string, viewer dto.Viewer, visit dto.Visit) (*dto.PublicPost, bool, *dto.ErrorResponse) {
+	post, moved, errorResponse := repo.PublicRepository.GetPost(slug, viewer)
+	if errorResponse != nil {
+		return nil, false, errorResponse
+	}
+
+	//the view is counted once the old slug is redirected
+	if !moved {
+		recordView(repo.Views, post, viewer, visit)
+	}
+
+	publicPost := toPublicPost(post)
+	return &publicPost, moved, nil
+}
+
+// check the password of a protected post and issue a grant to read it
+func (repo *publicService) UnlockPost(slug string, password string) (*dto.PostGrant, *dto.ErrorResponse) {
+	post, errorResponse := repo.PublicRepository.GetProtectedPost(slug)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(post.PasswordHash), []byte(password)); err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusUnauthorized, Error: "incorrect password"}
+	}
+
+	ttl := time.Duration(helpers.EnvInt64("POST_GRANT_TTL", int64(constants.DefaultPostGrantTTL))) * time.Second
+	grant, err := validation.GeneratePostGrant(post.PostID, ttl)
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return grant, nil
+}
+
+// retrieve the threads of a published post without the private details of their authors
+func (repo *publicService) GetComments(slug string, sort string, limit, offset int, viewer dto.Viewer) ([]dto.PublicComment, int64, *dto.ErrorResponse) {
+	comments, count, errorResponse := repo.PublicRepository.GetComments(slug, sort, limit, offset, viewer)
+	if errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	publicComments := make([]dto.PublicComment, 0, len(*comments))
+	for i := range *comments {
+		publicComments = append(publicComments, toPublicComment(&(*comments)[i]))
+	}
+
+	return publicComments, count, nil
+}
+
+// retrieve a comment of a published post along with its answers without the private details of their authors
+func (repo *publicService) GetThread(slug string, commentID uuid.UUID, sort string, viewer dto.Viewer) (*dto.PublicComment, *dto.ErrorResponse) {
+	comment, errorResponse := repo.PublicRepository.GetThread(slug, commentID, sort, viewer)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	publicComment := toPublicComment(comment)
+	return &publicComment, nil
+}
+
+// retrieve every category
+func (repo *publicService) GetCategories(limit, offset int) ([]dto.PublicCategory, int64, *dto.ErrorResponse) {
+	categories, count, errorResponse := repo.PublicRepository.GetCategories(limit, offset)
+	if errorResponse != nil {
+		return nil, 0, errorResponse
+	}
+
+	publicCategories := make([]dto.PublicCategory, 0, len(*categories))
+	for i := range *categories {
+		publicCategories = append(publicCategories, *toPublicCategory(&(*categories)[i]))
+	}
+
+	return publicCategories, count, nil
+}
+
+// retrieve the public profile of an author
+func (repo *publicService) GetAuthor(username string) (*dto.PublicAuthorProfile, *dto.ErrorResponse) {
+	user, count, errorResponse := repo.PublicRepository.GetAuthor(username)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	return &dto.PublicAuthorProfile{
+		Username:  user.Username,
+		Name:      user.Name,
+		PostCount: count,
+		JoinedAt:  user.CreatedAt,
+	}, nil
+}
+
+// copy the post details which are safe to show to anonymous visitors
+func toPublicPost(post *models.Post) dto.PublicPost {
+	publicPost := dto.PublicPost{
+		Slug:           post.Slug,
+		Title:          post.Title,
+		Description:    post.Description,
+		Content:        post.Content,
+		ContentFormat:  post.ContentFormat,
+		ContentHTML:    post.ContentHTML,
+		Author:         toPublicAuthor(post.User),
+		Category:       toPublicCategory(post.Category),
+		Reactions:      post.Reactions,
+		ViewCount:      post.ViewCount,
+		Visibility:     post.Visibility,
+		Locked:         post.Locked,
+		Pinned:         post.Pinned,
+		CommentsLocked: post.CommentsLocked,
+		SEO:            toPublicSEO(post),
+		CreatedAt:      post.CreatedAt,
+		UpdatedAt:      post.UpdatedAt,
+	}
+
+	if post.Series != nil {
+		publicPost.Series = &dto.PublicSeries{
+			SeriesID: post.Series.SeriesID,
+			Title:    post.Series.Title,
+			Position: post.Series.Position,
+			Total:    post.Series.Total,
+			Previous: toPublicSeriesPart(post.Series.Previous),
+			Next:     toPublicSeriesPart(post.Series.Next),
+		}
+	}
+
+	for _, author := range post.Authors {
+		publicPost.Authors = append(publicPost.Authors, dto.PublicAuthor{Username: author.Username, Name: author.Name, Role: author.Role})
+	}
+
+	for _, tag := range post.Tags {
+		publicPost.Tags = append(publicPost.Tags, dto.PublicTag{Name: tag.Name, Slug: tag.Slug})
+	}
+
+	for _, media := range post.Media {
+		publicPost.Media = append(publicPost.Media, dto.PublicMedia{
+			FileName:     media.FileName,
+			ContentType:  media.ContentType,
+			Width:        media.Width,
+			Height:       media.Height,
+			URL:          media.URL,
+			ThumbnailURL: media.ThumbnailURL,
+		})
+	}
+
+	return publicPost
+}
+
+// copy the search engine metadata, deriving the missing values from the post
+func toPublicSEO(post *models.Post) dto.PublicSEO {
+	seo := dto.PublicSEO{
+		MetaTitle:       post.MetaTitle,
+		MetaDescription: post.MetaDescription,
+		CanonicalURL:    post.CanonicalURL,
+		OGImage:         post.OGImage,
+		NoIndex:         post.NoIndex != nil && *post.NoIndex,
+	}
+
+	if seo.MetaTitle == "" {
+		seo.MetaTitle = helpers.Truncate(post.Title, constants.MaxMetaTitleLength)
+	}
+	if seo.MetaDescription == "" {
+		seo.MetaDescription = helpers.Truncate(post.Description, constants.MaxMetaDescriptionLength)
+	}
+	if seo.CanonicalURL == "" {
+		seo.CanonicalURL = helpers.SiteLink("posts", post.Slug)
+	}
+
+	//use the first attached image when no open graph image was chosen
+	if seo.OGImage == "" {
+		for _, media := range post.Media {
+			if strings.HasPrefix(media.ContentType, "image/") {
+				seo.OGImage = media.URL
+				if strings.HasPrefix(seo.OGImage, "/") {
+					seo.OGImage = helpers.SiteURL() + seo.OGImage
+				}
+				break
+			}
+		}
+	}
+
+	return seo
+}
+
+// only the names of the author are shown, deleted authors are left out
+func toPublicAuthor(user *models.User) *dto.PublicAuthor {
+	if user == nil {
+		return nil
+	}
+
+	return &dto.PublicAuthor{Username: user.Username, Name: user.Name}
+}
+
+// copy the comment and its answers which are safe to show to anonymous visitors
+func toPublicComment(comment *models.Comment) dto.PublicComment {
+	publicComment := dto.PublicComment{
+		CommentID:   comment.CommentID,
+		ParentID:    comment.ParentID,
+		Depth:       comment.Depth,
+		Content:     comment.Content,
+		ContentHTML: comment.ContentHTML,
+		Author:      toPublicAuthor(comment.User),
+		ReplyCount:  comment.ReplyCount,
+		Reactions:   comment.Reactions,
+		EditedAt:    comment.EditedAt,
+		EditCount:   comment.EditCount,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+	}
+
+	//the comments which are not approved are only shown to their author and the authors of the post
+	if comment.Status != constants.CommentStatusApproved {
+		publicComment.Status = comment.Status
+	}
+
+	for i := range comment.Replies {
+		publicComment.Replies = append(publicComment.Replies, toPublicComment(&comment.Replies[i]))
+	}
+
+	return publicComment
+}
+
+// copy the part of a series which is safe to show to anonymous visitors
+func toPublicSeriesPart(part *models.SeriesPart) *dto.PublicSeriesPart {
+	if part == nil {
+		return nil
+	}
+
+	return &dto.PublicSeriesPart{Title: part.Title, Slug: part.Slug, Position: part.Position}
+}
+
+// copy the category details which are safe to show to anonymous visitors
+func toPublicCategory(category *models.Category) *dto.PublicCategory {
+	if category == nil {
+		return nil
+	}
+
+	return &dto.PublicCategory{Name: category.CategoryName, Slug: category.Slug, Description: category.Description}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type ReactionServices interface {
+	ToggleReaction(reaction *models.Reaction, viewer dto.Viewer) (*dto.ReactionResult, *dto.ErrorResponse)
+	GetReactors(targetType string, targetID uuid.UUID, reactionType string, limit, offset int, viewer dto.Viewer) ([]dto.Reactor, int64, *dto.ErrorResponse)
+}
+
+type reactionService struct {
+	repositories.ReactionRepository
+}
+
+func InitReactionService(reaction repositories.ReactionRepository) ReactionServices {
+	return &reactionService{reaction}
+}
+
+// add or remove the reaction of the user
+func (repo *reactionService) ToggleReaction(reaction *models.Reaction, viewer dto.Viewer) (*dto.ReactionResult, *dto.ErrorResponse) {
+	return repo.ReactionRepository.ToggleReaction(reaction, viewer)
+}
+
+// retrieve the users who reacted to a post or comment
+func (repo *reactionService) GetReactors(targetType string, targetID uuid.UUID, reactionType string, limit, offset int, viewer dto.Viewer) ([]dto.Reactor, int64, *dto.ErrorResponse) {
+	return repo.ReactionRepository.GetReactors(targetType, targetID, reactionType, limit, offset, viewer)
+}
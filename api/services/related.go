@@ -0,0 +1,26 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type RelatedServices interface {
+	GetRelatedPosts(postID uuid.UUID, limit int, viewer dto.Viewer) ([]models.RelatedPost, *dto.ErrorResponse)
+}
+
+type relatedService struct {
+	repositories.RelatedRepository
+}
+
+func InitRelatedService(related repositories.RelatedRepository) RelatedServices {
+	return &relatedService{related}
+}
+
+// retrieve the posts most similar to the post
+func (repo *relatedService) GetRelatedPosts(postID uuid.UUID, limit int, viewer dto.Viewer) ([]models.RelatedPost, *dto.ErrorResponse) {
+	return repo.RelatedRepository.GetRelatedPosts(postID, limit, viewer)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type ReportServices interface {
+	CreateReport(report *models.Report, viewer dto.Viewer) *dto.ErrorResponse
+	GetReportGroups(targetType string, limit, offset int) (*[]dto.ReportGroup, int64, *dto.ErrorResponse)
+	GetReports(targetType string, targetID uuid.UUID) (*[]models.Report, *dto.ErrorResponse)
+	ResolveReports(targetType string, targetID uuid.UUID, resolution *dto.ReportResolution, moderatorID uuid.UUID) (*models.AuditLog, *dto.ErrorResponse)
+	GetAuditLogs(targetType string, targetID uuid.UUID, limit, offset int) (*[]models.AuditLog, int64, *dto.ErrorResponse)
+}
+
+type reportService struct {
+	repositories.ReportRepository
+}
+
+func InitReportService(report repositories.ReportRepository) ReportServices {
+	return &reportService{report}
+}
+
+// report a post or comment
+func (repo *reportService) CreateReport(report *models.Report, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.ReportRepository.CreateReport(report, viewer)
+}
+
+// retrieve the open reports grouped by the content they report
+func (repo *reportService) GetReportGroups(targetType string, limit, offset int) (*[]dto.ReportGroup, int64, *dto.ErrorResponse) {
+	return repo.ReportRepository.GetReportGroups(targetType, limit, offset)
+}
+
+// retrieve the reports of a post or comment
+func (repo *reportService) GetReports(targetType string, targetID uuid.UUID) (*[]models.Report, *dto.ErrorResponse) {
+	return repo.ReportRepository.GetReports(targetType, targetID)
+}
+
+// close the open reports of a post or comment with the action of the moderator
+func (repo *reportService) ResolveReports(targetType string, targetID uuid.UUID, resolution *dto.ReportResolution, moderatorID uuid.UUID) (*models.AuditLog, *dto.ErrorResponse) {
+	return repo.ReportRepository.ResolveReports(targetType, targetID, resolution, moderatorID)
+}
+
+// retrieve the actions taken on the content and its authors
+func (repo *reportService) GetAuditLogs(targetType string, targetID uuid.UUID, limit, offset int) (*[]models.AuditLog, int64, *dto.ErrorResponse) {
+	return repo.ReportRepository.GetAuditLogs(targetType, targetID, limit, offset)
+}
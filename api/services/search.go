@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+)
+
+type SearchServices interface {
+	Search(query dto.SearchQuery) (*dto.SearchResponse, int64, *dto.ErrorResponse)
+}
+
+type searchService struct {
+	repositories.SearchRepository
+}
+
+func InitSearchService(search repositories.SearchRepository) SearchServices {
+	return &searchService{search}
+}
+
+// search the posts and comments ranked by relevance
+func (repo *searchService) Search(query dto.SearchQuery) (*dto.SearchResponse, int64, *dto.ErrorResponse) {
+	return repo.SearchRepository.Search(query)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type SeriesServices interface {
+	CreateSeries(series *models.Series) *dto.ErrorResponse
+	GetSeriesList(userID uuid.UUID, limit, offset int) (*[]models.Series, int64, *dto.ErrorResponse)
+	GetSeries(seriesID uuid.UUID, viewer dto.Viewer) (*models.Series, *dto.ErrorResponse)
+	UpdateSeries(series *models.Series, seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	DeleteSeries(seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	AddPart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) (*models.SeriesPost, *dto.ErrorResponse)
+	ReorderParts(seriesID uuid.UUID, postIDs []uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+	RemovePart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse
+}
+
+type seriesService struct {
+	repositories.SeriesRepository
+}
+
+func InitSeriesService(series repositories.SeriesRepository) SeriesServices {
+	return &seriesService{series}
+}
+
+// create a new series
+func (repo *seriesService) CreateSeries(series *models.Series) *dto.ErrorResponse {
+	return repo.SeriesRepository.CreateSeries(series)
+}
+
+// retrieve the series of the user
+func (repo *seriesService) GetSeriesList(userID uuid.UUID, limit, offset int) (*[]models.Series, int64, *dto.ErrorResponse) {
+	return repo.SeriesRepository.GetSeriesList(userID, limit, offset)
+}
+
+// retrieve a series along with its posts in order
+func (repo *seriesService) GetSeries(seriesID uuid.UUID, viewer dto.Viewer) (*models.Series, *dto.ErrorResponse) {
+	return repo.SeriesRepository.GetSeries(seriesID, viewer)
+}
+
+// update a existing series
+func (repo *seriesService) UpdateSeries(series *models.Series, seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.SeriesRepository.UpdateSeries(series, seriesID, viewer)
+}
+
+// delete a existing series
+func (repo *seriesService) DeleteSeries(seriesID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.SeriesRepository.DeleteSeries(seriesID, viewer)
+}
+
+// add a post at the end of the series
+func (repo *seriesService) AddPart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) (*models.SeriesPost, *dto.ErrorResponse) {
+	return repo.SeriesRepository.AddPart(seriesID, postID, viewer)
+}
+
+// change the order of the posts in the series
+func (repo *seriesService) ReorderParts(seriesID uuid.UUID, postIDs []uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.SeriesRepository.ReorderParts(seriesID, postIDs, viewer)
+}
+
+// remove a post from the series
+func (repo *seriesService) RemovePart(seriesID uuid.UUID, postID uuid.UUID, viewer dto.Viewer) *dto.ErrorResponse {
+	return repo.SeriesRepository.RemovePart(seriesID, postID, viewer)
+}
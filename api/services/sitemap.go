@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+	"github.com/marees7/rishi-aug-2024/pkg/sitemap"
+)
+
+type SitemapServices interface {
+	GetSitemap() ([]byte, *dto.ErrorResponse)
+	GetCategorySitemap() ([]byte, *dto.ErrorResponse)
+	GetPostSitemap(page int) ([]byte, *dto.ErrorResponse)
+}
+
+type sitemapService struct {
+	repositories.SitemapRepository
+}
+
+func InitSitemapService(sitemap repositories.SitemapRepository) SitemapServices {
+	return &sitemapService{sitemap}
+}
+
+// build the sitemap, or the sitemap index once the site has too many pages for a single sitemap
+func (repo *sitemapService) GetSitemap() ([]byte, *dto.ErrorResponse) {
+	count, errorResponse := repo.SitemapRepository.CountPosts()
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	categories, errorResponse := repo.categoryURLs()
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	if int(count)+len(categories) <= constants.SitemapMaxURLs {
+		posts, errorResponse := repo.postURLs(constants.SitemapMaxURLs, 0)
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+
+		return encodeSitemap(sitemap.URLSet(append(categories, posts...)))
+	}
+
+	//list the category sitemap followed by a sitemap for every page of posts
+	pages, errorResponse := repo.SitemapRepository.GetPostPages(constants.SitemapMaxURLs)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	sitemaps := []sitemap.URL{{Loc: helpers.SiteLink("sitemaps", "categories.xml"), LastMod: latest(categories)}}
+	for _, page := range pages {
+		sitemaps = append(sitemaps, sitemap.URL{
+			Loc:     helpers.SiteLink("sitemaps", fmt.Sprintf("posts-%d.xml", page.Page+1)),
+			LastMod: page.UpdatedAt,
+		})
+	}
+
+	return encodeSitemap(sitemap.Index(sitemaps))
+}
+
+// build the sitemap of the home page and the categories
+func (repo *sitemapService) GetCategorySitemap() ([]byte, *dto.ErrorResponse) {
+	categories, errorResponse := repo.categoryURLs()
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	return encodeSitemap(sitemap.URLSet(categories))
+}
+
+// build the sitemap of a page of posts, pages start from 1
+func (repo *sitemapService) GetPostSitemap(page int) ([]byte, *dto.ErrorResponse) {
+	count, errorResponse := repo.SitemapRepository.CountPosts()
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	if page < 1 || (page-1)*constants.SitemapMaxURLs >= int(count) {
+		return nil, &dto.ErrorResponse{Status: http.StatusNotFound, Error: "sitemap not found"}
+	}
+
+	posts, errorResponse := repo.postURLs(constants.SitemapMaxURLs, (page-1)*constants.SitemapMaxURLs)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	return encodeSitemap(sitemap.URLSet(posts))
+}
+
+// urls of the home page and every category
+func (repo *sitemapService) categoryURLs() ([]sitemap.URL, *dto.ErrorResponse) {
+	categories, errorResponse := repo.SitemapRepository.GetCategories()
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	urls := []sitemap.URL{{Loc: helpers.SiteURL() + "/"}}
+	for _, category := range categories {
+		urls = append(urls, sitemap.URL{Loc: helpers.SiteLink("categories", category.Slug), LastMod: category.UpdatedAt})
+	}
+
+	//the home page changes along with the most recent category
+	urls[0].LastMod = latest(urls)
+
+	return urls, nil
+}
+
+// urls of a page of posts, posts pointing their canonical url elsewhere are left out
+func (repo *sitemapService) postURLs(limit, offset int) ([]sitemap.URL, *dto.ErrorResponse) {
+	posts, errorResponse := repo.SitemapRepository.GetPosts(limit, offset)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	urls := make([]sitemap.URL, 0, len(posts))
+	for _, post := range posts {
+		link := helpers.SiteLink("posts", post.Slug)
+		if post.CanonicalURL != "" && post.CanonicalURL != link {
+			continue
+		}
+
+		urls = append(urls, sitemap.URL{Loc: link, LastMod: post.UpdatedAt})
+	}
+
+	return urls, nil
+}
+
+// most recent modification date of the urls
+func latest(urls []sitemap.URL) (lastMod time.Time) {
+	for _, url := range urls {
+		if url.LastMod.After(lastMod) {
+			lastMod = url.LastMod
+		}
+	}
+
+	return lastMod
+}
+
+// wrap the encoding error into an error response
+func encodeSitemap(body []byte, err error) ([]byte, *dto.ErrorResponse) {
+	if err != nil {
+		return nil, &dto.ErrorResponse{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return body, nil
+}
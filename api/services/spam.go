@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
+
+	"github.com/google/uuid"
+)
+
+type SpamServices interface {
+	GetSpamChecks(decision, targetType, review string, limit, offset int) (*[]models.SpamCheck, int64, *dto.ErrorResponse)
+	ReviewSpamCheck(checkID uuid.UUID, review string, moderatorID uuid.UUID) (*models.SpamCheck, *dto.ErrorResponse)
+}
+
+type spamService struct {
+	repositories.SpamRepository
+}
+
+func InitSpamService(spam repositories.SpamRepository) SpamServices {
+	return &spamService{spam}
+}
+
+// retrieve the spam checks
+func (repo *spamService) GetSpamChecks(decision, targetType, review string, limit, offset int) (*[]models.SpamCheck, int64, *dto.ErrorResponse) {
+	return repo.SpamRepository.GetSpamChecks(decision, targetType, review, limit, offset)
+}
+
+// record whether the checked content was spam
+func (repo *spamService) ReviewSpamCheck(checkID uuid.UUID, review string, moderatorID uuid.UUID) (*models.SpamCheck, *dto.ErrorResponse) {
+	return repo.SpamRepository.ReviewSpamCheck(checkID, review, moderatorID)
+}
+
+// score the content written by the viewer once the repository checked it can be written, the content of admins and
+// moderators is not checked
+func checkSpam(ctx context.Context, pipeline *spam.Pipeline, viewer dto.Viewer, content spam.Content) repositories.SpamCheck {
+	return func() *spam.Verdict {
+		if pipeline == nil || viewer.Role == constants.AdminRole || viewer.Role == constants.ModeratorRole {
+			return nil
+		}
+
+		verdict := pipeline.Check(ctx, content)
+		for _, result := range verdict.Results {
+			if result.Error != "" {
+				loggers.Warn.Println("Spam checker", result.Checker, "failed", result.Error)
+			}
+		}
+
+		return &verdict
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/views"
+
+	"github.com/google/uuid"
+)
+
+type StatsServices interface {
+	GetPostStats(postID uuid.UUID, userID uuid.UUID, role string, from, to time.Time) (*dto.PostStats, *dto.ErrorResponse)
+}
+
+type statsService struct {
+	repositories.StatsRepository
+}
+
+func InitStatsService(stats repositories.StatsRepository) StatsServices {
+	return &statsService{stats}
+}
+
+// retrieve the views of a post within the date range
+func (repo *statsService) GetPostStats(postID uuid.UUID, userID uuid.UUID, role string, from, to time.Time) (*dto.PostStats, *dto.ErrorResponse) {
+	return repo.StatsRepository.GetPostStats(postID, userID, role, from, to)
+}
+
+// count the view of a post, authors reading their own posts and locked posts are not counted
+func recordView(tracker *views.Tracker, post *models.Post, viewer dto.Viewer, visit dto.Visit) {
+	if tracker == nil || post.Locked || post.HasAuthor(viewer.UserID) {
+		return
+	}
+
+	tracker.Record(post.PostID, visit.Visitor, visit.Referrer)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type TagServices interface {
+	GetTags(limit, offset int, sort string) (*[]models.Tag, int64, *dto.ErrorResponse)
+	GetPostsByTag(slug string, limit, offset int, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse)
+	RenameTag(tagID uuid.UUID, name string) (*models.Tag, *dto.ErrorResponse)
+	MergeTag(sourceID uuid.UUID, targetID uuid.UUID) *dto.ErrorResponse
+}
+
+type tagService struct {
+	repositories.TagRepository
+}
+
+func InitTagService(tag repositories.TagRepository) TagServices {
+	return &tagService{tag}
+}
+
+// retrieve every tag along with its usage count
+func (repo *tagService) GetTags(limit, offset int, sort string) (*[]models.Tag, int64, *dto.ErrorResponse) {
+	return repo.TagRepository.GetTags(limit, offset, sort)
+}
+
+// retrieve the posts labelled with the tag
+func (repo *tagService) GetPostsByTag(slug string, limit, offset int, viewer dto.Viewer) (*[]models.Post, int64, *dto.ErrorResponse) {
+	return repo.TagRepository.GetPostsByTag(slug, limit, offset, viewer)
+}
+
+// rename an existing tag
+func (repo *tagService) RenameTag(tagID uuid.UUID, name string) (*models.Tag, *dto.ErrorResponse) {
+	return repo.TagRepository.RenameTag(tagID, name)
+}
+
+// merge the source tag into the target tag
+func (repo *tagService) MergeTag(sourceID uuid.UUID, targetID uuid.UUID) *dto.ErrorResponse {
+	return repo.TagRepository.MergeTag(sourceID, targetID)
+}
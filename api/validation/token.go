@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // generate a new token for the user
@@ -46,3 +47,60 @@ func GetClaims(token *jwt.Token) (jwt.MapClaims, error) {
 	
 	return claims, nil
 }
+
+// parse the login token and retrieve the data inside its claims
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetClaims(token)
+}
+
+// grants are signed with their own key so they cannot be used as login tokens
+func postGrantKey() []byte {
+	return []byte(os.Getenv("SECRET_KEY") + ":post-grant")
+}
+
+// generate a short lived grant to read a password protected post
+func GeneratePostGrant(postID uuid.UUID, ttl time.Duration) (*dto.PostGrant, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := &dto.PostGrantClaims{
+		PostID: postID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(postGrantKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PostGrant{Token: tokenStr, ExpiresAt: expiresAt}, nil
+}
+
+// retrieve the post id of a valid grant
+func ParsePostGrant(tokenString string) (uuid.UUID, error) {
+	var claims dto.PostGrantClaims
+
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return postGrantKey(), nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return claims.PostID, nil
+}
@@ -2,10 +2,17 @@ package validation
 
 import (
 	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/dto"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
+	"github.com/marees7/rishi-aug-2024/pkg/spam"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 // validates the user fields
@@ -28,8 +35,8 @@ func ValidateUser(user *models.User) error {
 	}
 
 	//check role
-	if user.Role != constants.AdminRole && user.Role != "user" {
-		return fmt.Errorf("role must be either admin or user")
+	if user.Role != constants.AdminRole && user.Role != constants.ModeratorRole && user.Role != "user" {
+		return fmt.Errorf("role must be either admin, moderator or user")
 	}
 
 	return nil
@@ -47,6 +54,15 @@ func ValidateCategory(category *models.Category) error {
 		return fmt.Errorf("description cannot be empty")
 	}
 
+	return ValidateCommentLockDays(category.CommentLockDays)
+}
+
+// Validate the days after which the comments on the posts of a category are locked, zero never locks them
+func ValidateCommentLockDays(days *int) error {
+	if days != nil && *days < 0 {
+		return fmt.Errorf("comment lock days cannot be negative")
+	}
+
 	return nil
 }
 
@@ -67,6 +83,122 @@ func ValidatePost(post *models.Post) error {
 		return fmt.Errorf("description cannot be empty")
 	}
 
+	if err := ValidateContentFormat(post.ContentFormat); err != nil {
+		return err
+	}
+
+	if err := ValidatePostStatus(post.Status); err != nil {
+		return err
+	}
+
+	if err := ValidatePostSEO(post); err != nil {
+		return err
+	}
+
+	if err := ValidatePostVisibility(post); err != nil {
+		return err
+	}
+
+	//check password
+	if post.Visibility == constants.VisibilityPassword && post.Password == "" {
+		return fmt.Errorf("password protected posts need a password")
+	}
+
+	return ValidatePostTags(post.TagNames)
+}
+
+// Validate the format of the post content, html is only written by the imports
+func ValidateContentFormat(format string) error {
+	if format != "" && format != constants.ContentFormatMarkdown && format != constants.ContentFormatPlain {
+		return fmt.Errorf("content format must be either markdown or plain")
+	}
+
+	return nil
+}
+
+// Validate the publishing status of the post
+func ValidatePostStatus(status string) error {
+	if status != "" && status != constants.PostStatusDraft && status != constants.PostStatusPublished {
+		return fmt.Errorf("status must be either draft or published")
+	}
+
+	return nil
+}
+
+// Validate the visibility of the post and its password
+func ValidatePostVisibility(post *models.Post) error {
+	switch post.Visibility {
+	case "", constants.VisibilityPublic, constants.VisibilityUnlisted, constants.VisibilityMembers,
+		constants.VisibilityPrivate, constants.VisibilityPassword:
+	default:
+		return fmt.Errorf("visibility must be public, unlisted, members, private or password")
+	}
+
+	//check password
+	if post.Password != "" && len(post.Password) < 4 {
+		return fmt.Errorf("post password must contain atleast 4 characters")
+	}
+
+	return nil
+}
+
+// Validate the search engine metadata of the post
+func ValidatePostSEO(post *models.Post) error {
+	//check meta title
+	if utf8.RuneCountInString(post.MetaTitle) > constants.MaxMetaTitleLength {
+		return fmt.Errorf("meta title cannot exceed %d characters", constants.MaxMetaTitleLength)
+	}
+
+	//check meta description
+	if utf8.RuneCountInString(post.MetaDescription) > constants.MaxMetaDescriptionLength {
+		return fmt.Errorf("meta description cannot exceed %d characters", constants.MaxMetaDescriptionLength)
+	}
+
+	//check urls
+	if post.CanonicalURL != "" && !helpers.IsAbsoluteURL(post.CanonicalURL) {
+		return fmt.Errorf("canonical url must be an absolute http or https url")
+	}
+
+	if post.OGImage != "" && !helpers.IsAbsoluteURL(post.OGImage) {
+		return fmt.Errorf("open graph image must be an absolute http or https url")
+	}
+
+	return nil
+}
+
+// Validate the tags assigned to a post
+func ValidatePostTags(names []string) error {
+	tags := make(map[string]bool)
+
+	for _, name := range names {
+		if err := ValidateTagName(name); err != nil {
+			return err
+		}
+		tags[helpers.Slugify(helpers.NormalizeTag(name))] = true
+	}
+
+	//check tag limit
+	if len(tags) > constants.MaxTagsPerPost {
+		return fmt.Errorf("a post can have at most %d tags", constants.MaxTagsPerPost)
+	}
+
+	return nil
+}
+
+// Validate the tag name
+func ValidateTagName(name string) error {
+	name = helpers.NormalizeTag(name)
+
+	//check name
+	if helpers.Slugify(name) == "" {
+		return fmt.Errorf("tag name must contain a letter or digit")
+	}
+
+	//check length
+	if utf8.RuneCountInString(name) > constants.MaxTagLength {
+		return fmt.Errorf("tag name cannot exceed %d characters", constants.MaxTagLength)
+	}
+
 	return nil
 }
 
@@ -80,11 +212,78 @@ func ValidateComment(comment *models.Comment) error {
 	return nil
 }
 
-// Validate the reply fields
-func ValidateReply(reply *models.Reply) error {
-	//check content
-	if reply.Content == "" {
-		return fmt.Errorf("content cannot be empty")
+// Validate the order of the comment threads, empty means the oldest first
+func ValidateCommentSort(sort string) error {
+	if sort != "" && sort != constants.CommentSortNewest && sort != constants.CommentSortOldest && sort != constants.CommentSortTop {
+		return fmt.Errorf("sort must be either newest, oldest or top")
+	}
+
+	return nil
+}
+
+// Validate the sniffed type of an uploaded file and return its file extension
+func ValidateMediaType(contentType string) (string, error) {
+	extensions := map[string]string{
+		"image/jpeg":      ".jpg",
+		"image/png":       ".png",
+		"image/gif":       ".gif",
+		"image/webp":      ".webp",
+		"application/pdf": ".pdf",
+		"video/mp4":       ".mp4",
+	}
+
+	extension, ok := extensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("file type %s is not allowed", contentType)
+	}
+
+	return extension, nil
+}
+
+// Validate the kind of content a reaction is added to
+func ValidateReactionTarget(targetType string) error {
+	if targetType != constants.ReactionTargetPost && targetType != constants.ReactionTargetComment && targetType != constants.ReactionTargetReply {
+		return fmt.Errorf("reactions can only be added to a post, comment or reply")
+	}
+
+	return nil
+}
+
+// Validate the reaction against the configured reaction types
+func ValidateReactionType(reactionType string) error {
+	types := helpers.EnvList("REACTION_TYPES", constants.DefaultReactionTypes)
+	for _, allowed := range types {
+		if reactionType == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("reaction must be one of %s", strings.Join(types, ", "))
+}
+
+// Validate the name of a bookmark collection
+func ValidateCollectionName(name string) error {
+	if utf8.RuneCountInString(strings.TrimSpace(name)) > constants.MaxCollectionNameLength {
+		return fmt.Errorf("collection name cannot exceed %d characters", constants.MaxCollectionNameLength)
+	}
+
+	return nil
+}
+
+// Validates the series fields
+func ValidateSeries(series *models.Series) error {
+	//check title
+	if strings.TrimSpace(series.Title) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+
+	return nil
+}
+
+// Validate the role an invited author gets on a post
+func ValidateAuthorRole(role string) error {
+	if role != constants.AuthorRoleCoAuthor && role != constants.AuthorRoleContributor {
+		return fmt.Errorf("authors can only be invited as %s or %s", constants.AuthorRoleCoAuthor, constants.AuthorRoleContributor)
 	}
 
 	return nil
@@ -94,3 +293,150 @@ func ValidateReply(reply *models.Reply) error {
 func ValidateRole(role string) bool {
 	return role == constants.AdminRole
 }
+
+// Check if the role can moderate the site
+func ValidateModerator(role string) bool {
+	return role == constants.AdminRole || role == constants.ModeratorRole
+}
+
+// Validate the details of a pinned post
+func ValidatePin(pin *dto.PinRequest) error {
+	if pin.Scope == "" {
+		pin.Scope = constants.PinScopeSite
+	}
+	if pin.Scope != constants.PinScopeSite && pin.Scope != constants.PinScopeCategory {
+		return fmt.Errorf("scope must be either %s or %s", constants.PinScopeSite, constants.PinScopeCategory)
+	}
+
+	if pin.Position != nil && *pin.Position < 0 {
+		return fmt.Errorf("position cannot be negative")
+	}
+
+	if pin.ExpiresAt != nil && !pin.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expiry must be in the future")
+	}
+
+	return nil
+}
+
+// Validate the source of an import
+func ValidateImportSource(source string) error {
+	if source != constants.ImportSourceWXR && source != constants.ImportSourceMarkdown {
+		return fmt.Errorf("source must be either %s or %s", constants.ImportSourceWXR, constants.ImportSourceMarkdown)
+	}
+
+	return nil
+}
+
+// Validate the action the import items are filtered by
+func ValidateImportAction(action string) error {
+	if action != "" && action != constants.ImportActionCreated && action != constants.ImportActionSkipped && action != constants.ImportActionFailed {
+		return fmt.Errorf("action must be either %s, %s or %s", constants.ImportActionCreated, constants.ImportActionSkipped, constants.ImportActionFailed)
+	}
+
+	return nil
+}
+
+// Validate the status the moderation queue is filtered by, empty means the pending comments
+func ValidateCommentStatus(status string) error {
+	if status != "" && status != constants.CommentStatusPending && status != constants.CommentStatusApproved &&
+		status != constants.CommentStatusRejected && status != constants.CommentStatusSpam {
+		return fmt.Errorf("status must be either %s, %s, %s or %s", constants.CommentStatusPending, constants.CommentStatusApproved,
+			constants.CommentStatusRejected, constants.CommentStatusSpam)
+	}
+
+	return nil
+}
+
+// Validate the comments moderated in bulk
+func ValidateCommentModeration(moderation *dto.CommentModeration) error {
+	if len(moderation.CommentIDs) == 0 {
+		return fmt.Errorf("comment_ids cannot be empty")
+	} else if len(moderation.CommentIDs) > constants.MaxModerationBatch {
+		return fmt.Errorf("at most %d comments can be moderated at once", constants.MaxModerationBatch)
+	}
+
+	if moderation.Status != constants.CommentStatusApproved && moderation.Status != constants.CommentStatusRejected &&
+		moderation.Status != constants.CommentStatusSpam {
+		return fmt.Errorf("status must be either %s, %s or %s", constants.CommentStatusApproved, constants.CommentStatusRejected, constants.CommentStatusSpam)
+	}
+
+	return nil
+}
+
+// Validate the type of the reported content, empty means every type
+func ValidateReportTarget(targetType string) error {
+	if targetType != "" && targetType != constants.ReactionTargetPost && targetType != constants.ReactionTargetComment {
+		return fmt.Errorf("only a post or comment can be reported")
+	}
+
+	return nil
+}
+
+// Validate the details of a report
+func ValidateReport(report *models.Report) error {
+	if report.TargetType == "" || report.TargetID == uuid.Nil {
+		return fmt.Errorf("target_type and target_id cannot be empty")
+	}
+	if err := ValidateReportTarget(report.TargetType); err != nil {
+		return err
+	}
+
+	switch report.Reason {
+	case constants.ReportReasonSpam, constants.ReportReasonHarassment, constants.ReportReasonHate, constants.ReportReasonViolence,
+		constants.ReportReasonSexual, constants.ReportReasonMisinformation, constants.ReportReasonOther:
+	default:
+		return fmt.Errorf("reason must be either %s, %s, %s, %s, %s, %s or %s", constants.ReportReasonSpam, constants.ReportReasonHarassment,
+			constants.ReportReasonHate, constants.ReportReasonViolence, constants.ReportReasonSexual, constants.ReportReasonMisinformation,
+			constants.ReportReasonOther)
+	}
+
+	if report.Reason == constants.ReportReasonOther && strings.TrimSpace(report.Details) == "" {
+		return fmt.Errorf("details cannot be empty when the reason is other")
+	} else if utf8.RuneCountInString(report.Details) > constants.MaxReportDetailsLength {
+		return fmt.Errorf("details cannot be longer than %d characters", constants.MaxReportDetailsLength)
+	}
+
+	return nil
+}
+
+// Validate the action resolving the reports of a content
+func ValidateReportResolution(resolution *dto.ReportResolution) error {
+	switch resolution.Action {
+	case constants.ReportActionDismiss, constants.ReportActionRemove, constants.ReportActionWarn, constants.ReportActionSuspend:
+	default:
+		return fmt.Errorf("action must be either %s, %s, %s or %s", constants.ReportActionDismiss, constants.ReportActionRemove,
+			constants.ReportActionWarn, constants.ReportActionSuspend)
+	}
+
+	if resolution.Action != constants.ReportActionSuspend {
+		resolution.SuspendDays = 0
+	} else if resolution.SuspendDays == 0 {
+		resolution.SuspendDays = constants.DefaultSuspendDays
+	} else if resolution.SuspendDays < 0 || resolution.SuspendDays > 365 {
+		return fmt.Errorf("suspend_days must be between 1 and 365")
+	}
+
+	return nil
+}
+
+// Validate the filters of the spam checks, none matches the checks which were not reviewed
+func ValidateSpamFilters(decision, review string) error {
+	if decision != "" && decision != spam.DecisionAllow && decision != spam.DecisionHold && decision != spam.DecisionReject {
+		return fmt.Errorf("decision must be either %s, %s or %s", spam.DecisionAllow, spam.DecisionHold, spam.DecisionReject)
+	}
+	if review != "" && review != "none" {
+		return ValidateSpamReview(review)
+	}
+
+	return nil
+}
+
+// Validate the review of a spam check
+func ValidateSpamReview(review string) error {
+	if review != constants.SpamReviewHam && review != constants.SpamReviewSpam {
+		return fmt.Errorf("review must be either %s or %s", constants.SpamReviewHam, constants.SpamReviewSpam)
+	}
+
+	return nil
+}
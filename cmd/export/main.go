@@ -0,0 +1,75 @@
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/internals"
+	"github.com/marees7/rishi-aug-2024/pkg/archive"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+)
+
+func init() {
+	//load the env file
+	internals.LoadEnv()
+
+	//load the logger
+	loggers.OpenLog()
+}
+
+// exports the users, categories, tags, posts and comments to an archive, compressed when the path ends with .gz
+//
+//	go run ./export [-passwords] path
+func main() {
+	passwords := flag.Bool("passwords", false, "export the password hashes of the users, the users have to pick a new password otherwise")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: export [-passwords] path")
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	//connect to the database and get the db
+	db := internals.Connect()
+
+	file, err := os.Create(path)
+	if err != nil {
+		exit(err)
+	}
+
+	var writer io.Writer = file
+	var compressor *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		compressor = gzip.NewWriter(file)
+		writer = compressor
+	}
+
+	counts, err := repositories.InitArchiveRepository(db.DB).Export(writer, *passwords)
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	//an unfinished archive cannot be restored, so it is not kept
+	if err != nil {
+		os.Remove(path)
+		exit(err)
+	}
+
+	fmt.Printf("exported %s\n", archive.Summary(counts))
+}
+
+// print the error and stop the export
+func exit(err error) {
+	loggers.Error.Println(err)
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
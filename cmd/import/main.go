@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/api/validation"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/internals"
+	"github.com/marees7/rishi-aug-2024/pkg/importer"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+	"github.com/marees7/rishi-aug-2024/pkg/models"
+)
+
+func init() {
+	//load the env file
+	internals.LoadEnv()
+
+	//load the logger
+	loggers.OpenLog()
+}
+
+// imports a wordpress export, a markdown file, a zip archive or a folder of markdown files
+//
+//	go run ./import [-source wxr|markdown] [-dry-run] [-user username] path
+func main() {
+	source := flag.String("source", "", "source of the path, wxr or markdown, guessed from the path when empty")
+	dryRun := flag.Bool("dry-run", false, "report what would be imported without importing it")
+	username := flag.String("user", "", "username of the admin importing the files, the first admin when empty")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: import [-source wxr|markdown] [-dry-run] [-user username] path")
+		os.Exit(2)
+	}
+
+	//connect to the database and get the db
+	db := internals.Connect()
+
+	//migrate the model structs
+	db.Migrate()
+
+	//the posts without an author known to the site belong to the admin importing them
+	var admin models.User
+	query := db.Where("role=?", constants.AdminRole)
+	if *username != "" {
+		query = query.Where("username=?", *username)
+	}
+	if data := query.Order("created_at").Limit(1).Find(&admin); data.Error != nil {
+		exit(data.Error)
+	} else if data.RowsAffected == 0 {
+		exit(fmt.Errorf("no admin found to import the files"))
+	}
+
+	document, job, err := readExport(flag.Arg(0), *source)
+	if err != nil {
+		exit(err)
+	}
+	job.DryRun = *dryRun
+	job.UserID = admin.UserID
+
+	repository := repositories.InitImportRepository(db.DB)
+
+	imported, errorResponse := repository.StartImport(job)
+	if errorResponse != nil {
+		exit(fmt.Errorf("%s", errorResponse.Error))
+	} else if imported {
+		fmt.Printf("%s was already imported by job %s\n", job.Name, job.JobID)
+		return
+	}
+
+	runErr := repository.RunImport(job, document)
+	fmt.Printf("job %s %s: %d created, %d skipped, %d failed\n", job.JobID, job.Status, job.Created, job.Skipped, job.Failed)
+
+	//list every failed item along with the reason
+	for offset := 0; ; offset += constants.DefaultLimit {
+		items, _, errorResponse := repository.GetImportItems(job.JobID, constants.ImportActionFailed, constants.DefaultLimit, offset)
+		if errorResponse != nil {
+			exit(fmt.Errorf("%s", errorResponse.Error))
+		}
+
+		for _, item := range *items {
+			fmt.Printf("  %s %s %s: %s\n", item.Kind, item.SourceID, item.Title, item.Reason)
+		}
+
+		if len(*items) < constants.DefaultLimit {
+			break
+		}
+	}
+
+	if runErr != nil {
+		exit(runErr)
+	} else if job.Status == constants.ImportStatusFailed {
+		os.Exit(1)
+	}
+}
+
+// parse the file or folder along with the checksum identifying it
+func readExport(path string, source string) (*importer.Document, *models.ImportJob, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := models.ImportJob{Source: source, Name: filepath.Base(path)}
+	hash := sha256.New()
+
+	var document *importer.Document
+	if info.IsDir() {
+		job.Source = constants.ImportSourceMarkdown
+		folder := os.DirFS(path)
+
+		//the checksum covers the name and content of every file of the folder
+		err = fs.WalkDir(folder, ".", func(name string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+
+			content, err := fs.ReadFile(folder, name)
+			if err != nil {
+				return err
+			}
+
+			hash.Write([]byte(name + "\x00"))
+			hash.Write(content)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if document, err = importer.ParseMarkdownFiles(folder); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if job.Source == "" {
+			job.Source = constants.ImportSourceMarkdown
+			if strings.EqualFold(filepath.Ext(path), ".xml") {
+				job.Source = constants.ImportSourceWXR
+			}
+		}
+
+		if err := validation.ValidateImportSource(job.Source); err != nil {
+			return nil, nil, err
+		}
+
+		hash.Write(content)
+		if document, err = importer.Parse(job.Source, path, content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	job.Site = document.Site
+	job.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return document, &job, nil
+}
+
+// print the error and stop the import
+func exit(err error) {
+	loggers.Error.Println(err)
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
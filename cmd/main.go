@@ -1,9 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/marees7/rishi-aug-2024/api/routes"
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
 	"github.com/marees7/rishi-aug-2024/internals"
 	"github.com/marees7/rishi-aug-2024/pkg/loggers"
 
@@ -32,6 +41,7 @@ func init() {
 func main() {
 	//create a instance of echo
 	server := echo.New()
+	server.IPExtractor = ipExtractor()
 
 	//connect to the database and get the db
 	db := internals.Connect()
@@ -46,10 +56,64 @@ func main() {
 	routes.CommentRoute(server, db.DB)
 	routes.PostRoute(server, db.DB)
 	routes.ReplyRoute(server, db.DB)
+	routes.TagRoute(server, db.DB)
+	routes.SearchRoute(server, db.DB)
+	routes.MediaRoute(server, db.DB)
+	routes.PublicRoute(server, db.DB)
+	routes.FeedRoute(server, db.DB)
+	routes.SitemapRoute(server, db.DB)
+	routes.ReactionRoute(server, db.DB)
+	routes.BookmarkRoute(server, db.DB)
+	routes.StatsRoute(server, db.DB)
+	routes.AuthorRoute(server, db.DB)
+	routes.SeriesRoute(server, db.DB)
+	routes.RelatedRoute(server, db.DB)
+	routes.PinRoute(server, db.DB)
+	routes.ImportRoute(server, db.DB)
+	routes.ModerationRoute(server, db.DB)
+	routes.NotificationRoute(server, db.DB)
+	routes.ReportRoute(server, db.DB)
+	routes.SpamRoute(server, db.DB)
 
 	server.GET("/swagger/*", echoSwagger.EchoWrapHandler())
 	//start the server
-	if err := server.Start(os.Getenv("HTTP_PORT")); err != nil {
-		loggers.Error.Fatalln("Failed to start the server", err)
+	go func() {
+		if err := server.Start(os.Getenv("HTTP_PORT")); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			loggers.Error.Fatalln("Failed to start the server", err)
+		}
+	}()
+
+	//wait for the server to be stopped
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	//finish the requests in progress and store the views still buffered
+	timeout := time.Duration(helpers.EnvInt64("SHUTDOWN_TIMEOUT", int64(constants.DefaultShutdownTimeout))) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		loggers.Error.Println("Failed to shut down the server", err)
 	}
+	routes.FlushViews()
+}
+
+// the client ip is taken from the connection, the X-Forwarded-For header is only trusted from the proxies of TRUSTED_PROXIES
+func ipExtractor() echo.IPExtractor {
+	proxies := helpers.EnvList("TRUSTED_PROXIES", "")
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			loggers.Error.Fatalln("Invalid TRUSTED_PROXIES", err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marees7/rishi-aug-2024/api/repositories"
+	"github.com/marees7/rishi-aug-2024/internals"
+	"github.com/marees7/rishi-aug-2024/pkg/archive"
+	"github.com/marees7/rishi-aug-2024/pkg/loggers"
+)
+
+func init() {
+	//load the env file
+	internals.LoadEnv()
+
+	//load the logger
+	loggers.OpenLog()
+}
+
+// restores an archive written by the export command in an empty database, compressed when the path ends with .gz
+//
+//	go run ./restore path
+func main() {
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: restore path")
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	file, err := os.Open(path)
+	if err != nil {
+		exit(err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		decompressor, err := gzip.NewReader(file)
+		if err != nil {
+			exit(err)
+		}
+		defer decompressor.Close()
+		reader = decompressor
+	}
+
+	//connect to the database and get the db
+	db := internals.Connect()
+
+	//migrate the model structs
+	db.Migrate()
+
+	header, counts, err := repositories.InitArchiveRepository(db.DB).Restore(reader)
+	if err != nil {
+		exit(err)
+	}
+
+	fmt.Printf("restored the archive exported at %s: %s\n", header.ExportedAt.Format("2006-01-02 15:04:05"), archive.Summary(counts))
+	if !header.Passwords {
+		fmt.Println("the passwords were not exported, the users cannot log in until they are given a new password")
+	}
+}
+
+// print the error and stop the restore
+func exit(err error) {
+	loggers.Error.Println(err)
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -5,4 +5,162 @@ const (
 	DefaultLimit  int    = 10
 	DefaultOffset int    = 1
 	AdminRole     string = "admin"
+	ModeratorRole string = "moderator"
+
+	SlugEntityPost     string = "post"
+	SlugEntityCategory string = "category"
+	SlugSaveAttempts   int    = 5
+
+	MaxTagsPerPost int = 10
+	MaxTagLength   int = 32
+
+	ContentFormatMarkdown string = "markdown"
+	ContentFormatPlain    string = "plain"
+	ContentFormatHTML     string = "html"
+
+	SearchTypePosts    string = "posts"
+	SearchTypeComments string = "comments"
+	SearchTypeAll      string = "all"
+
+	DefaultMediaMaxSize   int64 = 10 << 20
+	DefaultMediaUserQuota int64 = 100 << 20
+	DefaultMediaMaxPixels int64 = 40000000
+	MediaUploadOverhead   int64 = 64 << 10
+	ThumbnailSize         int   = 320
+
+	PostStatusDraft     string = "draft"
+	PostStatusPublished string = "published"
+
+	DefaultPublicRateLimit   int = 120
+	DefaultPublicCacheMaxAge int = 60
+
+	DefaultFeedLimit int    = 20
+	DefaultSiteTitle string = "Blog posts"
+
+	MaxMetaTitleLength       int = 70
+	MaxMetaDescriptionLength int = 160
+	SitemapMaxURLs           int = 50000
+
+	VisibilityPublic   string = "public"
+	VisibilityUnlisted string = "unlisted"
+	VisibilityMembers  string = "members"
+	VisibilityPrivate  string = "private"
+	VisibilityPassword string = "password"
+
+	PostGrantHeader     string = "X-Post-Grant"
+	DefaultPostGrantTTL int    = 3600
+
+	ReactionTargetPost    string = "post"
+	ReactionTargetComment string = "comment"
+	ReactionTargetReply   string = "reply"
+	DefaultReactionTypes  string = "like,love,laugh,wow,sad,angry"
+
+	MaxCollectionNameLength int = 50
+
+	DefaultCommentMaxDepth   int    = 5
+	DefaultCommentMaxAnswers int    = 200
+	DefaultCommentEditWindow int64  = 0
+	CommentSortNewest        string = "newest"
+	CommentSortOldest        string = "oldest"
+	CommentSortTop           string = "top"
+
+	CommentStatusPending       string = "pending"
+	CommentStatusApproved      string = "approved"
+	CommentStatusRejected      string = "rejected"
+	CommentStatusSpam          string = "spam"
+	HoldReasonAll              string = "hold_all"
+	HoldReasonFirstComment     string = "first_comment"
+	HoldReasonLinks            string = "links"
+	NotificationCommentPending string = "comment_pending"
+	MaxModerationBatch         int    = 100
+
+	HoldReasonReported         string = "reported"
+	HoldReasonRemoved          string = "removed"
+	ReportReasonSpam           string = "spam"
+	ReportReasonHarassment     string = "harassment"
+	ReportReasonHate           string = "hate"
+	ReportReasonViolence       string = "violence"
+	ReportReasonSexual         string = "sexual"
+	ReportReasonMisinformation string = "misinformation"
+	ReportReasonOther          string = "other"
+	ReportStatusOpen           string = "open"
+	ReportStatusDismissed      string = "dismissed"
+	ReportStatusResolved       string = "resolved"
+	ReportActionDismiss        string = "dismiss"
+	ReportActionRemove         string = "remove"
+	ReportActionWarn           string = "warn"
+	ReportActionSuspend        string = "suspend"
+	AuditActionHide            string = "hide"
+	NotificationWarning        string = "warning"
+	DefaultReportHideThreshold int    = 3
+	DefaultSuspendDays         int    = 7
+	MaxReportDetailsLength     int    = 1000
+
+	HoldReasonSpam               string  = "spam"
+	SpamReviewHam                string  = "ham"
+	SpamReviewSpam               string  = "spam"
+	DefaultSpamHoldScore         float64 = 0.5
+	DefaultSpamRejectScore       float64 = 1
+	DefaultSpamMaxLinks          int     = 3
+	DefaultSpamDuplicateWindow   int     = 86400
+	DefaultSpamDuplicateLength   int     = 40
+	DefaultSpamNewAccountAge     int     = 86400
+	DefaultSpamNewAccountLimit   int     = 5
+	DefaultSpamClassifierWeight  float64 = 1
+	DefaultSpamClassifierTimeout int     = 3
+
+	RateLimitActionPost           string = "post_create"
+	RateLimitActionComment        string = "comment_create"
+	RateLimitActionReply          string = "reply_create"
+	RateLimitActionLogin          string = "login"
+	RateLimitActionLoginIP        string = "login_ip"
+	RateLimitActionLoginAccount   string = "login_account"
+	DefaultRateLimitPost          string = "10/1h"
+	DefaultRateLimitComment       string = "20/10m"
+	DefaultRateLimitReply         string = "20/10m"
+	DefaultRateLimitLogin         string = "10/15m"
+	DefaultRateLimitLoginIP       string = "50/15m"
+	DefaultRateLimitLoginAccount  string = "50/1h"
+	DefaultRateLimitExemptRoles   string = "admin,moderator"
+	DefaultRateLimitPruneInterval int    = 600
+	MaxLoginBodySize              int64  = 4 << 10
+
+	DefaultViewWindow        int    = 1800
+	DefaultViewFlushInterval int    = 30
+	DefaultShutdownTimeout   int    = 10
+	DefaultStatsDays         int    = 30
+	MaxStatsReferrers        int    = 10
+	ReferrerDirect           string = "direct"
+
+	AuthorRoleOwner       string = "owner"
+	AuthorRoleCoAuthor    string = "co-author"
+	AuthorRoleContributor string = "contributor"
+	AuthorStatusPending   string = "pending"
+	AuthorStatusAccepted  string = "accepted"
+
+	DefaultRelatedLimit    int     = 5
+	MaxRelatedPosts        int     = 20
+	DefaultRelatedCacheTTL int     = 600
+	RelatedTagWeight       float64 = 3
+	RelatedCategoryWeight  float64 = 2
+	RelatedTextWeight      float64 = 10
+
+	PinScopeSite     string = "site"
+	PinScopeCategory string = "category"
+
+	ImportSourceWXR       string = "wxr"
+	ImportSourceMarkdown  string = "markdown"
+	ImportStatusRunning   string = "running"
+	ImportStatusCompleted string = "completed"
+	ImportStatusFailed    string = "failed"
+	ImportKindUser        string = "user"
+	ImportKindCategory    string = "category"
+	ImportKindPost        string = "post"
+	ImportKindComment     string = "comment"
+	ImportActionCreated   string = "created"
+	ImportActionSkipped   string = "skipped"
+	ImportActionFailed    string = "failed"
+	ImportDefaultCategory string = "Uncategorized"
+	ImportEmailDomain     string = "imported.invalid"
+	DefaultImportMaxSize  int64  = 64 << 20
 )
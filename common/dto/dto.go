@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -27,6 +29,316 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// for merging a tag into another tag
+type MergeTagRequest struct {
+	TargetTagID uuid.UUID `json:"target_tag_id"`
+}
+
+// filters used by the full text search
+type SearchQuery struct {
+	Query      string
+	Type       string
+	CategoryID uuid.UUID
+	UserID     uuid.UUID
+	Viewer     Viewer
+	Limit      int
+	Offset     int
+}
+
+// single post or comment matching the search
+type SearchResult struct {
+	Type       string    `json:"type"`
+	ID         uuid.UUID `json:"id"`
+	PostID     uuid.UUID `json:"post_id"`
+	Title      string    `json:"title,omitempty"`
+	Slug       string    `json:"slug,omitempty"`
+	Snippet    string    `json:"snippet,omitempty"`
+	Rank       float64   `json:"rank"`
+	CategoryID uuid.UUID `json:"category_id,omitempty"`
+	UserID     uuid.UUID `json:"user_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// number of matches sharing a category or author
+type SearchFacet struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name,omitempty"`
+	Count int64     `json:"count"`
+}
+
+// search results along with the category and author facets
+type SearchResponse struct {
+	Results    []SearchResult `json:"results"`
+	Categories []SearchFacet  `json:"categories,omitempty"`
+	Authors    []SearchFacet  `json:"authors,omitempty"`
+}
+
+// user reading the content along with the password protected posts they unlocked
+type Viewer struct {
+	UserID uuid.UUID
+	Role   string
+	Grants []uuid.UUID
+}
+
+// for unlocking a password protected post
+type UnlockRequest struct {
+	Password string `json:"password"`
+}
+
+// short lived grant to read a password protected post
+type PostGrant struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// claims of the grant to read a password protected post
+type PostGrantClaims struct {
+	PostID uuid.UUID `json:"post_id"`
+	jwt.RegisteredClaims
+}
+
+// filters used while listing the published posts
+type PublicPostFilter struct {
+	Category string
+	Tag      string
+	Author   string
+	Limit    int
+	Offset   int
+	//list the newest posts first without putting the pinned ones ahead, as the feeds do
+	ByDate bool
+}
+
+// author details which are safe to show to anonymous visitors
+type PublicAuthor struct {
+	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role,omitempty"`
+}
+
+// public profile of an author
+type PublicAuthorProfile struct {
+	Username  string    `json:"username"`
+	Name      string    `json:"name,omitempty"`
+	PostCount int64     `json:"post_count"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// category details shown to anonymous visitors
+type PublicCategory struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description,omitempty"`
+}
+
+// tag details shown to anonymous visitors
+type PublicTag struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// media details shown to anonymous visitors
+type PublicMedia struct {
+	FileName     string `json:"file_name,omitempty"`
+	ContentType  string `json:"content_type"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// published post without the private details of its author
+type PublicPost struct {
+	Slug           string           `json:"slug"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description,omitempty"`
+	Content        string           `json:"content,omitempty"`
+	ContentFormat  string           `json:"content_format,omitempty"`
+	ContentHTML    string           `json:"content_html,omitempty"`
+	Author         *PublicAuthor    `json:"author,omitempty"`
+	Authors        []PublicAuthor   `json:"authors,omitempty"`
+	Series         *PublicSeries    `json:"series,omitempty"`
+	Category       *PublicCategory  `json:"category,omitempty"`
+	Tags           []PublicTag      `json:"tags,omitempty"`
+	Media          []PublicMedia    `json:"media,omitempty"`
+	Reactions      map[string]int64 `json:"reactions,omitempty"`
+	ViewCount      int64            `json:"view_count"`
+	Visibility     string           `json:"visibility"`
+	Locked         bool             `json:"locked,omitempty"`
+	Pinned         bool             `json:"pinned,omitempty"`
+	CommentsLocked bool             `json:"comments_locked,omitempty"`
+	SEO            PublicSEO        `json:"seo"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// series a published post belongs to along with the parts before and after it
+type PublicSeries struct {
+	SeriesID uuid.UUID         `json:"series_id"`
+	Title    string            `json:"title"`
+	Position int               `json:"position"`
+	Total    int               `json:"total"`
+	Previous *PublicSeriesPart `json:"previous,omitempty"`
+	Next     *PublicSeriesPart `json:"next,omitempty"`
+}
+
+// part of a series shown to anonymous visitors
+type PublicSeriesPart struct {
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Position int    `json:"position"`
+}
+
+// search engine metadata of a published post, filled from the post when the author left it empty
+type PublicSEO struct {
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description,omitempty"`
+	CanonicalURL    string `json:"canonical_url"`
+	OGImage         string `json:"og_image,omitempty"`
+	NoIndex         bool   `json:"no_index"`
+}
+
+// comment of a published post along with its answers, without the private details of their authors
+type PublicComment struct {
+	CommentID   uuid.UUID        `json:"comment_id"`
+	ParentID    *uuid.UUID       `json:"parent_id,omitempty"`
+	Depth       int              `json:"depth"`
+	Status      string           `json:"status,omitempty"`
+	Content     string           `json:"content,omitempty"`
+	ContentHTML string           `json:"content_html,omitempty"`
+	Author      *PublicAuthor    `json:"author,omitempty"`
+	Replies     []PublicComment  `json:"replies,omitempty"`
+	ReplyCount  int64            `json:"reply_count,omitempty"`
+	Reactions   map[string]int64 `json:"reactions,omitempty"`
+	EditedAt    *time.Time       `json:"edited_at,omitempty"`
+	EditCount   int              `json:"edit_count,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// for adding or removing a reaction
+type ReactionRequest struct {
+	Type string `json:"type"`
+}
+
+// state of the reaction of the user after toggling it along with the updated counts
+type ReactionResult struct {
+	Reacted   bool             `json:"reacted"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+// for bookmarking a post, optionally into a named collection
+type BookmarkRequest struct {
+	Collection string `json:"collection"`
+}
+
+// for adding a post to a series
+type SeriesPartRequest struct {
+	PostID uuid.UUID `json:"post_id"`
+}
+
+// for changing the order of the posts in a series
+type SeriesOrderRequest struct {
+	PostIDs []uuid.UUID `json:"post_ids"`
+}
+
+// for pinning a post, posts without a position are pinned after the other pinned posts
+type PinRequest struct {
+	Scope     string     `json:"scope"`
+	Position  *int       `json:"position"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// for approving or rejecting comments in bulk
+type CommentModeration struct {
+	CommentIDs []uuid.UUID `json:"comment_ids"`
+	Status     string      `json:"status"`
+}
+
+// open reports of a post or comment along with the number of reports of each reason
+type ReportGroup struct {
+	TargetType      string           `json:"target_type"`
+	TargetID        uuid.UUID        `json:"target_id"`
+	Reporters       int64            `json:"reporters"`
+	Reasons         map[string]int64 `json:"reasons" gorm:"-"`
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+	LastReportedAt  time.Time        `json:"last_reported_at"`
+}
+
+// for resolving the open reports of a post or comment
+type ReportResolution struct {
+	Action      string `json:"action"`
+	Note        string `json:"note"`
+	SuspendDays int    `json:"suspend_days"`
+}
+
+// for locking or unlocking the comments on a post
+type CommentLock struct {
+	Locked bool `json:"locked"`
+}
+
+// for telling whether the content checked for spam was spam or ham
+type SpamReview struct {
+	Review string `json:"review"`
+}
+
+// for inviting a user to write a post
+type AuthorInvite struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// visitor reading a post along with the site they came from
+type Visit struct {
+	Visitor  string
+	Referrer string
+}
+
+// views of a post within a date range
+type PostStats struct {
+	PostID     uuid.UUID      `json:"post_id"`
+	TotalViews int64          `json:"total_views"`
+	Views      int64          `json:"views"`
+	Visitors   int64          `json:"visitors"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Days       []DailyStat    `json:"days"`
+	Referrers  []ReferrerStat `json:"referrers"`
+}
+
+// views and unique visitors of a post on a day
+type DailyStat struct {
+	Day      time.Time `json:"day"`
+	Views    int64     `json:"views"`
+	Visitors int64     `json:"visitors"`
+}
+
+// views of a post coming from a referrer
+type ReferrerStat struct {
+	Referrer string `json:"referrer"`
+	Views    int64  `json:"views"`
+}
+
+// user who reacted to a post or comment
+type Reactor struct {
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	ReactedAt time.Time `json:"reacted_at"`
+}
+
+// slug and last modification date of a page listed in the sitemap
+type SitemapEntry struct {
+	Slug         string
+	CanonicalURL string
+	UpdatedAt    time.Time
+}
+
+// last modification date of a page of the post sitemaps
+type SitemapPage struct {
+	Page      int
+	UpdatedAt time.Time
+}
+
 // assign JWT claims along with registered claims
 type JWTClaims struct {
 	UserID   uuid.UUID `json:"user_id"`
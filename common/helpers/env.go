@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// reads an integer env variable, falling back to the default when it is empty or invalid
+func EnvInt64(name string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// reads a decimal env variable, falling back to the default when it is empty or invalid
+func EnvFloat(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// reads a comma separated env variable, falling back to the default list when it is empty
+func EnvList(name string, fallback string) []string {
+	value := os.Getenv(name)
+	if strings.TrimSpace(value) == "" {
+		value = fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splits the search text into a websearch query and a prefix query
+//
+// words ending with * are matched as prefixes using to_tsquery, everything
+// else including "quoted phrases", or and -excluded words is left to
+// websearch_to_tsquery
+func ParseSearchQuery(text string) (string, string) {
+	var words []string
+	var prefixes []string
+	var builder strings.Builder
+	quoted := false
+
+	flush := func() {
+		word := builder.String()
+		builder.Reset()
+		if word == "" {
+			return
+		}
+
+		if quoted || !strings.HasSuffix(word, "*") {
+			words = append(words, word)
+			return
+		}
+
+		//keep only the letters and digits so the word cannot change the tsquery syntax
+		negated := strings.HasPrefix(word, "-")
+		term := strings.Map(func(char rune) rune {
+			if unicode.IsLetter(char) || unicode.IsDigit(char) {
+				return char
+			}
+			return -1
+		}, word)
+		if term == "" {
+			return
+		}
+
+		if negated {
+			prefixes = append(prefixes, "!"+term+":*")
+		} else {
+			prefixes = append(prefixes, term+":*")
+		}
+	}
+
+	for _, char := range text {
+		switch {
+		case char == '"':
+			builder.WriteRune(char)
+			if quoted {
+				quoted = false
+				flush()
+			} else {
+				quoted = true
+			}
+		case unicode.IsSpace(char) && !quoted:
+			flush()
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	flush()
+
+	return strings.Join(words, " "), strings.Join(prefixes, " & ")
+}
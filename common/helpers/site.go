@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// base url of the public site, used for the links in feeds and sitemaps
+func SiteURL() string {
+	siteURL := os.Getenv("SITE_URL")
+	if siteURL == "" {
+		siteURL = "http://localhost" + os.Getenv("HTTP_PORT")
+	}
+
+	return strings.TrimRight(siteURL, "/")
+}
+
+// builds an absolute url on the public site from the path segments
+func SiteLink(segments ...string) string {
+	link := SiteURL()
+	for _, segment := range segments {
+		link += "/" + url.PathEscape(segment)
+	}
+
+	return link
+}
+
+// shortens the text to at most max characters, cutting at a word boundary when possible
+func Truncate(text string, max int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+
+	cut := runes[:max-1]
+	for i := len(cut) - 1; i > max/2; i-- {
+		if unicode.IsSpace(cut[i]) {
+			cut = cut[:i]
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(cut), unicode.IsPunct) + "…"
+}
+
+// checks if the text is an absolute http or https url
+func IsAbsoluteURL(text string) bool {
+	link, err := url.Parse(text)
+	if err != nil {
+		return false
+	}
+
+	return (link.Scheme == "http" || link.Scheme == "https") && link.Host != ""
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maximum number of characters allowed in a slug
+const maxSlugLength = 80
+
+// converts the given text into a lower case, hyphen separated slug
+//
+// letters and digits from every script are kept, accents on latin letters
+// are dropped and every other character is treated as a separator
+func Slugify(text string) string {
+	var builder strings.Builder
+	var base rune
+	length := 0
+	separator := false
+
+	for _, char := range norm.NFD.String(text) {
+		if length >= maxSlugLength {
+			break
+		}
+
+		switch {
+		case unicode.Is(unicode.Mn, char):
+			//drop accents of latin letters and keep the marks of other scripts
+			if base == 0 || unicode.Is(unicode.Latin, base) {
+				continue
+			}
+			builder.WriteRune(char)
+		case unicode.IsLetter(char) || unicode.IsDigit(char) || unicode.IsMark(char):
+			if separator && length > 0 {
+				builder.WriteRune('-')
+				length++
+			}
+			separator = false
+			base = char
+			builder.WriteRune(unicode.ToLower(char))
+			length++
+		default:
+			separator = true
+			base = 0
+		}
+	}
+
+	return strings.Trim(norm.NFC.String(builder.String()), "-")
+}
+
+// appends a numeric suffix to the slug until it is no longer taken
+func UniqueSlug(slug string, taken func(candidate string) bool) string {
+	if slug == "" {
+		slug = "untitled"
+	}
+
+	candidate := slug
+	for suffix := 2; taken(candidate); suffix++ {
+		candidate = fmt.Sprintf("%s-%d", slug, suffix)
+	}
+
+	return candidate
+}
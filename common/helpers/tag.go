@@ -0,0 +1,13 @@
+package helpers
+
+import (
+	"strings"
+)
+
+// trims, lower cases and collapses the whitespaces of a tag name
+func NormalizeTag(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, "#")
+
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -1,16 +1,175 @@
 package internals
 
 import (
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
 	"github.com/marees7/rishi-aug-2024/pkg/loggers"
 	"github.com/marees7/rishi-aug-2024/pkg/models"
+
+	"gorm.io/gorm"
 )
 
 //Migrate the model structs to the database
 func (db connection) Migrate() {
-	err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Post{}, &models.Comment{}, &models.Reply{})
+	//gorm only creates missing check constraints, drop the ones whose allowed values changed
+	if err := db.dropChangedChecks(); err != nil {
+		loggers.Error.Fatalln(err)
+	}
+
+	err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Post{}, &models.Comment{}, &models.SlugHistory{}, &models.Tag{}, &models.Media{},
+		&models.Reaction{}, &models.ReactionCount{}, &models.BookmarkCollection{}, &models.Bookmark{},
+		&models.PostDailyStat{}, &models.PostReferrerStat{}, &models.PostAuthor{},
+		&models.Series{}, &models.SeriesPost{}, &models.PostPin{},
+		&models.ImportJob{}, &models.ImportItem{}, &models.ImportRecord{},
+		&models.ModerationRule{}, &models.Notification{}, &models.Report{}, &models.AuditLog{}, &models.SpamCheck{}, &models.RateLimitBucket{}, &models.CommentRevision{})
 	if err != nil {
 		loggers.Error.Fatalln(err)
 	}
+
+	//generate slugs for the rows created before slugs existed
+	if err := db.backfillSlugs(); err != nil {
+		loggers.Error.Fatalln(err)
+	}
+
+	//make the creators of the existing posts their owners
+	if err := db.backfillPostAuthors(); err != nil {
+		loggers.Error.Fatalln(err)
+	}
+
+	//turn the replies into answers nested under their comments
+	if err := db.convertReplies(); err != nil {
+		loggers.Error.Fatalln(err)
+	}
+
+	//add the full text search columns and the indexes which gorm cannot describe
+	if err := db.createIndexes(); err != nil {
+		loggers.Error.Fatalln(err)
+	}
 	
 	loggers.Info.Println("Migrated tables successfully...")
 }
+
+// drop the check constraints which are recreated with their new values by the migration
+func (db connection) dropChangedChecks() error {
+	return db.Exec(`ALTER TABLE IF EXISTS users DROP CONSTRAINT IF EXISTS chk_users_role`).Error
+}
+
+// assign a unique slug to every post and category which does not have one yet
+func (db connection) backfillSlugs() error {
+	tables := []struct {
+		table  string
+		id     string
+		source string
+	}{
+		{table: "posts", id: "post_id", source: "title"},
+		{table: "categories", id: "category_id", source: "category_name"},
+	}
+
+	for _, table := range tables {
+		var taken []string
+		var rows []struct {
+			ID     string
+			Source string
+		}
+
+		//collect the slugs which are already in use
+		data := db.Table(table.table).Where("slug IS NOT NULL AND slug <> ''").Pluck("slug", &taken)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		data = db.Table(table.table).Select(table.id+" AS id, "+table.source+" AS source").Where("slug IS NULL OR slug = ''").Scan(&rows)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		used := make(map[string]bool, len(taken))
+		for _, slug := range taken {
+			used[slug] = true
+		}
+
+		for _, row := range rows {
+			slug := helpers.UniqueSlug(helpers.Slugify(row.Source), func(candidate string) bool { return used[candidate] })
+			used[slug] = true
+
+			data = db.Table(table.table).Where(table.id+"=?", row.ID).Update("slug", slug)
+			if data.Error != nil {
+				return data.Error
+			}
+		}
+	}
+
+	return nil
+}
+
+// add the owner of every post which does not have its authors yet
+func (db connection) backfillPostAuthors() error {
+	return db.Exec(`INSERT INTO post_authors (post_id, user_id, role, status, created_at, accepted_at)
+		SELECT post_id, user_id, ?, ?, created_at, created_at FROM posts WHERE user_id IS NOT NULL
+		ON CONFLICT DO NOTHING`, constants.AuthorRoleOwner, constants.AuthorStatusAccepted).Error
+}
+
+// give the comments created before threads their path, and move the replies into the comments table as answers
+func (db connection) convertReplies() error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		data := tx.Exec(`UPDATE comments SET path = comment_id::text WHERE path = '' AND parent_id IS NULL`)
+		if data.Error != nil {
+			return data.Error
+		}
+
+		if !tx.Migrator().HasTable("replies") {
+			return nil
+		}
+
+		statements := []string{
+			`INSERT INTO comments (comment_id, content, content_html, user_id, post_id, parent_id, path, depth, created_at, updated_at, deleted_at)
+				SELECT r.reply_id, r.content, r.content_html, r.user_id, c.post_id, r.comment_id, c.path || '/' || r.reply_id::text, c.depth + 1,
+					r.created_at, r.updated_at, COALESCE(r.deleted_at, c.deleted_at)
+				FROM replies r JOIN comments c ON c.comment_id = r.comment_id
+				ON CONFLICT DO NOTHING`,
+			`UPDATE reactions SET target_type = 'comment' WHERE target_type = 'reply'`,
+			`UPDATE reaction_counts SET target_type = 'comment' WHERE target_type = 'reply'`,
+			`UPDATE import_items SET kind = 'comment' WHERE kind = 'reply'`,
+			`UPDATE import_records SET kind = 'comment' WHERE kind = 'reply'`,
+			`DROP TABLE replies`,
+		}
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// add the generated tsvector columns and their GIN indexes used by the search, along with the other indexes gorm cannot describe
+func (db connection) createIndexes() error {
+	statements := []string{
+		`ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(content, '')), 'C')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector)`,
+		`ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			to_tsvector('english', coalesce(content, ''))
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector)`,
+		//the threads are loaded by the prefix of their path
+		`CREATE INDEX IF NOT EXISTS idx_comments_path ON comments (path text_pattern_ops)`,
+		//only one set of site-wide moderation rules can exist
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_moderation_rules_site ON moderation_rules ((category_id IS NULL)) WHERE category_id IS NULL`,
+		//a user can only have one open report on the same content
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_reporter ON reports (target_type, target_id, reporter_id) WHERE status = 'open'`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
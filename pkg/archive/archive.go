@@ -0,0 +1,355 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// version of the archive format, archives of a newer version cannot be restored
+//
+// version 2 nests the answers to comments under their parent comment, the reply rows are only found in version 1 archives
+const Version = 2
+
+// largest line of an archive, a line holds a single row
+const maxLineSize = 64 << 20
+
+// kinds of the lines of an archive, the rows are written in this order so every row comes after the rows it refers to
+const (
+	KindHeader   string = "header"
+	KindUser     string = "user"
+	KindCategory string = "category"
+	KindTag      string = "tag"
+	KindPost     string = "post"
+	KindComment  string = "comment"
+	KindReply    string = "reply"
+	KindFooter   string = "footer"
+)
+
+// kinds of the rows in the order they are written
+var rowKinds = []string{KindUser, KindCategory, KindTag, KindPost, KindComment, KindReply}
+
+// line of an archive
+type line struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// first line of an archive
+type Header struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	//whether the users were exported along with their password hashes
+	Passwords bool `json:"passwords"`
+}
+
+// last line of an archive, an archive without one was cut short
+type Footer struct {
+	Counts map[string]int64 `json:"counts"`
+}
+
+type User struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	Username  string     `json:"username"`
+	Password  string     `json:"password,omitempty"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type Category struct {
+	CategoryID      uuid.UUID  `json:"category_id"`
+	CategoryName    string     `json:"category_name"`
+	Slug            string     `json:"slug"`
+	Description     string     `json:"description,omitempty"`
+	CommentLockDays int        `json:"comment_lock_days,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+type Tag struct {
+	TagID     uuid.UUID `json:"tag_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// post along with its authors and tags, posts and comments whose user was deleted have no user_id
+type Post struct {
+	PostID          uuid.UUID    `json:"post_id"`
+	Title           string       `json:"title"`
+	Slug            string       `json:"slug"`
+	Content         string       `json:"content"`
+	ContentFormat   string       `json:"content_format"`
+	ContentHTML     string       `json:"content_html,omitempty"`
+	Description     string       `json:"description,omitempty"`
+	Status          string       `json:"status"`
+	Visibility      string       `json:"visibility"`
+	PasswordHash    string       `json:"password_hash,omitempty"`
+	MetaTitle       string       `json:"meta_title,omitempty"`
+	MetaDescription string       `json:"meta_description,omitempty"`
+	CanonicalURL    string       `json:"canonical_url,omitempty"`
+	OGImage         string       `json:"og_image,omitempty"`
+	NoIndex         bool         `json:"no_index,omitempty"`
+	CommentsLocked  bool         `json:"comments_locked,omitempty"`
+	UserID          *uuid.UUID   `json:"user_id,omitempty"`
+	CategoryID      uuid.UUID    `json:"category_id"`
+	Authors         []PostAuthor `json:"authors,omitempty"`
+	TagIDs          []uuid.UUID  `json:"tag_ids,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	DeletedAt       *time.Time   `json:"deleted_at,omitempty"`
+}
+
+type PostAuthor struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  *uuid.UUID `json:"invited_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// comment of a post, the answers to a comment come after it
+type Comment struct {
+	CommentID   uuid.UUID  `json:"comment_id"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html,omitempty"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	PostID      uuid.UUID  `json:"post_id"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	EditCount   int        `json:"edit_count,omitempty"`
+	Deleted     bool       `json:"deleted,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// answer to a comment of a version 1 archive
+type Reply struct {
+	ReplyID     uuid.UUID  `json:"reply_id"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html,omitempty"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	CommentID   uuid.UUID  `json:"comment_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// describe the number of rows of each kind, such as the counts of a restored archive
+func Summary(counts map[string]int64) string {
+	parts := make([]string, 0, len(rowKinds))
+
+	for _, kind := range rowKinds {
+		//only version 1 archives have reply rows
+		if kind == KindReply && counts[kind] == 0 {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%d %s rows", counts[kind], kind))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// writes an archive one row at a time
+type Writer struct {
+	writer *bufio.Writer
+	kind   int
+	counts map[string]int64
+}
+
+// start an archive by writing its header
+func NewWriter(writer io.Writer, header Header) (*Writer, error) {
+	archive := Writer{writer: bufio.NewWriter(writer), counts: make(map[string]int64)}
+
+	header.Version = Version
+	if err := archive.writeLine(KindHeader, header); err != nil {
+		return nil, err
+	}
+
+	return &archive, nil
+}
+
+// write a row, the rows of a kind have to be written after the rows of the kinds before it
+func (archive *Writer) Write(kind string, row any) error {
+	position := slices.Index(rowKinds, kind)
+	if position < 0 {
+		return fmt.Errorf("unknown kind %q", kind)
+	} else if position < archive.kind {
+		return fmt.Errorf("%s rows cannot be written after %s rows", kind, rowKinds[archive.kind])
+	}
+	archive.kind = position
+
+	if err := archive.writeLine(kind, row); err != nil {
+		return err
+	}
+
+	archive.counts[kind]++
+	return nil
+}
+
+// finish the archive by writing its footer, an archive which is not closed cannot be restored
+func (archive *Writer) Close() error {
+	if err := archive.writeLine(KindFooter, Footer{Counts: archive.counts}); err != nil {
+		return err
+	}
+
+	return archive.writer.Flush()
+}
+
+// number of rows written of each kind
+func (archive *Writer) Counts() map[string]int64 {
+	return archive.counts
+}
+
+func (archive *Writer) writeLine(kind string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(line{Kind: kind, Data: raw})
+	if err != nil {
+		return err
+	}
+
+	if _, err := archive.writer.Write(append(encoded, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reads an archive one row at a time
+type Reader struct {
+	scanner *bufio.Scanner
+	header  Header
+	kind    int
+	counts  map[string]int64
+	number  int
+	done    bool
+}
+
+// start reading an archive, the header is read right away to reject the archives of another version
+func NewReader(reader io.Reader) (*Reader, error) {
+	archive := Reader{scanner: bufio.NewScanner(reader), counts: make(map[string]int64)}
+	archive.scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	kind, data, err := archive.readLine()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("archive is empty")
+	} else if err != nil {
+		return nil, err
+	} else if kind != KindHeader {
+		return nil, fmt.Errorf("line %d: archive does not start with a header", archive.number)
+	}
+
+	if err := json.Unmarshal(data, &archive.header); err != nil {
+		return nil, fmt.Errorf("line %d: invalid header: %w", archive.number, err)
+	}
+	if archive.header.Version < 1 || archive.header.Version > Version {
+		return nil, fmt.Errorf("archive version %d is not supported, only versions up to %d are", archive.header.Version, Version)
+	}
+
+	return &archive, nil
+}
+
+// header of the archive
+func (archive *Reader) Header() Header {
+	return archive.header
+}
+
+// read the next row, io.EOF is returned once the footer is read and matches the rows which were read
+func (archive *Reader) Next() (string, json.RawMessage, error) {
+	if archive.done {
+		return "", nil, io.EOF
+	}
+
+	kind, data, err := archive.readLine()
+	if errors.Is(err, io.EOF) {
+		return "", nil, fmt.Errorf("archive is cut short, its footer is missing")
+	} else if err != nil {
+		return "", nil, err
+	}
+
+	if kind == KindFooter {
+		return "", nil, archive.readFooter(data)
+	}
+
+	position := slices.Index(rowKinds, kind)
+	if position < 0 {
+		return "", nil, fmt.Errorf("line %d: unknown kind %q", archive.number, kind)
+	} else if position < archive.kind {
+		return "", nil, fmt.Errorf("line %d: %s rows cannot come after %s rows", archive.number, kind, rowKinds[archive.kind])
+	}
+	archive.kind = position
+	archive.counts[kind]++
+
+	return kind, data, nil
+}
+
+// line number of the row which was read last, used to point at the invalid rows
+func (archive *Reader) Line() int {
+	return archive.number
+}
+
+// number of rows read of each kind
+func (archive *Reader) Counts() map[string]int64 {
+	return archive.counts
+}
+
+// check the counts of the footer against the rows which were read
+func (archive *Reader) readFooter(data json.RawMessage) error {
+	var footer Footer
+
+	if err := json.Unmarshal(data, &footer); err != nil {
+		return fmt.Errorf("line %d: invalid footer: %w", archive.number, err)
+	}
+
+	for _, kind := range rowKinds {
+		if footer.Counts[kind] != archive.counts[kind] {
+			return fmt.Errorf("archive should have %d %s rows but has %d", footer.Counts[kind], kind, archive.counts[kind])
+		}
+	}
+
+	if archive.scanner.Scan() {
+		return fmt.Errorf("line %d: nothing can follow the footer", archive.number+1)
+	}
+
+	archive.done = true
+	return io.EOF
+}
+
+func (archive *Reader) readLine() (string, json.RawMessage, error) {
+	var decoded line
+
+	if !archive.scanner.Scan() {
+		if err := archive.scanner.Err(); err != nil {
+			return "", nil, err
+		}
+		return "", nil, io.EOF
+	}
+	archive.number++
+
+	if err := json.Unmarshal(archive.scanner.Bytes(), &decoded); err != nil {
+		return "", nil, fmt.Errorf("line %d: %w", archive.number, err)
+	}
+
+	return decoded.Kind, decoded.Data, nil
+}
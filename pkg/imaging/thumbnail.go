@@ -0,0 +1,83 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	//register the decoders of the supported image formats
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// returned when the image has more pixels than allowed
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// reads the width and height from the header of the image without decoding it, so images claiming huge
+// dimensions are refused before they are decoded into memory
+func Dimensions(reader io.Reader, maxPixels int64) (int, int, error) {
+	config, _, err := image.DecodeConfig(reader)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if config.Width <= 0 || config.Height <= 0 {
+		return 0, 0, fmt.Errorf("invalid image dimensions %dx%d", config.Width, config.Height)
+	} else if maxPixels > 0 && int64(config.Width)*int64(config.Height) > maxPixels {
+		return 0, 0, fmt.Errorf("%w: %dx%d is over %d pixels", ErrTooLarge, config.Width, config.Height, maxPixels)
+	}
+
+	return config.Width, config.Height, nil
+}
+
+// creates a thumbnail which fits inside a maxSize x maxSize box, images with more than maxPixels pixels are refused
+//
+// images with transparency are encoded as png and every other image as jpeg,
+// the content type of the encoded thumbnail is returned along with it
+func Thumbnail(content []byte, maxSize int, maxPixels int64) (*bytes.Buffer, string, error) {
+	if _, _, err := Dimensions(bytes.NewReader(content), maxPixels); err != nil {
+		return nil, "", err
+	}
+
+	source, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	//keep the aspect ratio and never upscale small images
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = max(1, height*maxSize/width)
+			width = maxSize
+		} else {
+			width = max(1, width*maxSize/height)
+			height = maxSize
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var buffer bytes.Buffer
+	if format == "png" || format == "gif" {
+		if err := png.Encode(&buffer, thumbnail); err != nil {
+			return nil, "", err
+		}
+		return &buffer, "image/png", nil
+	}
+
+	if err := jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, "", err
+	}
+
+	return &buffer, "image/jpeg", nil
+}
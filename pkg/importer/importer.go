@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+)
+
+// everything read from an export, the items are kept in the order they were found
+type Document struct {
+	//identifies the exported site, so the same items of different sites are told apart
+	Site       string
+	Authors    []Author
+	Categories []Category
+	Posts      []Post
+	//items which could not be read, the rest of the document can still be imported
+	Errors []Issue
+	//items which are left out on purpose such as pages and attachments
+	Skipped []Issue
+}
+
+// author of the exported posts
+type Author struct {
+	Login string
+	Email string
+	Name  string
+}
+
+// category of the exported posts
+type Category struct {
+	Name        string
+	Slug        string
+	Description string
+}
+
+// exported post along with its comments
+type Post struct {
+	SourceID      string
+	Title         string
+	Slug          string
+	Content       string
+	ContentFormat string
+	Description   string
+	Status        string
+	Visibility    string
+	Password      string
+	Author        string
+	Categories    []string
+	Tags          []string
+	Comments      []Comment
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// exported comment, comments with a parent answer another comment of the post
+type Comment struct {
+	SourceID  string
+	ParentID  string
+	Author    string
+	Email     string
+	Content   string
+	Approved  bool
+	CreatedAt time.Time
+}
+
+// item which could not be read or was left out along with the reason
+type Issue struct {
+	SourceID string
+	Title    string
+	Reason   string
+}
+
+// read an uploaded export, markdown files are sent one at a time or as a zip archive
+func Parse(source string, name string, content []byte) (*Document, error) {
+	switch source {
+	case constants.ImportSourceWXR:
+		return ParseWXR(bytes.NewReader(content))
+	case constants.ImportSourceMarkdown:
+		if strings.EqualFold(path.Ext(name), ".zip") {
+			archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid zip archive: %w", err)
+			}
+
+			return ParseMarkdownFiles(archive)
+		}
+
+		post, err := ParseMarkdown(path.Base(name), content)
+		if err != nil {
+			return nil, err
+		}
+
+		document := Document{}
+		document.addMarkdownPost(post)
+		return &document, nil
+	default:
+		return nil, fmt.Errorf("source must be either %s or %s", constants.ImportSourceWXR, constants.ImportSourceMarkdown)
+	}
+}
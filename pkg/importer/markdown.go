@@ -0,0 +1,208 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+	"github.com/marees7/rishi-aug-2024/common/helpers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layouts accepted for the dates of the front matter
+var frontMatterDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// yaml front matter of a markdown file as written by hugo and jekyll
+type frontMatter struct {
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug"`
+	Description string     `yaml:"description"`
+	Summary     string     `yaml:"summary"`
+	Date        string     `yaml:"date"`
+	LastMod     string     `yaml:"lastmod"`
+	Draft       bool       `yaml:"draft"`
+	Author      stringList `yaml:"author"`
+	Authors     stringList `yaml:"authors"`
+	Categories  stringList `yaml:"categories"`
+	Tags        stringList `yaml:"tags"`
+}
+
+// list which can also be written as a single value
+type stringList []string
+
+func (list *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*list = stringList{node.Value}
+		return nil
+	}
+
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+
+	*list = values
+	return nil
+}
+
+// read every markdown file of the folder, the files which cannot be read are reported without stopping the others
+func ParseMarkdownFiles(fsys fs.FS) (*Document, error) {
+	document := Document{}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		extension := strings.ToLower(path.Ext(name))
+		if entry.IsDir() || (extension != ".md" && extension != ".markdown") {
+			return nil
+		}
+
+		//section pages of hugo describe a folder rather than a post
+		if path.Base(name) == "_index.md" {
+			document.Skipped = append(document.Skipped, Issue{SourceID: name, Reason: "section pages are not imported"})
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			document.Errors = append(document.Errors, Issue{SourceID: name, Reason: err.Error()})
+			return nil
+		}
+
+		post, err := ParseMarkdown(name, content)
+		if err != nil {
+			document.Errors = append(document.Errors, Issue{SourceID: name, Reason: err.Error()})
+			return nil
+		}
+
+		document.addMarkdownPost(post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// add the post along with its author and categories, which markdown files only name
+func (document *Document) addMarkdownPost(post *Post) {
+	if login := helpers.Slugify(post.Author); login != "" {
+		if !slices.ContainsFunc(document.Authors, func(author Author) bool { return author.Login == login }) {
+			document.Authors = append(document.Authors, Author{Login: login, Name: post.Author})
+		}
+		post.Author = login
+	}
+
+	for _, category := range post.Categories {
+		if !slices.ContainsFunc(document.Categories, func(known Category) bool { return strings.EqualFold(known.Name, category) }) {
+			document.Categories = append(document.Categories, Category{Name: category, Slug: helpers.Slugify(category)})
+		}
+	}
+
+	document.Posts = append(document.Posts, *post)
+}
+
+// read a markdown file along with its yaml front matter, the name identifies the post in later imports
+func ParseMarkdown(name string, content []byte) (*Post, error) {
+	var matter frontMatter
+
+	content = bytes.TrimPrefix(content, []byte("\uFEFF"))
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	if bytes.HasPrefix(content, []byte("+++")) {
+		return nil, fmt.Errorf("toml front matter is not supported")
+	}
+
+	body := content
+	if rest, ok := bytes.CutPrefix(content, []byte("---\n")); ok {
+		//an empty front matter is closed right away
+		closing := []byte("\n---")
+		end := bytes.Index(rest, closing)
+		if bytes.HasPrefix(rest, []byte("---")) {
+			end, closing = 0, []byte("---")
+		} else if end < 0 {
+			return nil, fmt.Errorf("front matter is not closed")
+		}
+
+		if err := yaml.Unmarshal(rest[:end], &matter); err != nil {
+			return nil, fmt.Errorf("invalid front matter: %w", err)
+		}
+		body = rest[end+len(closing):]
+	}
+
+	post := Post{
+		SourceID:      name,
+		Title:         strings.TrimSpace(matter.Title),
+		Slug:          strings.TrimSpace(matter.Slug),
+		Content:       strings.TrimSpace(string(body)),
+		ContentFormat: constants.ContentFormatMarkdown,
+		Description:   strings.TrimSpace(matter.Description),
+		Status:        constants.PostStatusPublished,
+		Visibility:    constants.VisibilityPublic,
+		Categories:    trimList(matter.Categories),
+		Tags:          trimList(matter.Tags),
+	}
+
+	//the file name is the slug of hugo posts without one
+	if post.Slug == "" {
+		post.Slug = helpers.Slugify(strings.TrimSuffix(path.Base(name), path.Ext(name)))
+	}
+	if post.Description == "" {
+		post.Description = strings.TrimSpace(matter.Summary)
+	}
+	if matter.Draft {
+		post.Status = constants.PostStatusDraft
+	}
+
+	if authors := trimList(append(matter.Author, matter.Authors...)); len(authors) > 0 {
+		post.Author = authors[0]
+	}
+
+	var err error
+	if post.CreatedAt, err = frontMatterDate(matter.Date); err != nil {
+		return nil, err
+	}
+	if post.UpdatedAt, err = frontMatterDate(matter.LastMod); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// parse a date of the front matter, missing dates are left empty
+func frontMatterDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range frontMatterDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// drop the empty values of the list
+func trimList(values []string) []string {
+	var trimmed []string
+
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+
+	return trimmed
+}
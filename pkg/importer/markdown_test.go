@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+)
+
+func TestParseMarkdownFiles(t *testing.T) {
+	document, err := ParseMarkdownFiles(os.DirFS("testdata/site"))
+	if err != nil {
+		t.Fatalf("ParseMarkdownFiles: %v", err)
+	}
+
+	if len(document.Posts) != 2 {
+		t.Fatalf("parsed %d posts, want 2", len(document.Posts))
+	}
+
+	frost := document.Posts[0]
+	if frost.SourceID != "posts/first-frost.md" || frost.Title != "First frost" || frost.Slug != "first-frost" || frost.Description != "Protect the seedlings" {
+		t.Fatalf("post = %+v", frost)
+	}
+	if frost.Content != "Cover them **at night**." || frost.ContentFormat != constants.ContentFormatMarkdown || frost.Status != constants.PostStatusPublished {
+		t.Fatalf("content = %q (%s), status = %s", frost.Content, frost.ContentFormat, frost.Status)
+	}
+	if !frost.CreatedAt.Equal(time.Date(2024, 10, 20, 7, 0, 0, 0, time.UTC)) || !frost.UpdatedAt.Equal(time.Date(2024, 10, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("created at %s, updated at %s", frost.CreatedAt, frost.UpdatedAt)
+	}
+	if strings.Join(frost.Categories, ",") != "Vegetables,Winter" || strings.Join(frost.Tags, ",") != "frost" {
+		t.Fatalf("categories = %v, tags = %v", frost.Categories, frost.Tags)
+	}
+
+	seeds := document.Posts[1]
+	if seeds.Slug != "seeds-2025" || seeds.Description != "What to order" || seeds.Status != constants.PostStatusDraft {
+		t.Fatalf("post = %+v", seeds)
+	}
+
+	//the authors are only named, they are found again by the login made of their name
+	if frost.Author != "rose-gardner" || seeds.Author != "rose-gardner" {
+		t.Fatalf("authors = %q and %q", frost.Author, seeds.Author)
+	}
+	if len(document.Authors) != 1 || document.Authors[0] != (Author{Login: "rose-gardner", Name: "Rose Gardner"}) {
+		t.Fatalf("authors = %+v", document.Authors)
+	}
+
+	//the categories are only added once whatever their case
+	if len(document.Categories) != 2 || document.Categories[0].Slug != "vegetables" || document.Categories[1].Slug != "winter" {
+		t.Fatalf("categories = %+v", document.Categories)
+	}
+
+	if len(document.Errors) != 1 || document.Errors[0].SourceID != "notes/broken.md" || document.Errors[0].Reason != "front matter is not closed" {
+		t.Fatalf("errors = %+v", document.Errors)
+	}
+	if len(document.Skipped) != 1 || document.Skipped[0].SourceID != "posts/_index.md" {
+		t.Fatalf("skipped = %+v", document.Skipped)
+	}
+}
+
+func TestParseMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "toml front matter", content: "+++\ntitle = \"post\"\n+++\n"},
+		{name: "invalid yaml", content: "---\ntitle: [post\n---\n"},
+		{name: "invalid date", content: "---\ndate: yesterday\n---\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseMarkdown("post.md", []byte(test.content)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseMarkdownWithoutFrontMatter(t *testing.T) {
+	post, err := ParseMarkdown("Hello World.md", []byte("\uFEFF# Hello\r\n\r\ntext\r\n"))
+	if err != nil {
+		t.Fatalf("ParseMarkdown: %v", err)
+	}
+
+	if post.Slug != "hello-world" || post.Content != "# Hello\n\ntext" || post.Title != "" {
+		t.Fatalf("post = %+v", post)
+	}
+}
+
+func TestParseZipArchive(t *testing.T) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, content := range map[string]string{
+		"blog/one.md":   "---\ntitle: One\n---\nfirst",
+		"blog/two.md":   "---\ntitle: Two\n---\nsecond",
+		"blog/logo.png": "not markdown",
+	} {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := Parse(constants.ImportSourceMarkdown, "export.ZIP", buffer.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(document.Posts) != 2 || document.Posts[0].Title != "One" || document.Posts[1].Title != "Two" {
+		t.Fatalf("posts = %+v", document.Posts)
+	}
+
+	if _, err := Parse(constants.ImportSourceMarkdown, "export.zip", []byte("not a zip")); err == nil {
+		t.Fatal("expected an error for an invalid archive")
+	}
+	if _, err := Parse("blogger", "export.xml", nil); err == nil {
+		t.Fatal("expected an error for an unknown source")
+	}
+}
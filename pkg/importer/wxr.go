@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+)
+
+// layout of the dates written by wordpress
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// namespace of the full content of an item
+const wxrContentSpace = "http://purl.org/rss/1.0/modules/content/"
+
+// wordpress extended rss file, the namespaces of wordpress are matched by the local names
+// because their urls change with the version of the export
+type wxrFile struct {
+	Channel struct {
+		Link        string        `xml:"link"`
+		BaseSiteURL string        `xml:"base_site_url"`
+		Authors     []wxrAuthor   `xml:"author"`
+		Categories  []wxrCategory `xml:"category"`
+		Items       []wxrItem     `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrAuthor struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrCategory struct {
+	Slug        string `xml:"category_nicename"`
+	Name        string `xml:"cat_name"`
+	Description string `xml:"category_description"`
+}
+
+type wxrItem struct {
+	Title       string       `xml:"title"`
+	PubDate     string       `xml:"pubDate"`
+	Creator     string       `xml:"creator"`
+	Encoded     []wxrEncoded `xml:"encoded"`
+	PostID      string       `xml:"post_id"`
+	Date        string       `xml:"post_date"`
+	DateGMT     string       `xml:"post_date_gmt"`
+	ModifiedGMT string       `xml:"post_modified_gmt"`
+	Name        string       `xml:"post_name"`
+	Status      string       `xml:"status"`
+	Type        string       `xml:"post_type"`
+	Password    string       `xml:"post_password"`
+	Terms       []wxrTerm    `xml:"category"`
+	Comments    []wxrComment `xml:"comment"`
+}
+
+// content and excerpt of an item, both are named encoded and told apart by their namespace
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// category or tag of an item
+type wxrTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID          string `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Date        string `xml:"comment_date"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      string `xml:"comment_parent"`
+}
+
+// read a wordpress export, only the posts are imported and the pages, attachments and trashed posts are skipped
+func ParseWXR(reader io.Reader) (*Document, error) {
+	var file wxrFile
+
+	if err := xml.NewDecoder(reader).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid wordpress export: %w", err)
+	}
+
+	channel := file.Channel
+	document := Document{Site: strings.TrimSpace(channel.BaseSiteURL)}
+	if document.Site == "" {
+		document.Site = strings.TrimSpace(channel.Link)
+	}
+
+	for _, author := range channel.Authors {
+		document.Authors = append(document.Authors, Author{
+			Login: strings.TrimSpace(author.Login),
+			Email: strings.TrimSpace(author.Email),
+			Name:  strings.TrimSpace(author.DisplayName),
+		})
+	}
+
+	for _, category := range channel.Categories {
+		document.Categories = append(document.Categories, Category{
+			Name:        strings.TrimSpace(category.Name),
+			Slug:        strings.TrimSpace(category.Slug),
+			Description: strings.TrimSpace(category.Description),
+		})
+	}
+
+	for _, item := range channel.Items {
+		sourceID := strings.TrimSpace(item.PostID)
+		title := strings.TrimSpace(item.Title)
+
+		if item.Type != "post" {
+			document.Skipped = append(document.Skipped, Issue{SourceID: sourceID, Title: title, Reason: fmt.Sprintf("%s items are not imported", item.Type)})
+			continue
+		}
+
+		status, visibility, ok := wxrStatus(item.Status)
+		if !ok {
+			document.Skipped = append(document.Skipped, Issue{SourceID: sourceID, Title: title, Reason: fmt.Sprintf("%s posts are not imported", item.Status)})
+			continue
+		}
+
+		post := Post{
+			SourceID:      sourceID,
+			Title:         title,
+			Slug:          strings.TrimSpace(item.Name),
+			ContentFormat: constants.ContentFormatHTML,
+			Status:        status,
+			Visibility:    visibility,
+			Password:      item.Password,
+			Author:        strings.TrimSpace(item.Creator),
+			CreatedAt:     wxrDate(item.DateGMT, item.Date, item.PubDate),
+			UpdatedAt:     wxrDate(item.ModifiedGMT),
+		}
+
+		for _, encoded := range item.Encoded {
+			if encoded.XMLName.Space == wxrContentSpace {
+				post.Content = encoded.Value
+			} else if strings.Contains(encoded.XMLName.Space, "excerpt") {
+				post.Description = strings.TrimSpace(encoded.Value)
+			}
+		}
+
+		for _, term := range item.Terms {
+			switch term.Domain {
+			case "category":
+				post.Categories = append(post.Categories, strings.TrimSpace(term.Name))
+			case "post_tag":
+				post.Tags = append(post.Tags, strings.TrimSpace(term.Name))
+			}
+		}
+
+		for _, comment := range item.Comments {
+			//pingbacks and trackbacks are links from other sites rather than comments
+			if comment.Type != "" && comment.Type != "comment" {
+				continue
+			}
+
+			parentID := strings.TrimSpace(comment.Parent)
+			if parentID == "0" {
+				parentID = ""
+			}
+
+			post.Comments = append(post.Comments, Comment{
+				SourceID:  strings.TrimSpace(comment.ID),
+				ParentID:  parentID,
+				Author:    strings.TrimSpace(comment.Author),
+				Email:     strings.TrimSpace(comment.AuthorEmail),
+				Content:   comment.Content,
+				Approved:  comment.Approved == "1",
+				CreatedAt: wxrDate(comment.DateGMT, comment.Date),
+			})
+		}
+
+		document.Posts = append(document.Posts, post)
+	}
+
+	return &document, nil
+}
+
+// map the status of a wordpress post onto the status and visibility of a post
+func wxrStatus(status string) (string, string, bool) {
+	switch status {
+	case "publish":
+		return constants.PostStatusPublished, constants.VisibilityPublic, true
+	case "private":
+		return constants.PostStatusPublished, constants.VisibilityPrivate, true
+	//scheduled posts are kept as drafts instead of being published early
+	case "draft", "pending", "future":
+		return constants.PostStatusDraft, constants.VisibilityPublic, true
+	default:
+		return "", "", false
+	}
+}
+
+// parse the first valid date, wordpress writes zero dates for the posts which were never published
+func wxrDate(values ...string) time.Time {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "0000") {
+			continue
+		}
+
+		if date, err := time.Parse(wxrDateLayout, value); err == nil {
+			return date
+		}
+		if date, err := time.Parse(time.RFC1123Z, value); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+)
+
+// read the wordpress export of the testdata folder
+func parseWXRFixture(t *testing.T) *Document {
+	t.Helper()
+
+	file, err := os.Open("testdata/wordpress.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	document, err := ParseWXR(file)
+	if err != nil {
+		t.Fatalf("ParseWXR: %v", err)
+	}
+
+	return document
+}
+
+func TestParseWXRSiteAuthorsAndCategories(t *testing.T) {
+	document := parseWXRFixture(t)
+
+	if document.Site != "https://garden.example.com" {
+		t.Fatalf("site = %q", document.Site)
+	}
+	if len(document.Authors) != 1 || document.Authors[0] != (Author{Login: "rose", Email: "rose@example.com", Name: "Rose Gardner"}) {
+		t.Fatalf("authors = %+v", document.Authors)
+	}
+	if len(document.Categories) != 1 || document.Categories[0] != (Category{Name: "Vegetables", Slug: "vegetables", Description: "Growing vegetables"}) {
+		t.Fatalf("categories = %+v", document.Categories)
+	}
+}
+
+func TestParseWXRPosts(t *testing.T) {
+	document := parseWXRFixture(t)
+
+	if len(document.Posts) != 3 {
+		t.Fatalf("parsed %d posts, want 3", len(document.Posts))
+	}
+
+	post := document.Posts[0]
+	if post.SourceID != "10" || post.Title != "Planting tomatoes" || post.Slug != "planting-tomatoes" || post.Author != "rose" {
+		t.Fatalf("post = %+v", post)
+	}
+	if post.Content != "<p>Plant them <strong>deep</strong>.</p>" || post.ContentFormat != constants.ContentFormatHTML || post.Description != "How deep to plant" {
+		t.Fatalf("content = %q (%s), description = %q", post.Content, post.ContentFormat, post.Description)
+	}
+	if post.Status != constants.PostStatusPublished || post.Visibility != constants.VisibilityPublic {
+		t.Fatalf("status = %s, visibility = %s", post.Status, post.Visibility)
+	}
+	if !post.CreatedAt.Equal(time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)) || !post.UpdatedAt.Equal(time.Date(2024, 4, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("created at %s, updated at %s", post.CreatedAt, post.UpdatedAt)
+	}
+	if strings.Join(post.Categories, ",") != "Vegetables" || strings.Join(post.Tags, ",") != "tomatoes" {
+		t.Fatalf("categories = %v, tags = %v", post.Categories, post.Tags)
+	}
+
+	//private posts keep their password, scheduled posts become drafts and the zero dates fall back to the local date
+	private := document.Posts[1]
+	if private.Visibility != constants.VisibilityPrivate || private.Password != "letmein" || !private.CreatedAt.Equal(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("private post = %+v", private)
+	}
+	scheduled := document.Posts[2]
+	if scheduled.Status != constants.PostStatusDraft || !scheduled.CreatedAt.IsZero() {
+		t.Fatalf("scheduled post = %+v", scheduled)
+	}
+}
+
+func TestParseWXRComments(t *testing.T) {
+	comments := parseWXRFixture(t).Posts[0].Comments
+
+	//the pingback is left out
+	if len(comments) != 2 {
+		t.Fatalf("parsed %d comments, want 2", len(comments))
+	}
+
+	first, answer := comments[0], comments[1]
+	if first.SourceID != "100" || first.ParentID != "" || first.Author != "Sam" || first.Email != "sam@example.com" || !first.Approved {
+		t.Fatalf("first comment = %+v", first)
+	}
+	if answer.ParentID != "100" || answer.Approved || !answer.CreatedAt.Equal(time.Date(2024, 4, 1, 12, 15, 0, 0, time.UTC)) {
+		t.Fatalf("answer = %+v", answer)
+	}
+}
+
+func TestParseWXRSkipsOtherItems(t *testing.T) {
+	skipped := parseWXRFixture(t).Skipped
+
+	if len(skipped) != 2 {
+		t.Fatalf("skipped = %+v, want the page and the trashed post", skipped)
+	}
+	if skipped[0].SourceID != "13" || skipped[0].Reason != "page items are not imported" {
+		t.Fatalf("skipped page = %+v", skipped[0])
+	}
+	if skipped[1].SourceID != "14" || skipped[1].Reason != "trash posts are not imported" {
+		t.Fatalf("skipped post = %+v", skipped[1])
+	}
+}
+
+func TestParseWXRRejectsInvalidFiles(t *testing.T) {
+	if _, err := ParseWXR(strings.NewReader("<rss><channel>")); err == nil {
+		t.Fatal("expected an error for a cut short export")
+	}
+}
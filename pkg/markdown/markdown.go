@@ -0,0 +1,123 @@
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/marees7/rishi-aug-2024/common/constants"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// maximum number of rendered documents kept in memory
+const cacheSize = 1024
+
+var (
+	//commonmark parser along with the github flavoured extensions
+	renderer = goldmark.New(goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	))
+
+	//only the tags and attributes users are allowed to publish
+	policy = newPolicy()
+
+	//the inputs left by the sanitizer, only the disabled checkboxes of the task lists are kept
+	inputTag = regexp.MustCompile(`<input[^>]*>`)
+
+	cache   = make(map[string]string, cacheSize)
+	cacheMu sync.RWMutex
+)
+
+// creates the allow list used to sanitize the rendered html
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+
+	//keep the language of fenced code blocks for syntax highlighting
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	policy.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).OnElements("input")
+	policy.RequireNoFollowOnLinks(true)
+
+	return policy
+}
+
+// converts the source into sanitized html using the given format
+//
+// rendered documents are cached using the hash of the format and the source,
+// so an edited document never returns the html of its old version
+func Render(source string, format string) (string, error) {
+	if format == "" {
+		format = constants.ContentFormatMarkdown
+	}
+
+	sum := sha256.Sum256([]byte(format + "\x00" + source))
+	key := hex.EncodeToString(sum[:])
+
+	cacheMu.RLock()
+	rendered, ok := cache[key]
+	cacheMu.RUnlock()
+	if ok {
+		return rendered, nil
+	}
+
+	var unsafe string
+	if format == constants.ContentFormatPlain {
+		unsafe = renderPlain(source)
+	} else if format == constants.ContentFormatHTML {
+		//html written elsewhere, such as imported posts, is only sanitized
+		unsafe = source
+	} else {
+		var buffer bytes.Buffer
+		if err := renderer.Convert([]byte(source), &buffer); err != nil {
+			return "", err
+		}
+		unsafe = buffer.String()
+	}
+
+	rendered = inputTag.ReplaceAllStringFunc(policy.Sanitize(unsafe), func(tag string) string {
+		if strings.Contains(tag, ` type="checkbox"`) && strings.Contains(tag, ` disabled=`) {
+			return tag
+		}
+		return ""
+	})
+
+	cacheMu.Lock()
+	//drop the whole cache instead of tracking the usage of every entry
+	if len(cache) >= cacheSize {
+		cache = make(map[string]string, cacheSize)
+	}
+	cache[key] = rendered
+	cacheMu.Unlock()
+
+	return rendered, nil
+}
+
+// escapes plain text and keeps its paragraphs and line breaks
+func renderPlain(source string) string {
+	var builder strings.Builder
+
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	for _, paragraph := range strings.Split(source, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		builder.WriteString("<p>")
+		builder.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		builder.WriteString("</p>\n")
+	}
+
+	return builder.String()
+}
@@ -3,72 +3,431 @@ package models
 import (
 	"time"
 
+	"github.com/marees7/rishi-aug-2024/common/constants"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// contains the user details
+// contains the user details, suspended users cannot log in or write until their suspension ends
 type User struct {
-	UserID    uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid;primary_key"`
-	Email     string         `json:"email,omitempty" validate:"required,email" gorm:"unique;not null;"`
-	Name      string         `json:"name,omitempty" gorm:"not null;default:'anonymous'"`
-	Username  string         `json:"username,omitempty" gorm:"unique;not null;"`
-	Password  string         `json:"password,omitempty" gorm:"not null;"`
-	Role      string         `json:"role,omitempty" gorm:"check:role='admin' or role='user'"`
-	Comments  []Comment      `json:"comments,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Replies   []Reply        `json:"replies,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Posts     []Post         `json:"posts,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
-	DeletedAt gorm.DeletedAt `json:"-"`
-}
-
-// contains category details
+	UserID         uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid;primary_key"`
+	Email          string         `json:"email,omitempty" validate:"required,email" gorm:"unique;not null;"`
+	Name           string         `json:"name,omitempty" gorm:"not null;default:'anonymous'"`
+	Username       string         `json:"username,omitempty" gorm:"unique;not null;"`
+	Password       string         `json:"password,omitempty" gorm:"not null;"`
+	Role           string         `json:"role,omitempty" gorm:"check:role='admin' or role='moderator' or role='user'"`
+	SuspendedUntil *time.Time     `json:"suspended_until,omitempty"`
+	Comments       []Comment      `json:"comments,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Posts          []Post         `json:"posts,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt      time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt      time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+	DeletedAt      gorm.DeletedAt `json:"-"`
+}
+
+// contains category details, the comments on its posts are locked once the posts are older than the lock days
 type Category struct {
-	CategoryID   uuid.UUID      `json:"category_id,omitempty" gorm:"type:uuid;primary_key"`
-	CategoryName string         `json:"category_name,omitempty" gorm:"unique;not null;"`
-	Description  string         `json:"description,omitempty"`
-	Posts        []Post         `json:"posts,omitempty" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt    time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt    time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
-	DeletedAt    gorm.DeletedAt `json:"-"`
+	CategoryID      uuid.UUID      `json:"category_id,omitempty" gorm:"type:uuid;primary_key"`
+	CategoryName    string         `json:"category_name,omitempty" gorm:"unique;not null;"`
+	Slug            string         `json:"slug,omitempty" gorm:"uniqueIndex"`
+	Description     string         `json:"description,omitempty"`
+	CommentLockDays *int           `json:"comment_lock_days,omitempty" gorm:"not null;default:0"`
+	Posts           []Post         `json:"posts,omitempty" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt       time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt       time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+	DeletedAt       gorm.DeletedAt `json:"-"`
 }
 
 // contains post details
 type Post struct {
-	PostID      uuid.UUID      `json:"post_id,omitempty" gorm:"type:uuid;primary_key"`
-	Title       string         `json:"title,omitempty" gorm:"not null;"`
-	Content     string         `json:"content,omitempty" gorm:"not null;"`
-	Description string         `json:"description,omitempty"`
-	UserID      uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid"`
-	CategoryID  uuid.UUID      `json:"category_id,omitempty" gorm:"type:uuid"`
-	Comments    []Comment      `json:"comments,omitempty" gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt   time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt   time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
-	DeletedAt   gorm.DeletedAt `json:"-"`
-}
-
-// contains the comment details
+	PostID          uuid.UUID         `json:"post_id,omitempty" gorm:"type:uuid;primary_key"`
+	Title           string            `json:"title,omitempty" gorm:"not null;"`
+	Slug            string            `json:"slug,omitempty" gorm:"uniqueIndex"`
+	Content         string            `json:"content,omitempty" gorm:"not null;"`
+	ContentFormat   string            `json:"content_format,omitempty" gorm:"not null;default:'markdown'"`
+	ContentHTML     string            `json:"content_html,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	Status          string            `json:"status,omitempty" gorm:"not null;default:'published';index"`
+	Visibility      string            `json:"visibility,omitempty" gorm:"not null;default:'public';index"`
+	Password        string            `json:"password,omitempty" gorm:"-"`
+	PasswordHash    string            `json:"-"`
+	Locked          bool              `json:"locked,omitempty" gorm:"-"`
+	MetaTitle       string            `json:"meta_title,omitempty"`
+	MetaDescription string            `json:"meta_description,omitempty"`
+	CanonicalURL    string            `json:"canonical_url,omitempty"`
+	OGImage         string            `json:"og_image,omitempty"`
+	NoIndex         *bool             `json:"no_index,omitempty" gorm:"not null;default:false"`
+	UserID          uuid.UUID         `json:"user_id,omitempty" gorm:"type:uuid"`
+	User            *User             `json:"-" gorm:"foreignKey:UserID"`
+	CategoryID      uuid.UUID         `json:"category_id,omitempty" gorm:"type:uuid"`
+	Category        *Category         `json:"-" gorm:"foreignKey:CategoryID"`
+	Authors         []PostAuthor      `json:"authors,omitempty" gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Tags            []Tag             `json:"tags,omitempty" gorm:"many2many:post_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TagNames        []string          `json:"tag_names,omitempty" gorm:"-"`
+	Media           []Media           `json:"media,omitempty" gorm:"many2many:post_media;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MediaIDs        []uuid.UUID       `json:"media_ids,omitempty" gorm:"-"`
+	Comments        []Comment         `json:"comments,omitempty" gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Reactions       map[string]int64  `json:"reactions,omitempty" gorm:"-"`
+	Bookmarked      bool              `json:"bookmarked,omitempty" gorm:"-"`
+	Series          *SeriesNavigation `json:"series,omitempty" gorm:"-"`
+	Pinned          bool              `json:"pinned,omitempty" gorm:"-"`
+	ViewCount       int64             `json:"view_count" gorm:"->;not null;default:0"`
+	HiddenAt        *time.Time        `json:"hidden_at,omitempty"`
+	HiddenReason    string            `json:"hidden_reason,omitempty" gorm:"not null;default:''"`
+	CommentsLocked  bool              `json:"comments_locked" gorm:"not null;default:false"`
+	CreatedAt       time.Time         `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt       time.Time         `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+	DeletedAt       gorm.DeletedAt    `json:"-"`
+}
+
+// contains a named and ordered group of posts such as the parts of a tutorial
+type Series struct {
+	SeriesID    uuid.UUID    `json:"series_id,omitempty" gorm:"type:uuid;primary_key"`
+	UserID      uuid.UUID    `json:"user_id,omitempty" gorm:"type:uuid;not null;index"`
+	Title       string       `json:"title,omitempty" gorm:"not null;"`
+	Description string       `json:"description,omitempty"`
+	PartCount   int64        `json:"part_count" gorm:"->;-:migration"`
+	Parts       []SeriesPart `json:"parts,omitempty" gorm:"-"`
+	CreatedAt   time.Time    `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt   time.Time    `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+}
+
+// contains the position of a post in a series, a post belongs to one series at most
+type SeriesPost struct {
+	SeriesID uuid.UUID `json:"series_id,omitempty" gorm:"type:uuid;primary_key"`
+	PostID   uuid.UUID `json:"post_id,omitempty" gorm:"type:uuid;primary_key;uniqueIndex"`
+	Series   *Series   `json:"-" gorm:"foreignKey:SeriesID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Position int       `json:"position" gorm:"not null;"`
+}
+
+// contains a post of a series as shown to the readers
+type SeriesPart struct {
+	PostID      uuid.UUID `json:"post_id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	Position    int       `json:"position"`
+}
+
+// contains a post recommended after reading another one, higher scores are more similar
+type RelatedPost struct {
+	PostID      uuid.UUID `json:"post_id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	Score       float64   `json:"score"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// contains the series of a post along with the parts before and after it
+type SeriesNavigation struct {
+	SeriesID uuid.UUID   `json:"series_id"`
+	Title    string      `json:"title"`
+	Position int         `json:"position"`
+	Total    int         `json:"total"`
+	Previous *SeriesPart `json:"previous,omitempty"`
+	Next     *SeriesPart `json:"next,omitempty"`
+}
+
+// contains an import of a wordpress export or of markdown files, importing the same file again resumes it
+type ImportJob struct {
+	JobID      uuid.UUID  `json:"job_id" gorm:"type:uuid;primary_key"`
+	Source     string     `json:"source" gorm:"not null;check:source='wxr' or source='markdown'"`
+	Site       string     `json:"site,omitempty"`
+	Name       string     `json:"name"`
+	Checksum   string     `json:"checksum" gorm:"not null;index"`
+	DryRun     bool       `json:"dry_run" gorm:"not null;default:false"`
+	Status     string     `json:"status" gorm:"not null;default:'running'"`
+	Created    int64      `json:"created"`
+	Skipped    int64      `json:"skipped"`
+	Failed     int64      `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime;"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// contains the outcome of importing an item of an import job
+type ImportItem struct {
+	JobID    uuid.UUID  `json:"job_id" gorm:"type:uuid;primaryKey"`
+	Kind     string     `json:"kind" gorm:"primaryKey"`
+	SourceID string     `json:"source_id" gorm:"primaryKey"`
+	Title    string     `json:"title,omitempty"`
+	Action   string     `json:"action" gorm:"not null;index"`
+	TargetID *uuid.UUID `json:"target_id,omitempty" gorm:"type:uuid"`
+	Reason   string     `json:"reason,omitempty"`
+	Job      *ImportJob `json:"-" gorm:"foreignKey:JobID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// maps an item of an exported site onto the row it was imported as, the items with a record are not imported again
+type ImportRecord struct {
+	Source    string    `json:"source" gorm:"primaryKey"`
+	Kind      string    `json:"kind" gorm:"primaryKey"`
+	SourceID  string    `json:"source_id" gorm:"primaryKey"`
+	TargetID  uuid.UUID `json:"target_id" gorm:"type:uuid;not null"`
+	JobID     uuid.UUID `json:"job_id" gorm:"type:uuid;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;"`
+}
+
+// contains a post pinned on top of the site-wide listing or of the listing of its category
+type PostPin struct {
+	PostID     uuid.UUID  `json:"post_id" gorm:"type:uuid;primaryKey"`
+	Scope      string     `json:"scope" gorm:"primaryKey;check:scope='site' or scope='category'"`
+	CategoryID *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid;index"`
+	Position   int        `json:"position" gorm:"not null;default:0"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	PinnedBy   uuid.UUID  `json:"pinned_by" gorm:"type:uuid"`
+	Post       *Post      `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime;"`
+}
+
+// contains an author of a post, every author other than the owner is invited and has to accept
+type PostAuthor struct {
+	PostID     uuid.UUID  `json:"post_id,omitempty" gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID  `json:"user_id,omitempty" gorm:"type:uuid;primary_key;index"`
+	Username   string     `json:"username,omitempty" gorm:"->;-:migration"`
+	Name       string     `json:"name,omitempty" gorm:"->;-:migration"`
+	Role       string     `json:"role,omitempty" gorm:"not null;check:role='owner' or role='co-author' or role='contributor'"`
+	Status     string     `json:"status,omitempty" gorm:"not null;default:'pending'"`
+	InvitedBy  *uuid.UUID `json:"invited_by,omitempty" gorm:"type:uuid"`
+	Post       *Post      `json:"post,omitempty" gorm:"foreignKey:PostID"`
+	CreatedAt  time.Time  `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// contains tag details
+type Tag struct {
+	TagID      uuid.UUID `json:"tag_id,omitempty" gorm:"type:uuid;primary_key"`
+	Name       string    `json:"name,omitempty" gorm:"unique;not null;"`
+	Slug       string    `json:"slug,omitempty" gorm:"unique;not null;"`
+	UsageCount int64     `json:"usage_count,omitempty" gorm:"->;-:migration"`
+	CreatedAt  time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+}
+
+// contains the uploaded file details
+type Media struct {
+	MediaID      uuid.UUID `json:"media_id,omitempty" gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	FileName     string    `json:"file_name,omitempty" gorm:"not null;"`
+	ContentType  string    `json:"content_type,omitempty" gorm:"not null;"`
+	Size         int64     `json:"size,omitempty" gorm:"not null;"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	StorageKey   string    `json:"-" gorm:"not null;"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url,omitempty" gorm:"not null;"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+}
+
+// contains the comment details, answers to a comment are comments with a parent
+//
+// the path holds the ids of the ancestors and of the comment itself separated by slashes, so a thread is loaded with a single prefix match
 type Comment struct {
-	CommentID uuid.UUID      `json:"comment_id,omitempty" gorm:"type:uuid;primary_key"`
-	Content   string         `json:"content,omitempty" gorm:"not null;default:''"`
-	UserID    uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid"`
-	PostID    uuid.UUID      `json:"post_id,omitempty" gorm:"type:uuid"`
-	Replies   []Reply        `json:"replies,omitempty" gorm:"foreignKey:CommentID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
-	DeletedAt gorm.DeletedAt `json:"-"`
+	CommentID   uuid.UUID        `json:"comment_id,omitempty" gorm:"type:uuid;primary_key"`
+	Content     string           `json:"content,omitempty" gorm:"not null;default:''"`
+	ContentHTML string           `json:"content_html,omitempty"`
+	UserID      uuid.UUID        `json:"user_id,omitempty" gorm:"type:uuid"`
+	User        *User            `json:"-" gorm:"foreignKey:UserID"`
+	PostID      uuid.UUID        `json:"post_id,omitempty" gorm:"type:uuid"`
+	ParentID    *uuid.UUID       `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+	Parent      *Comment         `json:"-" gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Path        string           `json:"-" gorm:"not null;default:''"`
+	Depth       int              `json:"depth"`
+	Status      string           `json:"status,omitempty" gorm:"not null;default:'approved';index"`
+	HoldReason  string           `json:"hold_reason,omitempty"`
+	ModeratedAt *time.Time       `json:"moderated_at,omitempty"`
+	EditedAt    *time.Time       `json:"edited_at,omitempty"`
+	EditCount   int              `json:"edit_count" gorm:"not null;default:0"`
+	Deleted     bool             `json:"deleted,omitempty" gorm:"not null;default:false"`
+	Replies     []Comment        `json:"replies,omitempty" gorm:"-"`
+	ReplyCount  int64            `json:"reply_count,omitempty" gorm:"-"`
+	MoreReplies bool             `json:"more_replies,omitempty" gorm:"-"`
+	Reactions   map[string]int64 `json:"reactions,omitempty" gorm:"-"`
+	CreatedAt   time.Time        `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt   time.Time        `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+	DeletedAt   gorm.DeletedAt   `json:"-"`
 }
 
-// contains the reply details
-type Reply struct {
-	ReplyID   uuid.UUID      `json:"reply_id,omitempty" gorm:"type:uuid;primary_key"`
-	Content   string         `json:"content,omitempty" gorm:"not null;"`
-	UserID    uuid.UUID      `json:"user_id,omitempty" gorm:"type:uuid"`
-	CommentID uuid.UUID      `json:"comment_id,omitempty" gorm:"type:uuid"`
-	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt time.Time      `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
-	DeletedAt gorm.DeletedAt `json:"-"`
+// contains a previous version of an edited comment, the first version is the comment as it was written
+type CommentRevision struct {
+	RevisionID  uuid.UUID `json:"revision_id" gorm:"type:uuid;primary_key"`
+	CommentID   uuid.UUID `json:"comment_id" gorm:"type:uuid;not null;uniqueIndex:idx_comment_revisions_version"`
+	Comment     *Comment  `json:"-" gorm:"foreignKey:CommentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Version     int       `json:"version" gorm:"not null;uniqueIndex:idx_comment_revisions_version"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html,omitempty"`
+	WrittenAt   time.Time `json:"written_at"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;"`
+}
+
+// contains the rules holding the comments for moderation, the rules of a category replace the site-wide rules
+// which have no category
+type ModerationRule struct {
+	RuleID           uuid.UUID  `json:"rule_id" gorm:"type:uuid;primary_key"`
+	CategoryID       *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+	Category         *Category  `json:"-" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	HoldFirstComment bool       `json:"hold_first_comment" gorm:"not null;default:false"`
+	HoldLinks        bool       `json:"hold_links" gorm:"not null;default:false"`
+	HoldAll          bool       `json:"hold_all" gorm:"not null;default:false"`
+	UpdatedBy        uuid.UUID  `json:"updated_by" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime;"`
+}
+
+// contains a report of a post or comment, a reporter can only have one open report of the same content
+type Report struct {
+	ReportID   uuid.UUID  `json:"report_id" gorm:"type:uuid;primary_key"`
+	TargetType string     `json:"target_type" gorm:"not null;index:idx_reports_target"`
+	TargetID   uuid.UUID  `json:"target_id" gorm:"type:uuid;not null;index:idx_reports_target"`
+	ReporterID uuid.UUID  `json:"reporter_id" gorm:"type:uuid;not null;index"`
+	Reporter   *User      `json:"-" gorm:"foreignKey:ReporterID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Reason     string     `json:"reason" gorm:"not null"`
+	Details    string     `json:"details,omitempty"`
+	Status     string     `json:"status" gorm:"not null;default:'open';index"`
+	ResolvedBy *uuid.UUID `json:"resolved_by,omitempty" gorm:"type:uuid"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+}
+
+// contains an action taken on the content or its author, the automatic actions have no actor
+type AuditLog struct {
+	AuditID    uuid.UUID  `json:"audit_id" gorm:"type:uuid;primary_key"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	Action     string     `json:"action" gorm:"not null;index"`
+	TargetType string     `json:"target_type" gorm:"not null;index:idx_audit_logs_target"`
+	TargetID   uuid.UUID  `json:"target_id" gorm:"type:uuid;not null;index:idx_audit_logs_target"`
+	Details    string     `json:"details,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+}
+
+// contains the spam check of a post or comment kept to tune the checkers, rejected posts have no target
+type SpamCheck struct {
+	CheckID     uuid.UUID         `json:"check_id" gorm:"type:uuid;primary_key"`
+	TargetType  string            `json:"target_type" gorm:"not null;index:idx_spam_checks_target"`
+	TargetID    *uuid.UUID        `json:"target_id,omitempty" gorm:"type:uuid;index:idx_spam_checks_target"`
+	UserID      uuid.UUID         `json:"user_id" gorm:"type:uuid;not null;index"`
+	ContentHash string            `json:"content_hash" gorm:"not null;index"`
+	Score       float64           `json:"score"`
+	Decision    string            `json:"decision" gorm:"not null;index"`
+	Results     []SpamCheckResult `json:"results" gorm:"serializer:json"`
+	Review      string            `json:"review,omitempty"`
+	ReviewedBy  *uuid.UUID        `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt  *time.Time        `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// contains the score a spam checker gave the content, along with why it scored or failed
+type SpamCheckResult struct {
+	Checker string  `json:"checker"`
+	Score   float64 `json:"score"`
+	Reason  string  `json:"reason,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// contains a notification of a user, such as a comment waiting for moderation on their post
+type Notification struct {
+	NotificationID uuid.UUID  `json:"notification_id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	User           *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Type           string     `json:"type" gorm:"not null"`
+	Message        string     `json:"message"`
+	PostID         *uuid.UUID `json:"post_id,omitempty" gorm:"type:uuid"`
+	CommentID      *uuid.UUID `json:"comment_id,omitempty" gorm:"type:uuid"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime;"`
+}
+
+// contains the previous slugs of posts and categories
+type SlugHistory struct {
+	SlugHistoryID uuid.UUID `json:"slug_history_id,omitempty" gorm:"type:uuid;primary_key"`
+	EntityType    string    `json:"entity_type,omitempty" gorm:"not null;uniqueIndex:idx_slug_histories_entity_slug"`
+	Slug          string    `json:"slug,omitempty" gorm:"not null;uniqueIndex:idx_slug_histories_entity_slug"`
+	EntityID      uuid.UUID `json:"entity_id,omitempty" gorm:"type:uuid;index"`
+	CreatedAt     time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+}
+
+// contains a reaction of a user on a post or comment
+type Reaction struct {
+	ReactionID uuid.UUID `json:"reaction_id,omitempty" gorm:"type:uuid;primary_key"`
+	TargetType string    `json:"target_type,omitempty" gorm:"not null;uniqueIndex:idx_reactions_target_user_type"`
+	TargetID   uuid.UUID `json:"target_id,omitempty" gorm:"type:uuid;not null;uniqueIndex:idx_reactions_target_user_type"`
+	UserID     uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;not null;uniqueIndex:idx_reactions_target_user_type;index"`
+	Type       string    `json:"type,omitempty" gorm:"not null;uniqueIndex:idx_reactions_target_user_type"`
+	CreatedAt  time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+}
+
+// contains the number of reactions of each type on a post or comment
+type ReactionCount struct {
+	TargetType string    `json:"target_type,omitempty" gorm:"primary_key"`
+	TargetID   uuid.UUID `json:"target_id,omitempty" gorm:"type:uuid;primary_key"`
+	Type       string    `json:"type,omitempty" gorm:"primary_key"`
+	Count      int64     `json:"count,omitempty" gorm:"not null;default:0"`
+}
+
+// contains the number of views and unique visitors of a post on a day
+type PostDailyStat struct {
+	PostID   uuid.UUID `json:"post_id,omitempty" gorm:"type:uuid;primary_key"`
+	Day      time.Time `json:"day,omitempty" gorm:"type:date;primary_key"`
+	Views    int64     `json:"views" gorm:"not null;default:0"`
+	Visitors int64     `json:"visitors" gorm:"not null;default:0"`
+}
+
+// contains the tokens left in the rate limit bucket of a user for an action, shared by every replica
+type RateLimitBucket struct {
+	BucketKey string    `gorm:"primary_key"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index;autoUpdateTime:false"`
+}
+
+// contains the number of views of a post on a day coming from a referrer
+type PostReferrerStat struct {
+	PostID   uuid.UUID `json:"post_id,omitempty" gorm:"type:uuid;primary_key"`
+	Day      time.Time `json:"day,omitempty" gorm:"type:date;primary_key"`
+	Referrer string    `json:"referrer,omitempty" gorm:"primary_key"`
+	Views    int64     `json:"views" gorm:"not null;default:0"`
+}
+
+// contains a named list of bookmarks of a user
+type BookmarkCollection struct {
+	CollectionID uuid.UUID `json:"collection_id,omitempty" gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;not null;uniqueIndex:idx_bookmark_collections_user_name"`
+	Name         string    `json:"name,omitempty" gorm:"not null;uniqueIndex:idx_bookmark_collections_user_name"`
+	Bookmarks    int64     `json:"bookmarks" gorm:"->;-:migration"`
+	CreatedAt    time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+}
+
+// contains a post saved by a user to read later
+type Bookmark struct {
+	BookmarkID   uuid.UUID           `json:"bookmark_id,omitempty" gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID           `json:"user_id,omitempty" gorm:"type:uuid;not null;uniqueIndex:idx_bookmarks_user_post"`
+	PostID       uuid.UUID           `json:"post_id,omitempty" gorm:"type:uuid;not null;uniqueIndex:idx_bookmarks_user_post;index"`
+	Post         *Post               `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CollectionID *uuid.UUID          `json:"collection_id,omitempty" gorm:"type:uuid;index"`
+	Collection   *BookmarkCollection `json:"collection,omitempty" gorm:"foreignKey:CollectionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt    time.Time           `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+}
+
+// check if the user is the owner or an accepted author of the post
+func (post *Post) HasAuthor(userID uuid.UUID) bool {
+	if userID == uuid.Nil {
+		return false
+	}
+
+	if post.UserID == userID {
+		return true
+	}
+
+	for _, author := range post.Authors {
+		if author.UserID == userID && author.Status == constants.AuthorStatusAccepted {
+			return true
+		}
+	}
+
+	return false
 }
 
 // assign uuid before insert a new row
@@ -77,15 +436,59 @@ func (user *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// assign uuid before insert a new row
+func (job *ImportJob) BeforeCreate(tx *gorm.DB) error {
+	job.JobID = uuid.New()
+	return nil
+}
+
 // assign uuid before insert a new row
 func (post *Post) BeforeCreate(tx *gorm.DB) error {
 	post.PostID = uuid.New()
 	return nil
 }
 
-// assign uuid before insert a new row
+// assign uuid before insert a new row, the comments placed in a thread already have one
 func (comment *Comment) BeforeCreate(tx *gorm.DB) error {
-	comment.CommentID = uuid.New()
+	if comment.CommentID == uuid.Nil {
+		comment.CommentID = uuid.New()
+	}
+	return nil
+}
+
+// assign uuid before insert a new row
+func (rule *ModerationRule) BeforeCreate(tx *gorm.DB) error {
+	rule.RuleID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (report *Report) BeforeCreate(tx *gorm.DB) error {
+	report.ReportID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (audit *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	audit.AuditID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (revision *CommentRevision) BeforeCreate(tx *gorm.DB) error {
+	revision.RevisionID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (check *SpamCheck) BeforeCreate(tx *gorm.DB) error {
+	check.CheckID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (notification *Notification) BeforeCreate(tx *gorm.DB) error {
+	notification.NotificationID = uuid.New()
 	return nil
 }
 
@@ -96,7 +499,45 @@ func (category *Category) BeforeCreate(tx *gorm.DB) error {
 }
 
 // assign uuid before insert a new row
-func (reply *Reply) BeforeCreate(tx *gorm.DB) error {
-	reply.ReplyID = uuid.New()
+func (tag *Tag) BeforeCreate(tx *gorm.DB) error {
+	tag.TagID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (media *Media) BeforeCreate(tx *gorm.DB) error {
+	if media.MediaID == uuid.Nil {
+		media.MediaID = uuid.New()
+	}
+	return nil
+}
+
+// assign uuid before insert a new row
+func (history *SlugHistory) BeforeCreate(tx *gorm.DB) error {
+	history.SlugHistoryID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (reaction *Reaction) BeforeCreate(tx *gorm.DB) error {
+	reaction.ReactionID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (collection *BookmarkCollection) BeforeCreate(tx *gorm.DB) error {
+	collection.CollectionID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (series *Series) BeforeCreate(tx *gorm.DB) error {
+	series.SeriesID = uuid.New()
+	return nil
+}
+
+// assign uuid before insert a new row
+func (bookmark *Bookmark) BeforeCreate(tx *gorm.DB) error {
+	bookmark.BookmarkID = uuid.New()
 	return nil
 }
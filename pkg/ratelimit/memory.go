@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// keeps the buckets in memory, each replica limits the requests it receives on its own
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets map[string]*Bucket
+}
+
+// create an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now, buckets: make(map[string]*Bucket)}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	bucket, ok := store.buckets[key]
+	if !ok {
+		created := NewBucket(limit, now)
+		bucket = &created
+		store.buckets[key] = bucket
+	}
+
+	return bucket.Take(limit, now), nil
+}
+
+func (store *MemoryStore) Prune(ctx context.Context, unusedFor time.Duration) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	//the buckets are compared with the clock they were updated with
+	before := store.now().Add(-unusedFor)
+	for key, bucket := range store.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(store.buckets, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// create a memory store whose clock is moved by the test
+func newTestMemoryStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	limit := Limit{Capacity: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+		t.Fatal("first request of a denied")
+	}
+	if result, _ := store.Take(ctx, "a", limit); result.Allowed || result.RetryAfter != time.Minute {
+		t.Fatalf("second request of a = %+v, want to retry after a minute", result)
+	}
+
+	//every key has its own bucket
+	if result, _ := store.Take(ctx, "b", limit); !result.Allowed {
+		t.Fatal("first request of b denied")
+	}
+
+	now = now.Add(time.Minute)
+	if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+		t.Fatal("request of a denied after the refill")
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	limit := Limit{Capacity: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	store.Take(ctx, "old", limit)
+	now = now.Add(50 * time.Second)
+	store.Take(ctx, "recent", limit)
+
+	now = now.Add(20 * time.Second)
+	if err := store.Prune(ctx, time.Minute); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if _, ok := store.buckets["old"]; ok {
+		t.Fatal("bucket unused for longer than a minute was kept")
+	}
+	if _, ok := store.buckets["recent"]; !ok {
+		t.Fatal("bucket used within a minute was pruned")
+	}
+}
+
+func TestLimiterPrunesWithTheLongestPeriod(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	limiter := NewLimiter(store, map[string]Limit{
+		"short": {Capacity: 1, Period: time.Minute},
+		"long":  {Capacity: 1, Period: time.Hour},
+	})
+	ctx := context.Background()
+
+	if result, _ := limiter.Allow(ctx, "unlimited", "key"); !result.Allowed {
+		t.Fatal("action without a limit denied")
+	}
+
+	limiter.Allow(ctx, "short", "key")
+	now = now.Add(30 * time.Minute)
+	if err := limiter.Prune(ctx); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if _, ok := store.buckets["short:key"]; !ok {
+		t.Fatal("bucket pruned before the longest period")
+	}
+
+	now = now.Add(31 * time.Minute)
+	limiter.Prune(ctx)
+	if len(store.buckets) != 0 {
+		t.Fatalf("buckets = %v, want none", store.buckets)
+	}
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// supported rate limit backends
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// allows a burst of requests up to the capacity, the tokens refill evenly over the period
+type Limit struct {
+	Capacity int
+	Period   time.Duration
+}
+
+// parse a limit written as the capacity over the period, such as 20/10m
+func ParseLimit(value string) (Limit, error) {
+	capacityStr, periodStr, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("rate limit %q must be written as requests/period, such as 20/10m", value)
+	}
+
+	capacity, err := strconv.Atoi(capacityStr)
+	if err != nil || capacity <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must allow at least one request", value)
+	}
+
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid period", value)
+	}
+
+	return Limit{Capacity: capacity, Period: period}, nil
+}
+
+// tokens refilled every second
+func (limit Limit) rate() float64 {
+	return float64(limit.Capacity) / limit.Period.Seconds()
+}
+
+// contains the state of the limit after a request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// contains the tokens left for a key when they were last counted
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// create a bucket with every token available
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Capacity), UpdatedAt: now}
+}
+
+// refill the bucket up to the given time and take a token if one is left
+func (bucket *Bucket) Take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+
+	if elapsed := now.Sub(bucket.UpdatedAt).Seconds(); elapsed > 0 {
+		bucket.Tokens = math.Min(float64(limit.Capacity), bucket.Tokens+elapsed*rate)
+		bucket.UpdatedAt = now
+	}
+
+	result := Result{Limit: limit.Capacity}
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - bucket.Tokens) / rate)
+	}
+
+	result.Remaining = int(bucket.Tokens)
+	result.Reset = seconds((float64(limit.Capacity) - bucket.Tokens) / rate)
+	return result
+}
+
+// round the seconds up so the client does not retry too early
+func seconds(value float64) time.Duration {
+	return time.Duration(math.Ceil(value)) * time.Second
+}
+
+// stores the buckets of every key, the store counts the time with its own clock
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	Prune(ctx context.Context, unusedFor time.Duration) error
+}
+
+// limits every action to its own limit, the actions without a limit are not limited
+type Limiter struct {
+	Store  Store
+	Limits map[string]Limit
+}
+
+// create a limiter storing the buckets in the store
+func NewLimiter(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{Store: store, Limits: limits}
+}
+
+// take a token from the bucket of the key for the action
+func (limiter *Limiter) Allow(ctx context.Context, action string, key string) (Result, error) {
+	limit, ok := limiter.Limits[action]
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+
+	return limiter.Store.Take(ctx, action+":"+key, limit)
+}
+
+// forget the buckets not used for longer than the longest period, they are full again by then
+func (limiter *Limiter) Prune(ctx context.Context) error {
+	var longest time.Duration
+	for _, limit := range limiter.Limits {
+		if limit.Period > longest {
+			longest = limit.Period
+		}
+	}
+
+	return limiter.Store.Prune(ctx, longest)
+}
+
+// prune the buckets at every interval until the program stops
+func (limiter *Limiter) Run(interval time.Duration, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := limiter.Prune(context.Background()); err != nil {
+			report(err)
+		}
+	}
+}
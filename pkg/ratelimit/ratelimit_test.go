@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit(" 20/10m ")
+	if err != nil {
+		t.Fatalf("ParseLimit: %v", err)
+	}
+	if limit.Capacity != 20 || limit.Period != 10*time.Minute {
+		t.Fatalf("limit = %+v, want 20 requests over 10m", limit)
+	}
+
+	for _, value := range []string{"", "20", "0/1m", "-1/1m", "abc/1m", "20/abc", "20/0s", "20/-1m"} {
+		if _, err := ParseLimit(value); err == nil {
+			t.Errorf("ParseLimit(%q) expected an error", value)
+		}
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Capacity: 2, Period: 10 * time.Second}
+	start := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	bucket := NewBucket(limit, start)
+
+	//the burst goes up to the capacity
+	first := bucket.Take(limit, start)
+	if !first.Allowed || first.Remaining != 1 || first.Limit != 2 || first.Reset != 5*time.Second {
+		t.Fatalf("first take = %+v", first)
+	}
+	second := bucket.Take(limit, start)
+	if !second.Allowed || second.Remaining != 0 || second.Reset != 10*time.Second {
+		t.Fatalf("second take = %+v", second)
+	}
+
+	//a token comes back every 5 seconds
+	denied := bucket.Take(limit, start.Add(2*time.Second))
+	if denied.Allowed || denied.RetryAfter != 3*time.Second || denied.Remaining != 0 {
+		t.Fatalf("denied take = %+v, want to retry after 3s", denied)
+	}
+
+	refilled := bucket.Take(limit, start.Add(5*time.Second))
+	if !refilled.Allowed || refilled.Remaining != 0 {
+		t.Fatalf("refilled take = %+v", refilled)
+	}
+}
+
+func TestBucketRefillsUpToCapacity(t *testing.T) {
+	limit := Limit{Capacity: 3, Period: time.Minute}
+	start := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	bucket := NewBucket(limit, start)
+	bucket.Take(limit, start)
+
+	result := bucket.Take(limit, start.Add(time.Hour))
+	if !result.Allowed || result.Remaining != 2 {
+		t.Fatalf("take = %+v, want the bucket to be full before the take", result)
+	}
+
+	//a clock going backwards does not refill the bucket
+	before := bucket.Tokens
+	bucket.Take(limit, start)
+	if bucket.Tokens != before-1 {
+		t.Fatalf("tokens = %v, want %v", bucket.Tokens, before-1)
+	}
+}
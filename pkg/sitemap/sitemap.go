@@ -0,0 +1,67 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// single page listed in the sitemap
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []entry  `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// encode the pages as a sitemap
+func URLSet(urls []URL) ([]byte, error) {
+	document := urlSet{XMLNS: namespace, URLs: entries(urls)}
+	return encode(document)
+}
+
+// encode the sitemaps as a sitemap index
+func Index(sitemaps []URL) ([]byte, error) {
+	document := sitemapIndex{XMLNS: namespace, Sitemaps: entries(sitemaps)}
+	return encode(document)
+}
+
+// the last modification date is left out when it is unknown
+func entries(urls []URL) []entry {
+	result := make([]entry, 0, len(urls))
+	for _, url := range urls {
+		item := entry{Loc: url.Loc}
+		if !url.LastMod.IsZero() {
+			item.LastMod = url.LastMod.UTC().Format(time.RFC3339)
+		}
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// encode the document along with the xml header
+func encode(document interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
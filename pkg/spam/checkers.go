@@ -0,0 +1,141 @@
+package spam
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.|<a\s`)
+
+// rejects the content containing blocked keywords or matching blocked patterns
+type Blocklist struct {
+	Keywords []string
+	Patterns []*regexp.Regexp
+}
+
+// read the blocklist from a file, each line is a keyword or a pattern between slashes, lines starting with # are skipped
+func LoadBlocklist(path string) (*Blocklist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocklist := &Blocklist{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if len(line) > 2 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/") {
+			pattern, err := regexp.Compile("(?i)" + line[1:len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid blocklist pattern %s: %w", line, err)
+			}
+			blocklist.Patterns = append(blocklist.Patterns, pattern)
+			continue
+		}
+
+		blocklist.Keywords = append(blocklist.Keywords, strings.ToLower(line))
+	}
+
+	return blocklist, scanner.Err()
+}
+
+func (blocklist *Blocklist) Name() string {
+	return "blocklist"
+}
+
+func (blocklist *Blocklist) Check(ctx context.Context, content *Content) (Result, error) {
+	text := strings.ToLower(content.Title + "\n" + content.Text)
+
+	for _, keyword := range blocklist.Keywords {
+		if strings.Contains(text, keyword) {
+			return Result{Score: 1, Reason: fmt.Sprintf("contains the blocked keyword %q", keyword)}, nil
+		}
+	}
+	for _, pattern := range blocklist.Patterns {
+		if pattern.MatchString(text) {
+			return Result{Score: 1, Reason: fmt.Sprintf("matches the blocked pattern %s", pattern.String())}, nil
+		}
+	}
+
+	return Result{}, nil
+}
+
+// scores the content having more links than allowed, every extra link adds to the score
+type LinkCount struct {
+	MaxLinks int
+}
+
+func (links *LinkCount) Name() string {
+	return "links"
+}
+
+func (links *LinkCount) Check(ctx context.Context, content *Content) (Result, error) {
+	count := len(linkPattern.FindAllString(content.Text, -1))
+	if count <= links.MaxLinks {
+		return Result{}, nil
+	}
+
+	extra := count - links.MaxLinks
+	return Result{Score: math.Min(1, 0.25+0.25*float64(extra)), Reason: fmt.Sprintf("contains %d links, at most %d are allowed", count, links.MaxLinks)}, nil
+}
+
+// scores the content already written within the window by anyone, short content such as thanks is skipped
+type Duplicate struct {
+	History   History
+	Window    time.Duration
+	MinLength int
+}
+
+func (duplicate *Duplicate) Name() string {
+	return "duplicate"
+}
+
+func (duplicate *Duplicate) Check(ctx context.Context, content *Content) (Result, error) {
+	if utf8.RuneCountInString(strings.TrimSpace(content.Text)) < duplicate.MinLength {
+		return Result{}, nil
+	}
+
+	count, err := duplicate.History.CountDuplicates(ctx, content.Hash, time.Now().Add(-duplicate.Window))
+	if err != nil || count == 0 {
+		return Result{}, err
+	}
+
+	return Result{Score: math.Min(1, 0.5*float64(count)), Reason: fmt.Sprintf("the same content was written %d times before", count)}, nil
+}
+
+// holds the content of new accounts writing more than the limit within an hour
+type Velocity struct {
+	History    History
+	AccountAge time.Duration
+	Limit      int64
+}
+
+func (velocity *Velocity) Name() string {
+	return "velocity"
+}
+
+func (velocity *Velocity) Check(ctx context.Context, content *Content) (Result, error) {
+	createdAt, err := velocity.History.AccountCreatedAt(ctx, content.AuthorID)
+	if err != nil || time.Since(createdAt) >= velocity.AccountAge {
+		return Result{}, err
+	}
+
+	count, err := velocity.History.CountRecent(ctx, content.AuthorID, time.Now().Add(-time.Hour))
+	if err != nil || count < velocity.Limit {
+		return Result{}, err
+	}
+
+	return Result{Score: 0.5, Reason: fmt.Sprintf("a new account wrote %d times within an hour", count)}, nil
+}
@@ -0,0 +1,76 @@
+package spam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// request sent to the classifier
+type classifierRequest struct {
+	Type     string    `json:"type"`
+	AuthorID uuid.UUID `json:"author_id"`
+	Title    string    `json:"title,omitempty"`
+	Text     string    `json:"text"`
+	HTML     string    `json:"html,omitempty"`
+}
+
+// response expected from the classifier, the score goes from 0 for ham to 1 for spam
+type classifierResponse struct {
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// asks an external service over http to score the content
+type Classifier struct {
+	URL    string
+	Weight float64
+	Client *http.Client
+}
+
+// create a classifier posting the content as json to the url
+func NewClassifier(url string, weight float64, timeout time.Duration) *Classifier {
+	return &Classifier{URL: url, Weight: weight, Client: &http.Client{Timeout: timeout}}
+}
+
+func (classifier *Classifier) Name() string {
+	return "classifier"
+}
+
+func (classifier *Classifier) Check(ctx context.Context, content *Content) (Result, error) {
+	body, err := json.Marshal(classifierRequest{Type: content.Type, AuthorID: content.AuthorID, Title: content.Title, Text: content.Text, HTML: content.HTML})
+	if err != nil {
+		return Result{}, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, classifier.URL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := classifier.Client.Do(request)
+	if err != nil {
+		return Result{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("classifier responded with %s", response.Status)
+	}
+
+	var classified classifierResponse
+	if err := json.NewDecoder(response.Body).Decode(&classified); err != nil {
+		return Result{}, fmt.Errorf("invalid classifier response: %w", err)
+	}
+	if classified.Score < 0 || classified.Score > 1 {
+		return Result{}, fmt.Errorf("classifier score %v is not between 0 and 1", classified.Score)
+	}
+
+	return Result{Score: classified.Score * classifier.Weight, Reason: classified.Reason}, nil
+}
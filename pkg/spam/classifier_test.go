@@ -0,0 +1,94 @@
+package spam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// start a stub classifier answering every request with the given status and body
+func newStubClassifier(t *testing.T, status int, body string, requests chan<- classifierRequest) *Classifier {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var request classifierRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if requests != nil {
+			requests <- request
+		}
+
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClassifier(server.URL, 0.5, time.Second)
+}
+
+func TestClassifierScoresContent(t *testing.T) {
+	requests := make(chan classifierRequest, 1)
+	classifier := newStubClassifier(t, http.StatusOK, `{"score": 0.8, "reason": "looks like spam"}`, requests)
+
+	authorID := uuid.New()
+	result, err := classifier.Check(context.Background(), &Content{Type: "comment", AuthorID: authorID, Title: "title", Text: "buy now"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	//the score of the classifier is weighted
+	if result.Score != 0.4 || result.Reason != "looks like spam" {
+		t.Fatalf("result = %+v, want a score of 0.4 and the reason of the classifier", result)
+	}
+
+	request := <-requests
+	if request.Type != "comment" || request.AuthorID != authorID || request.Title != "title" || request.Text != "buy now" {
+		t.Fatalf("classifier received %+v", request)
+	}
+}
+
+func TestClassifierErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "failed request", status: http.StatusInternalServerError, body: `{"score": 0}`},
+		{name: "invalid json", status: http.StatusOK, body: `not json`},
+		{name: "score out of range", status: http.StatusOK, body: `{"score": 1.5}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			classifier := newStubClassifier(t, test.status, test.body, nil)
+			if _, err := classifier.Check(context.Background(), &Content{Type: "post", Text: "text"}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestClassifierTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"score": 0}`))
+	}))
+	t.Cleanup(server.Close)
+
+	classifier := NewClassifier(server.URL, 1, 50*time.Millisecond)
+	if _, err := classifier.Check(context.Background(), &Content{Type: "post", Text: "text"}); err == nil {
+		t.Fatal("expected the slow classifier to time out")
+	}
+}
@@ -0,0 +1,103 @@
+package spam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// decisions taken on the checked content
+const (
+	DecisionAllow  = "allow"
+	DecisionHold   = "hold"
+	DecisionReject = "reject"
+)
+
+// contains the content written by a user along with its author
+type Content struct {
+	Type     string
+	AuthorID uuid.UUID
+	Title    string
+	Text     string
+	HTML     string
+	Hash     string
+}
+
+// contains the score a checker gave to the content, a score of 1 alone is enough to reject it
+type Result struct {
+	Checker string  `json:"checker"`
+	Score   float64 `json:"score"`
+	Reason  string  `json:"reason,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// contains the decision taken on the content along with the results of every checker
+type Verdict struct {
+	Decision string   `json:"decision"`
+	Score    float64  `json:"score"`
+	Hash     string   `json:"hash"`
+	Results  []Result `json:"results"`
+}
+
+// scores the content, a checker which fails is skipped
+type Checker interface {
+	Name() string
+	Check(ctx context.Context, content *Content) (Result, error)
+}
+
+// contents checked earlier, used to find duplicates and new accounts writing too fast
+type History interface {
+	CountDuplicates(ctx context.Context, hash string, since time.Time) (int64, error)
+	CountRecent(ctx context.Context, authorID uuid.UUID, since time.Time) (int64, error)
+	AccountCreatedAt(ctx context.Context, authorID uuid.UUID) (time.Time, error)
+}
+
+// runs the checkers and adds their scores up into a decision
+type Pipeline struct {
+	Checkers    []Checker
+	HoldScore   float64
+	RejectScore float64
+}
+
+// create a pipeline holding the content from the hold score and rejecting it from the reject score
+func NewPipeline(holdScore, rejectScore float64, checkers ...Checker) *Pipeline {
+	return &Pipeline{Checkers: checkers, HoldScore: holdScore, RejectScore: rejectScore}
+}
+
+// score the content with every checker, the content is allowed when the checkers fail
+func (pipeline *Pipeline) Check(ctx context.Context, content Content) Verdict {
+	content.Hash = Hash(content.Title + "\n" + content.Text)
+	verdict := Verdict{Decision: DecisionAllow, Hash: content.Hash, Results: []Result{}}
+
+	for _, checker := range pipeline.Checkers {
+		result, err := checker.Check(ctx, &content)
+		result.Checker = checker.Name()
+		if err != nil {
+			result.Score = 0
+			result.Error = err.Error()
+		}
+
+		verdict.Score += result.Score
+		verdict.Results = append(verdict.Results, result)
+	}
+
+	if verdict.Score >= pipeline.RejectScore {
+		verdict.Decision = DecisionReject
+	} else if verdict.Score >= pipeline.HoldScore {
+		verdict.Decision = DecisionHold
+	}
+
+	return verdict
+}
+
+// hash of the content ignoring the case and the spacing, so copies with small changes still match
+func Hash(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// default url prefix the local files are served from
+const defaultLocalURL = "/media"
+
+// stores the files in a directory of the local filesystem
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+// returns the directory configured by MEDIA_DIR, defaults to uploads next to the env file
+func LocalDir() string {
+	if dir := os.Getenv("MEDIA_DIR"); dir != "" {
+		return dir
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "uploads"
+	}
+
+	return filepath.Join(filepath.Dir(wd), "uploads")
+}
+
+func NewLocalStorage(dir string, baseURL string) (*LocalStorage, error) {
+	if baseURL == "" {
+		baseURL = defaultLocalURL
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// write the file into a temporary file and move it to its place once it is complete
+func (storage *LocalStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// remove the file, missing files are ignored
+func (storage *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (storage *LocalStorage) URL(key string) string {
+	return storage.BaseURL + "/" + key
+}
+
+// resolve the key inside the storage directory
+func (storage *LocalStorage) path(key string) (string, error) {
+	path := filepath.Join(storage.Dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(storage.Dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return path, nil
+}
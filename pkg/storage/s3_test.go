@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// minimal stand-in for an S3 compatible server like MinIO, it keeps the objects in memory and skips the signatures
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (s3 *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s3.mu.Lock()
+	defer s3.mu.Unlock()
+
+	//objects are addressed by their path, the bucket being the first part of it
+	key := strings.TrimPrefix(r.URL.Path, "/")
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readPayload(r)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s3.objects[key] = body
+		s3.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(s3.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// read the object sent, unsigned payloads are sent as is and signed ones in aws-chunked encoding
+func readPayload(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return body, err
+	}
+
+	//every chunk is "<hex size>;chunk-signature=<signature>\r\n<data>\r\n" and the last one is empty
+	var payload []byte
+	for len(body) > 0 {
+		header, rest, found := bytes.Cut(body, []byte("\r\n"))
+		if !found {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		size, err := strconv.ParseInt(string(bytes.SplitN(header, []byte(";"), 2)[0]), 16, 64)
+		if err != nil || int64(len(rest)) < size {
+			return nil, io.ErrUnexpectedEOF
+		} else if size == 0 {
+			break
+		}
+
+		payload = append(payload, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+
+	return payload, nil
+}
+
+func newTestS3Storage(t *testing.T, publicURL string) (*S3Storage, *fakeS3) {
+	t.Helper()
+
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Storage(S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "media",
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+		PublicURL: publicURL,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+
+	return store, fake
+}
+
+func TestS3StoragePutAndDelete(t *testing.T) {
+	store, fake := newTestS3Storage(t, "")
+	ctx := context.Background()
+	content := []byte("hello media")
+
+	if err := store.Put(ctx, "user/file.txt", bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if got := fake.objects["media/user/file.txt"]; !bytes.Equal(got, content) {
+		t.Fatalf("stored object = %q, want %q", got, content)
+	}
+	if got := fake.types["media/user/file.txt"]; got != "text/plain" {
+		t.Fatalf("stored content type = %q, want text/plain", got)
+	}
+
+	if err := store.Delete(ctx, "user/file.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.objects["media/user/file.txt"]; ok {
+		t.Fatal("object still stored after Delete")
+	}
+}
+
+func TestS3StorageURL(t *testing.T) {
+	store, _ := newTestS3Storage(t, "https://cdn.example.com/")
+	if got := store.URL("user/file.png"); got != "https://cdn.example.com/user/file.png" {
+		t.Fatalf("URL = %q", got)
+	}
+
+	store, _ = newTestS3Storage(t, "")
+	if got := store.URL("user/file.png"); !strings.HasSuffix(got, "/media/user/file.png") || !strings.HasPrefix(got, "http://") {
+		t.Fatalf("URL = %q, want the bucket path on the endpoint", got)
+	}
+}
+
+func TestNewS3StorageRequiresEndpointAndBucket(t *testing.T) {
+	if _, err := NewS3Storage(S3Config{Bucket: "media"}); err == nil {
+		t.Fatal("expected an error without an endpoint")
+	}
+	if _, err := NewS3Storage(S3Config{Endpoint: "localhost:9000"}); err == nil {
+		t.Fatal("expected an error without a bucket")
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// supported storage backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// stores the uploaded files and builds the urls they are served from
+type Storage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// creates the storage selected by the STORAGE_BACKEND env variable
+func New() (Storage, error) {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "", BackendLocal:
+		return NewLocalStorage(LocalDir(), os.Getenv("MEDIA_BASE_URL"))
+	case BackendS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+			PublicURL: os.Getenv("MEDIA_BASE_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", os.Getenv("STORAGE_BACKEND"))
+	}
+}
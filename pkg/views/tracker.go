@@ -0,0 +1,177 @@
+package views
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// views and unique visitors of a post on a day
+type DailyCount struct {
+	PostID   uuid.UUID
+	Day      time.Time
+	Views    int64
+	Visitors int64
+}
+
+// views of a post on a day coming from a referrer
+type ReferrerCount struct {
+	PostID   uuid.UUID
+	Day      time.Time
+	Referrer string
+	Views    int64
+}
+
+// views buffered since the last flush
+type Batch struct {
+	Days      []DailyCount
+	Referrers []ReferrerCount
+}
+
+// stores a batch of views, an error keeps the views buffered for the next flush
+type FlushFunc func(batch Batch) error
+
+type dayKey struct {
+	postID uuid.UUID
+	day    time.Time
+}
+
+type referrerKey struct {
+	postID   uuid.UUID
+	day      time.Time
+	referrer string
+}
+
+type visitorKey struct {
+	postID  uuid.UUID
+	visitor string
+}
+
+// counts the views of posts in memory and flushes them in batches
+type Tracker struct {
+	mu        sync.Mutex
+	window    time.Duration
+	flush     FlushFunc
+	now       func() time.Time
+	lastSeen  map[visitorKey]time.Time
+	seenDay   time.Time
+	seenToday map[visitorKey]bool
+	days      map[dayKey]*DailyCount
+	referrers map[referrerKey]int64
+}
+
+// create a tracker counting a visitor once per post within the window
+func NewTracker(window time.Duration, flush FlushFunc) *Tracker {
+	return &Tracker{
+		window:    window,
+		flush:     flush,
+		now:       time.Now,
+		lastSeen:  make(map[visitorKey]time.Time),
+		seenToday: make(map[visitorKey]bool),
+		days:      make(map[dayKey]*DailyCount),
+		referrers: make(map[referrerKey]int64),
+	}
+}
+
+// record a view of the post, repeated views of the visitor within the window are ignored
+func (tracker *Tracker) Record(postID uuid.UUID, visitor string, referrer string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	now := tracker.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	key := visitorKey{postID: postID, visitor: visitor}
+
+	if last, ok := tracker.lastSeen[key]; ok && now.Sub(last) < tracker.window {
+		return
+	}
+	tracker.lastSeen[key] = now
+
+	//unique visitors are counted per day
+	if !day.Equal(tracker.seenDay) {
+		tracker.seenDay = day
+		tracker.seenToday = make(map[visitorKey]bool)
+	}
+
+	count := tracker.days[dayKey{postID: postID, day: day}]
+	if count == nil {
+		count = &DailyCount{PostID: postID, Day: day}
+		tracker.days[dayKey{postID: postID, day: day}] = count
+	}
+
+	count.Views++
+	if !tracker.seenToday[key] {
+		tracker.seenToday[key] = true
+		count.Visitors++
+	}
+
+	tracker.referrers[referrerKey{postID: postID, day: day, referrer: referrer}]++
+}
+
+// store the buffered views, they are buffered again when storing them fails
+func (tracker *Tracker) Flush() error {
+	tracker.mu.Lock()
+	days, referrers := tracker.days, tracker.referrers
+	tracker.days = make(map[dayKey]*DailyCount)
+	tracker.referrers = make(map[referrerKey]int64)
+
+	//forget the visitors whose window is over
+	now := tracker.now().UTC()
+	for key, last := range tracker.lastSeen {
+		if now.Sub(last) >= tracker.window {
+			delete(tracker.lastSeen, key)
+		}
+	}
+	tracker.mu.Unlock()
+
+	if len(days) == 0 {
+		return nil
+	}
+
+	var batch Batch
+	for _, count := range days {
+		batch.Days = append(batch.Days, *count)
+	}
+	for key, views := range referrers {
+		batch.Referrers = append(batch.Referrers, ReferrerCount{PostID: key.postID, Day: key.day, Referrer: key.referrer, Views: views})
+	}
+
+	if err := tracker.flush(batch); err != nil {
+		tracker.restore(days, referrers)
+		return err
+	}
+
+	return nil
+}
+
+// put the views which could not be stored back into the buffer
+func (tracker *Tracker) restore(days map[dayKey]*DailyCount, referrers map[referrerKey]int64) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	for key, count := range days {
+		if current := tracker.days[key]; current != nil {
+			current.Views += count.Views
+			current.Visitors += count.Visitors
+		} else {
+			tracker.days[key] = count
+		}
+	}
+
+	for key, views := range referrers {
+		tracker.referrers[key] += views
+	}
+}
+
+// flush the buffered views on every interval, report receives the errors of failed flushes
+func (tracker *Tracker) Run(interval time.Duration, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := tracker.Flush(); err != nil {
+			report(err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package views
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// create a tracker whose clock is moved by the test, the batches it flushes are kept
+func newTestTracker(now *time.Time, window time.Duration, fail *bool, batches *[]Batch) *Tracker {
+	tracker := NewTracker(window, func(batch Batch) error {
+		if *fail {
+			return errors.New("database unavailable")
+		}
+		*batches = append(*batches, batch)
+		return nil
+	})
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+// find the count of the post on the day in the batch
+func dailyCount(t *testing.T, batch Batch, postID uuid.UUID, day time.Time) DailyCount {
+	t.Helper()
+
+	for _, count := range batch.Days {
+		if count.PostID == postID && count.Day.Equal(day) {
+			return count
+		}
+	}
+
+	t.Fatalf("no count of %s on %s in %+v", postID, day, batch.Days)
+	return DailyCount{}
+}
+
+func TestTrackerCountsAVisitorOncePerWindow(t *testing.T) {
+	var fail bool
+	var batches []Batch
+	now := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(&now, 30*time.Minute, &fail, &batches)
+	postID := uuid.New()
+
+	tracker.Record(postID, "user:a", "direct")
+	now = now.Add(10 * time.Minute)
+	tracker.Record(postID, "user:a", "direct")
+	tracker.Record(postID, "user:b", "example.com")
+
+	//the window starts again from the last counted view
+	now = now.Add(25 * time.Minute)
+	tracker.Record(postID, "user:a", "direct")
+
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("flushed %d batches, want 1", len(batches))
+	}
+
+	count := dailyCount(t, batches[0], postID, now.Truncate(24*time.Hour))
+	if count.Views != 3 || count.Visitors != 2 {
+		t.Fatalf("count = %+v, want 3 views by 2 visitors", count)
+	}
+
+	referrers := map[string]int64{}
+	for _, referrer := range batches[0].Referrers {
+		referrers[referrer.Referrer] += referrer.Views
+	}
+	if referrers["direct"] != 2 || referrers["example.com"] != 1 {
+		t.Fatalf("referrers = %v", referrers)
+	}
+
+	//nothing is flushed without new views
+	if err := tracker.Flush(); err != nil || len(batches) != 1 {
+		t.Fatalf("empty flush stored a batch, err %v", err)
+	}
+}
+
+func TestTrackerCountsVisitorsAgainTheNextDay(t *testing.T) {
+	var fail bool
+	var batches []Batch
+	now := time.Date(2024, 8, 1, 23, 40, 0, 0, time.UTC)
+	tracker := newTestTracker(&now, 30*time.Minute, &fail, &batches)
+	postID := uuid.New()
+
+	tracker.Record(postID, "user:a", "direct")
+	now = now.Add(45 * time.Minute)
+	tracker.Record(postID, "user:a", "direct")
+
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	first := dailyCount(t, batches[0], postID, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+	second := dailyCount(t, batches[0], postID, time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC))
+	if first.Views != 1 || first.Visitors != 1 || second.Views != 1 || second.Visitors != 1 {
+		t.Fatalf("counts = %+v and %+v, want a view by a visitor on each day", first, second)
+	}
+}
+
+func TestTrackerKeepsTheViewsOfAFailedFlush(t *testing.T) {
+	var batches []Batch
+	fail := true
+	now := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(&now, time.Minute, &fail, &batches)
+	postID := uuid.New()
+
+	tracker.Record(postID, "user:a", "direct")
+	if err := tracker.Flush(); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	//the views recorded after the failure are added to the restored ones
+	now = now.Add(2 * time.Minute)
+	tracker.Record(postID, "user:b", "direct")
+
+	fail = false
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("flushed %d batches, want 1", len(batches))
+	}
+
+	count := dailyCount(t, batches[0], postID, now.Truncate(24*time.Hour))
+	if count.Views != 2 || count.Visitors != 2 {
+		t.Fatalf("count = %+v, want 2 views by 2 visitors", count)
+	}
+	if len(batches[0].Referrers) != 1 || batches[0].Referrers[0].Views != 2 {
+		t.Fatalf("referrers = %+v, want 2 direct views", batches[0].Referrers)
+	}
+}
+
+func TestTrackerForgetsVisitorsAfterTheWindow(t *testing.T) {
+	var fail bool
+	var batches []Batch
+	now := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(&now, time.Minute, &fail, &batches)
+
+	tracker.Record(uuid.New(), "anonymous:a", "direct")
+	tracker.Record(uuid.New(), "anonymous:b", "direct")
+
+	now = now.Add(time.Minute)
+	tracker.Flush()
+	if len(tracker.lastSeen) != 0 {
+		t.Fatalf("lastSeen = %v, want the visitors of past windows forgotten", tracker.lastSeen)
+	}
+}